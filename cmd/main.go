@@ -8,7 +8,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/sssciel/avito-backend-intership/internals/absences"
+	"github.com/sssciel/avito-backend-intership/internals/codeowners"
 	"github.com/sssciel/avito-backend-intership/internals/pullrequests"
+	"github.com/sssciel/avito-backend-intership/internals/reminders"
+	"github.com/sssciel/avito-backend-intership/internals/sla"
+	"github.com/sssciel/avito-backend-intership/internals/stats"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 	"github.com/sssciel/avito-backend-intership/internals/storage/pgsql"
 	"github.com/sssciel/avito-backend-intership/internals/teams"
 	"github.com/sssciel/avito-backend-intership/internals/users"
@@ -46,10 +52,38 @@ func main() {
 	teamStorage := &pgsql.PGTeamStorage{DB: db}
 	userStorage := &pgsql.PGUserStorage{DB: db}
 	requestStorage := &pgsql.PGPullRequestStorage{DB: db}
+	codeOwnerStorage := &pgsql.PGCodeOwnerStorage{DB: db}
+	statsStorage := &pgsql.PGStatsStorage{DB: db}
+	reminderStorage := &pgsql.PGReminderStorage{DB: db}
 
 	teamService := teams.New(teamStorage, userStorage)
 	userService := users.New(userStorage, teamStorage)
-	prService := pullrequests.New(requestStorage, teamStorage, userStorage)
+	prService := pullrequests.New(requestStorage, teamStorage, userStorage, codeOwnerStorage)
+	codeOwnerService := codeowners.New(codeOwnerStorage)
+	statsService := stats.New(statsStorage, teamStorage)
+
+	if config.Workers.SLACheckInterval > 0 {
+		slaWorker := sla.NewWorker(requestStorage, prService, config.Workers.SLACheckInterval)
+		go slaWorker.Run(ctx)
+	}
+
+	if config.Workers.AbsenceCheckInterval > 0 {
+		absenceWorker := absences.NewWorker(userStorage, prService, config.Workers.AbsenceCheckInterval)
+		go absenceWorker.Run(ctx)
+	}
+
+	if config.Workers.ReminderInterval > 0 {
+		notifier, err := reminders.NewNotifier(config.Workers.ReminderNotifier, config.Workers.ReminderFile)
+		if err != nil {
+			slog.Error("Failed to set up reminder notifier", "err", err)
+			os.Exit(1)
+		}
+		scheduler := reminders.NewScheduler(reminderStorage, notifier, config.Workers.ReminderInterval, models.ReminderPolicy{
+			StaleAfter:  config.Workers.ReminderStaleAfter,
+			RepeatAfter: config.Workers.ReminderRepeatAfter,
+		})
+		go scheduler.Run(ctx)
+	}
 
 	r := gin.Default()
 	api := r.Group("/api/v1")
@@ -57,6 +91,8 @@ func main() {
 	teamService.RegisterRoutes(api)
 	userService.RegisterRoutes(api)
 	prService.RegisterRoutes(api)
+	codeOwnerService.RegisterRoutes(api)
+	statsService.RegisterRoutes(api)
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -0,0 +1,107 @@
+// Package absences hands the reviews of users who went out of office over to
+// other reviewers.
+package absences
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Claimer hands out the started absences whose reviews are to be reassigned
+// and records how each claim ended.
+type Claimer interface {
+	ClaimStartedAbsences(ctx context.Context) ([]models.Absence, error)
+	CompleteAbsence(ctx context.Context, absence models.Absence) error
+	ReleaseAbsence(ctx context.Context, absence models.Absence) error
+}
+
+// Reassigner replaces an absent reviewer on a pull request and returns who
+// took over.
+type Reassigner interface {
+	ReassignAbsent(ctx context.Context, pullRequestID, reviewerID string) (string, error)
+}
+
+// Worker periodically reassigns the OPEN reviews of users whose absence has
+// started and asked for it. Every replica of the service may run one: each
+// absence is claimed by a single replica.
+type Worker struct {
+	Storage    Claimer
+	Reassigner Reassigner
+	Interval   time.Duration
+}
+
+func NewWorker(storage Claimer, reassigner Reassigner, interval time.Duration) *Worker {
+	return &Worker{
+		Storage:    storage,
+		Reassigner: reassigner,
+		Interval:   interval,
+	}
+}
+
+// Run checks for started absences every Interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("Absence worker started", "interval", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Absence worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := w.RunOnce(ctx); err != nil {
+				slog.Error("Absence check failed", "err", err)
+			}
+		}
+	}
+}
+
+// RunOnce reassigns the reviews of every claimed absence and returns how many
+// reviews changed hands. A review nobody can take over stays with the absent
+// reviewer; a failed reassignment is logged, does not stop the others and
+// releases the absence, so that the next run retries its remaining reviews.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	claimed, err := w.Storage.ClaimStartedAbsences(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	reassigned := 0
+	for _, absence := range claimed {
+		failed := false
+		for _, prID := range absence.PullRequestIDs {
+			newReviewerID, err := w.Reassigner.ReassignAbsent(ctx, prID, absence.UserID)
+			if err != nil {
+				switch err.Error() {
+				case "NO_CANDIDATE":
+					slog.Warn("No replacement for absent reviewer", "prID", prID, "reviewerID", absence.UserID)
+				case "NOT_ASSIGNED", "NOT_FOUND", models.StatusError(models.StatusMerged), models.StatusError(models.StatusClosed):
+					// Changed since the absence was claimed.
+				default:
+					slog.Error("Failed to reassign absent reviewer", "prID", prID, "reviewerID", absence.UserID, "err", err)
+					failed = true
+				}
+				continue
+			}
+
+			reassigned++
+			slog.Info("Absent reviewer reassigned", "prID", prID, "oldID", absence.UserID, "newID", newReviewerID)
+		}
+
+		if failed {
+			if err := w.Storage.ReleaseAbsence(ctx, absence); err != nil {
+				slog.Error("Failed to release absence", "absenceID", absence.ID, "err", err)
+			}
+			continue
+		}
+		if err := w.Storage.CompleteAbsence(ctx, absence); err != nil {
+			slog.Error("Failed to complete absence", "absenceID", absence.ID, "err", err)
+		}
+	}
+	return reassigned, nil
+}
@@ -0,0 +1,132 @@
+package absences
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type fakeReassigner struct {
+	results map[string]string
+	errs    map[string]error
+	calls   []string
+}
+
+func (f *fakeReassigner) ReassignAbsent(ctx context.Context, pullRequestID, reviewerID string) (string, error) {
+	f.calls = append(f.calls, pullRequestID+"/"+reviewerID)
+	return f.results[pullRequestID], f.errs[pullRequestID]
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	now := time.Now()
+	storage := mocks.NewMockUserStorage()
+	storage.UserReviews["u1"] = []models.PullRequest{
+		{ID: "pr-1", Status: models.StatusOpen},
+		{ID: "pr-2", Status: models.StatusMerged},
+		{ID: "pr-3", Status: models.StatusOpen},
+		{ID: "pr-4", Status: models.StatusOpen},
+	}
+	storage.UserReviews["u2"] = []models.PullRequest{{ID: "pr-5", Status: models.StatusOpen}}
+	storage.Absences["u1"] = []models.Absence{
+		{ID: 1, UserID: "u1", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), ReassignReviews: true},
+	}
+	storage.Absences["u2"] = []models.Absence{
+		// Not started, and started without reassignment.
+		{ID: 2, UserID: "u2", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), ReassignReviews: true},
+		{ID: 3, UserID: "u2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+	}
+
+	reassigner := &fakeReassigner{
+		results: map[string]string{"pr-1": "u3"},
+		errs: map[string]error{
+			"pr-3": errors.New("NO_CANDIDATE"),
+			"pr-4": errors.New("connection reset"),
+		},
+	}
+	worker := NewWorker(storage, reassigner, time.Minute)
+
+	reassigned, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if reassigned != 1 {
+		t.Errorf("reassigned = %d, want 1", reassigned)
+	}
+	want := []string{"pr-1/u1", "pr-3/u1", "pr-4/u1"}
+	if !slices.Equal(reassigner.calls, want) {
+		t.Errorf("calls = %v, want %v", reassigner.calls, want)
+	}
+
+}
+
+func TestWorker_RunOnceRetriesFailedReassignment(t *testing.T) {
+	now := time.Now()
+	storage := mocks.NewMockUserStorage()
+	storage.UserReviews["u1"] = []models.PullRequest{
+		{ID: "pr-1", Status: models.StatusOpen},
+		{ID: "pr-2", Status: models.StatusOpen},
+	}
+	storage.Absences["u1"] = []models.Absence{
+		{ID: 1, UserID: "u1", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), ReassignReviews: true},
+	}
+
+	reassigner := &fakeReassigner{
+		results: map[string]string{"pr-1": "u2"},
+		errs:    map[string]error{"pr-2": errors.New("connection reset")},
+	}
+	worker := NewWorker(storage, reassigner, time.Minute)
+
+	if reassigned, err := worker.RunOnce(context.Background()); err != nil || reassigned != 1 {
+		t.Fatalf("first RunOnce() = %d, %v, want 1, nil", reassigned, err)
+	}
+
+	// pr-1 changed hands; the failed pr-2 is retried on the next run.
+	storage.UserReviews["u1"] = storage.UserReviews["u1"][1:]
+	reassigner.calls = nil
+	reassigner.results["pr-2"] = "u3"
+	delete(reassigner.errs, "pr-2")
+	if reassigned, err := worker.RunOnce(context.Background()); err != nil || reassigned != 1 {
+		t.Fatalf("second RunOnce() = %d, %v, want 1, nil", reassigned, err)
+	}
+	if want := []string{"pr-2/u1"}; !slices.Equal(reassigner.calls, want) {
+		t.Errorf("calls = %v, want %v", reassigner.calls, want)
+	}
+
+	// Once every review was handled, the absence is not claimed again.
+	reassigner.calls = nil
+	if reassigned, err := worker.RunOnce(context.Background()); err != nil || reassigned != 0 || len(reassigner.calls) != 0 {
+		t.Errorf("third RunOnce() = %d, %v with calls %v, want nothing", reassigned, err, reassigner.calls)
+	}
+	if !storage.Absences["u1"][0].ReviewsReassignedAt.Valid {
+		t.Error("Expected the absence to be recorded as reassigned")
+	}
+}
+
+func TestWorker_RunOnceClaimError(t *testing.T) {
+	worker := NewWorker(claimerFunc(func(ctx context.Context) ([]models.Absence, error) {
+		return nil, errors.New("connection refused")
+	}), &fakeReassigner{}, time.Minute)
+
+	if _, err := worker.RunOnce(context.Background()); err == nil {
+		t.Error("RunOnce() error = nil, want the claim error")
+	}
+}
+
+type claimerFunc func(ctx context.Context) ([]models.Absence, error)
+
+func (f claimerFunc) ClaimStartedAbsences(ctx context.Context) ([]models.Absence, error) {
+	return f(ctx)
+}
+
+func (f claimerFunc) CompleteAbsence(ctx context.Context, absence models.Absence) error {
+	return nil
+}
+
+func (f claimerFunc) ReleaseAbsence(ctx context.Context, absence models.Absence) error {
+	return nil
+}
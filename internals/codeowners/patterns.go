@@ -0,0 +1,149 @@
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Parse reads a CODEOWNERS file. Owners are written as @user_id for users and
+// @org/team_name for teams; e-mail owners are not supported.
+func Parse(content string) ([]models.CodeOwnerRule, error) {
+	var rules []models.CodeOwnerRule
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := models.CodeOwnerRule{
+			Pattern: strings.ReplaceAll(fields[0], `\#`, "#"),
+			Users:   []string{},
+			Teams:   []string{},
+		}
+		if err := ValidatePattern(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		for _, owner := range fields[1:] {
+			name, ok := strings.CutPrefix(owner, "@")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("line %d: unsupported owner %q", line, owner)
+			}
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				rule.Teams = append(rule.Teams, name[i+1:])
+			} else {
+				rule.Users = append(rule.Users, name)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ValidatePattern reports whether pattern is a usable ownership glob.
+func ValidatePattern(pattern string) error {
+	_, err := compile(pattern)
+	return err
+}
+
+// Match reports whether path matches a CODEOWNERS pattern. Patterns without a
+// slash match at any depth, a leading slash anchors to the repository root,
+// and a pattern naming a directory matches everything below it.
+func Match(pattern, path string) bool {
+	re, err := compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// Owners returns the rules that own paths. For every path only the last
+// matching rule counts, as in CODEOWNERS.
+func Owners(rules []models.CodeOwnerRule, paths []string) []models.CodeOwnerRule {
+	compiled := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		compiled[i], _ = compile(rule.Pattern)
+	}
+
+	var owners []models.CodeOwnerRule
+	seen := make(map[int]bool)
+	for _, path := range paths {
+		path = strings.TrimPrefix(path, "/")
+		for i := len(rules) - 1; i >= 0; i-- {
+			if compiled[i] == nil || !compiled[i].MatchString(path) {
+				continue
+			}
+			if !seen[i] {
+				seen[i] = true
+				owners = append(owners, rules[i])
+			}
+			break
+		}
+	}
+	return owners
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" || strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+		return nil, errors.New("INVALID_PATTERN")
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return nil, errors.New("INVALID_PATTERN")
+	}
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			b.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(trimmed[i])))
+		}
+	}
+
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
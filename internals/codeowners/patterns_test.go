@@ -0,0 +1,87 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "cmd/main.go", true},
+		{"*.go", "internals/teams/service.go", true},
+		{"*.go", "README.md", false},
+		{"/docs/", "docs/openapi.yml", true},
+		{"/docs/", "api/docs/index.md", false},
+		{"docs/", "api/docs/index.md", true},
+		{"docs/", "docs", false},
+		{"migrations", "db/migrations/000001_init.up.sql", true},
+		{"internals/storage", "internals/storage/pgsql/teams.go", true},
+		{"internals/storage", "pkg/internals/storage/x.go", false},
+		{"internals/*.go", "internals/main.go", true},
+		{"internals/*.go", "internals/teams/service.go", false},
+		{"**/pgsql/*.go", "internals/storage/pgsql/teams.go", true},
+		{"internals/**/*_test.go", "internals/teams/service_test.go", true},
+		{"internals/**", "internals/teams/service.go", true},
+		{"/Makefile", "Makefile", true},
+		{"Makefile?", "Makefile", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := `
+# Default owners
+*                     @u1
+
+/internals/storage/   @u2 @avito/Backend # storage layer
+docs/\#drafts/        @u3
+`
+
+	rules, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(rules))
+	}
+	if rules[1].Pattern != "/internals/storage/" || len(rules[1].Users) != 1 || rules[1].Users[0] != "u2" {
+		t.Errorf("Unexpected second rule: %+v", rules[1])
+	}
+	if len(rules[1].Teams) != 1 || rules[1].Teams[0] != "Backend" {
+		t.Errorf("Expected team Backend, got %v", rules[1].Teams)
+	}
+	if rules[2].Pattern != "docs/#drafts/" {
+		t.Errorf("Expected escaped hash in pattern, got %q", rules[2].Pattern)
+	}
+}
+
+func TestParse_UnsupportedOwner(t *testing.T) {
+	if _, err := Parse("*.go dev@example.com\n"); err == nil {
+		t.Error("Expected error for e-mail owner")
+	}
+}
+
+func TestOwners_LastMatchWins(t *testing.T) {
+	rules := []models.CodeOwnerRule{
+		{ID: 1, Pattern: "*", Users: []string{"u1"}},
+		{ID: 2, Pattern: "/migrations/", Users: []string{"u2"}},
+		{ID: 3, Pattern: "*.md", Users: []string{"u3"}},
+	}
+
+	owners := Owners(rules, []string{"migrations/000001_init.up.sql", "migrations/README.md"})
+
+	if len(owners) != 2 || owners[0].ID != 2 || owners[1].ID != 3 {
+		t.Errorf("Expected rules 2 and 3, got %+v", owners)
+	}
+}
@@ -0,0 +1,170 @@
+package codeowners
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/storage"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type CodeOwnerService struct {
+	CodeOwnerStorage storage.CodeOwnerStorage
+}
+
+var codeOwnersPrefix = "codeowners"
+
+func New(codeOwnerStorage storage.CodeOwnerStorage) *CodeOwnerService {
+	return &CodeOwnerService{
+		CodeOwnerStorage: codeOwnerStorage,
+	}
+}
+
+func (s *CodeOwnerService) RegisterRoutes(r *gin.RouterGroup) {
+	codeOwnersRouter := r.Group("/" + codeOwnersPrefix)
+
+	codeOwnersRouter.POST("/add", s.AddRule)
+	codeOwnersRouter.POST("/import", s.Import)
+	codeOwnersRouter.GET("/list", s.ListRules)
+}
+
+type AddRuleRequest struct {
+	Pattern string   `json:"pattern" binding:"required"`
+	Users   []string `json:"users"`
+	Teams   []string `json:"teams"`
+}
+
+type ImportRequest struct {
+	Content string `json:"content"`
+}
+
+type RuleResponse struct {
+	Rule models.CodeOwnerRule `json:"rule"`
+}
+
+type RulesResponse struct {
+	Rules []models.CodeOwnerRule `json:"rules"`
+}
+
+func (s *CodeOwnerService) AddRule(c *gin.Context) {
+	var req AddRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if len(req.Users) == 0 && len(req.Teams) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": "at least one of users or teams is required",
+			},
+		})
+		return
+	}
+	if err := ValidatePattern(req.Pattern); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_CODEOWNERS",
+				"message": "unsupported pattern " + req.Pattern,
+			},
+		})
+		return
+	}
+
+	rule := models.CodeOwnerRule{
+		Pattern: req.Pattern,
+		Users:   req.Users,
+		Teams:   req.Teams,
+	}
+	if rule.Users == nil {
+		rule.Users = []string{}
+	}
+	if rule.Teams == nil {
+		rule.Teams = []string{}
+	}
+
+	createdRule, err := s.CodeOwnerStorage.AddCodeOwnerRule(context.Background(), rule)
+	if err != nil {
+		s.handleStorageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, RuleResponse{Rule: createdRule})
+}
+
+func (s *CodeOwnerService) Import(c *gin.Context) {
+	var req ImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	rules, err := Parse(req.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_CODEOWNERS",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	importedRules, err := s.CodeOwnerStorage.ReplaceCodeOwnerRules(context.Background(), rules)
+	if err != nil {
+		s.handleStorageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RulesResponse{Rules: importedRules})
+}
+
+func (s *CodeOwnerService) ListRules(c *gin.Context) {
+	rules, err := s.CodeOwnerStorage.GetCodeOwnerRules(context.Background())
+	if err != nil {
+		slog.Error("Failed to get code owner rules", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get code owner rules",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RulesResponse{Rules: rules})
+}
+
+func (s *CodeOwnerService) handleStorageError(c *gin.Context, err error) {
+	if err.Error() == "OWNER_NOT_FOUND" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "NOT_FOUND",
+				"message": "code owner user or team not found",
+			},
+		})
+		return
+	}
+	slog.Error("Failed to save code owner rules", "err", err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "failed to save code owner rules",
+		},
+	})
+}
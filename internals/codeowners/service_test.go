@@ -0,0 +1,125 @@
+package codeowners
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func setupRouter(service *CodeOwnerService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api/v1")
+	service.RegisterRoutes(api)
+	return r
+}
+
+func TestAddRule_Success(t *testing.T) {
+	codeOwnerStorage := mocks.NewMockCodeOwnerStorage()
+	service := New(codeOwnerStorage)
+	router := setupRouter(service)
+
+	reqBody := AddRuleRequest{
+		Pattern: "/migrations/",
+		Teams:   []string{"Backend"},
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/codeowners/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if len(codeOwnerStorage.Rules) != 1 || codeOwnerStorage.Rules[0].Teams[0] != "Backend" {
+		t.Errorf("Expected rule to be stored, got %+v", codeOwnerStorage.Rules)
+	}
+}
+
+func TestAddRule_NoOwners(t *testing.T) {
+	service := New(mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(AddRuleRequest{Pattern: "*.go"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/codeowners/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestImport_ReplacesRules(t *testing.T) {
+	codeOwnerStorage := mocks.NewMockCodeOwnerStorage()
+	codeOwnerStorage.Rules = []models.CodeOwnerRule{{ID: 1, Pattern: "*", Users: []string{"old"}}}
+	service := New(codeOwnerStorage)
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(ImportRequest{Content: "*.go @u1\n/docs/ @avito/Docs\n"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/codeowners/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response RulesResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.Rules) != 2 || response.Rules[1].Teams[0] != "Docs" {
+		t.Errorf("Expected 2 imported rules, got %+v", response.Rules)
+	}
+}
+
+func TestImport_InvalidContent(t *testing.T) {
+	service := New(mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(ImportRequest{Content: "*.go dev@example.com\n"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/codeowners/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestImport_UnknownOwner(t *testing.T) {
+	codeOwnerStorage := mocks.NewMockCodeOwnerStorage()
+	codeOwnerStorage.ReplaceCodeOwnerRulesFunc = func(ctx context.Context, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error) {
+		return nil, errors.New("OWNER_NOT_FOUND")
+	}
+	service := New(codeOwnerStorage)
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(ImportRequest{Content: "*.go @ghost\n"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/codeowners/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package mergepolicy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Rules a merge is checked against. RuleOpen and RuleNoChangesRequested
+// apply to every team, the rest only when the team policy enables them.
+const (
+	RuleOpen                  = "open"
+	RuleNoChangesRequested    = "no_changes_requested"
+	RuleMinApprovals          = "min_approvals"
+	RuleSeniorApproval        = "senior_approval"
+	RuleActiveReviewers       = "active_reviewers"
+	RuleChangeRequestCooldown = "change_request_cooldown"
+)
+
+// Result is the outcome of one rule.
+type Result struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Evaluate checks state against policy, in the order of the rule constants.
+func Evaluate(policy models.MergePolicy, state models.MergeState) []Result {
+	pr := state.PullRequest
+	results := []Result{
+		check(RuleOpen, pr.Status == models.StatusOpen, "PR is "+strings.ToLower(pr.Status)),
+	}
+
+	changesRequested := state.ChangesRequested()
+	if len(changesRequested) > 0 {
+		results = append(results, check(RuleNoChangesRequested, false, "changes requested by "+strings.Join(changesRequested, ", ")))
+	} else {
+		results = append(results, check(RuleNoChangesRequested, true, "no outstanding change requests"))
+	}
+
+	approvals := state.Approvals()
+	if policy.MinApprovals > 0 {
+		results = append(results, check(RuleMinApprovals, len(approvals) >= policy.MinApprovals,
+			fmt.Sprintf("%d of %d required approvals", len(approvals), policy.MinApprovals)))
+	}
+
+	if policy.SeniorTag != "" {
+		result := check(RuleSeniorApproval, false, "no approval from a reviewer tagged "+policy.SeniorTag)
+		for _, reviewer := range state.Reviewers {
+			if slices.Contains(approvals, reviewer.ID) && slices.Contains(reviewer.Tags, policy.SeniorTag) {
+				result = check(RuleSeniorApproval, true, "approved by "+reviewer.ID)
+				break
+			}
+		}
+		results = append(results, result)
+	}
+
+	if policy.BlockInactiveReviewers {
+		var inactive []string
+		for _, reviewer := range state.Reviewers {
+			if !reviewer.IsActive {
+				inactive = append(inactive, reviewer.ID)
+			}
+		}
+		if len(inactive) > 0 {
+			results = append(results, check(RuleActiveReviewers, false, "inactive reviewers: "+strings.Join(inactive, ", ")))
+		} else {
+			results = append(results, check(RuleActiveReviewers, true, "all assigned reviewers are active"))
+		}
+	}
+
+	if policy.ChangeRequestCooldownSeconds > 0 {
+		cooldown := time.Duration(policy.ChangeRequestCooldownSeconds) * time.Second
+		var last time.Time
+		for _, review := range state.Reviews {
+			if review.Decision == models.DecisionRequestChanges && review.CreatedAt.After(last) {
+				last = review.CreatedAt
+			}
+		}
+		if readyAt := last.Add(cooldown); !last.IsZero() && state.Now.Before(readyAt) {
+			results = append(results, check(RuleChangeRequestCooldown, false,
+				fmt.Sprintf("last change request was less than %s ago, wait %s", cooldown, readyAt.Sub(state.Now).Round(time.Second))))
+		} else {
+			results = append(results, check(RuleChangeRequestCooldown, true,
+				fmt.Sprintf("no change requests within the last %s", cooldown)))
+		}
+	}
+
+	return results
+}
+
+func check(rule string, passed bool, message string) Result {
+	return Result{Rule: rule, Passed: passed, Message: message}
+}
+
+// Mergeable reports whether every rule passed.
+func Mergeable(results []Result) bool {
+	return len(Failures(results)) == 0
+}
+
+// Failures returns the messages of the rules that did not pass.
+func Failures(results []Result) []string {
+	var failures []string
+	for _, result := range results {
+		if !result.Passed {
+			failures = append(failures, result.Message)
+		}
+	}
+	return failures
+}
+
+// Validate reports why policy cannot be used by a team with settings.
+func Validate(policy models.MergePolicy, settings models.TeamSettings) error {
+	if policy.MinApprovals < 0 || policy.MinApprovals > settings.MaxReviewers {
+		return errors.New("min_approvals must be between 0 and max_reviewers")
+	}
+	if policy.ChangeRequestCooldownSeconds < 0 {
+		return errors.New("change_request_cooldown_seconds must not be negative")
+	}
+	return nil
+}
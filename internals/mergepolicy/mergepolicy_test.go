@@ -0,0 +1,134 @@
+package mergepolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	review := func(reviewerID, decision string, ago time.Duration) models.Review {
+		return models.Review{ReviewerID: reviewerID, Decision: decision, CreatedAt: now.Add(-ago)}
+	}
+	state := func(reviews ...models.Review) models.MergeState {
+		return models.MergeState{
+			PullRequest: models.PullRequest{ID: "pr-1", Status: models.StatusOpen, AssignedReviewers: []string{"u2", "u3"}},
+			Reviewers: []models.User{
+				{ID: "u2", IsActive: true, Tags: []string{"senior"}},
+				{ID: "u3", IsActive: false},
+			},
+			Reviews: reviews,
+			Now:     now,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy models.MergePolicy
+		state  models.MergeState
+		failed []string
+	}{
+		{
+			name:  "empty policy",
+			state: state(),
+		},
+		{
+			name:   "outstanding change request",
+			state:  state(review("u3", models.DecisionRequestChanges, time.Hour)),
+			failed: []string{RuleNoChangesRequested},
+		},
+		{
+			name:   "min approvals",
+			policy: models.MergePolicy{MinApprovals: 2},
+			state:  state(review("u2", models.DecisionApprove, time.Hour)),
+			failed: []string{RuleMinApprovals},
+		},
+		{
+			name:   "approval of a non senior",
+			policy: models.MergePolicy{SeniorTag: "senior"},
+			state:  state(review("u3", models.DecisionApprove, time.Hour)),
+			failed: []string{RuleSeniorApproval},
+		},
+		{
+			name:   "senior approval",
+			policy: models.MergePolicy{SeniorTag: "senior"},
+			state:  state(review("u2", models.DecisionApprove, time.Hour)),
+		},
+		{
+			name:   "inactive reviewer",
+			policy: models.MergePolicy{BlockInactiveReviewers: true},
+			state:  state(),
+			failed: []string{RuleActiveReviewers},
+		},
+		{
+			name:   "change request within cooldown",
+			policy: models.MergePolicy{ChangeRequestCooldownSeconds: 3600},
+			state: state(
+				review("u2", models.DecisionRequestChanges, 30*time.Minute),
+				review("u2", models.DecisionApprove, 10*time.Minute),
+			),
+			failed: []string{RuleChangeRequestCooldown},
+		},
+		{
+			name:   "change request before cooldown",
+			policy: models.MergePolicy{ChangeRequestCooldownSeconds: 3600},
+			state: state(
+				review("u2", models.DecisionRequestChanges, 2*time.Hour),
+				review("u2", models.DecisionApprove, time.Hour),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Evaluate(tt.policy, tt.state)
+
+			var failed []string
+			for _, result := range results {
+				if !result.Passed {
+					failed = append(failed, result.Rule)
+				}
+			}
+			if len(failed) != len(tt.failed) {
+				t.Fatalf("Expected failed rules %v, got %v", tt.failed, results)
+			}
+			for i := range failed {
+				if failed[i] != tt.failed[i] {
+					t.Errorf("Expected failed rules %v, got %v", tt.failed, failed)
+				}
+			}
+			if Mergeable(results) != (len(tt.failed) == 0) {
+				t.Errorf("Mergeable disagrees with failed rules %v", failed)
+			}
+		})
+	}
+}
+
+func TestEvaluate_ListsOnlyEnabledRules(t *testing.T) {
+	state := models.MergeState{PullRequest: models.PullRequest{Status: models.StatusMerged}}
+
+	results := Evaluate(models.MergePolicy{}, state)
+
+	if len(results) != 2 || results[0].Rule != RuleOpen || results[1].Rule != RuleNoChangesRequested {
+		t.Fatalf("Expected only the rules every team has, got %v", results)
+	}
+	if results[0].Passed {
+		t.Error("Expected a merged PR to fail the open rule")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	settings := models.DefaultTeamSettings()
+
+	if err := Validate(models.MergePolicy{MinApprovals: settings.MaxReviewers}, settings); err != nil {
+		t.Errorf("Expected min_approvals up to max_reviewers to be valid, got %v", err)
+	}
+	if err := Validate(models.MergePolicy{MinApprovals: settings.MaxReviewers + 1}, settings); err == nil {
+		t.Error("Expected min_approvals above max_reviewers to be invalid")
+	}
+	if err := Validate(models.MergePolicy{ChangeRequestCooldownSeconds: -1}, settings); err == nil {
+		t.Error("Expected a negative cooldown to be invalid")
+	}
+}
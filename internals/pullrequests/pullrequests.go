@@ -2,10 +2,24 @@ package pullrequests
 
 import (
 	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand/v2"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/codeowners"
+	"github.com/sssciel/avito-backend-intership/internals/mergepolicy"
+	"github.com/sssciel/avito-backend-intership/internals/selection"
 	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
@@ -14,18 +28,23 @@ type PullRequestService struct {
 	RequestStorage    storage.RequestStorage
 	TeamStorage       storage.TeamStorage
 	UserStorage       storage.UserStorage
+	CodeOwnerStorage  storage.CodeOwnerStorage
 	GetAuthorTeamIDFn func(ctx context.Context, userID string) (int, error)
+	// NewRandFn returns the random source reviewer selection uses for seed.
+	NewRandFn func(seed string) *rand.Rand
 }
 
 var pullRequestPrefix = "pullRequest"
 
-func New(requestStorage storage.RequestStorage, teamStorage storage.TeamStorage, userStorage storage.UserStorage) *PullRequestService {
+func New(requestStorage storage.RequestStorage, teamStorage storage.TeamStorage, userStorage storage.UserStorage, codeOwnerStorage storage.CodeOwnerStorage) *PullRequestService {
 	s := &PullRequestService{
-		RequestStorage: requestStorage,
-		TeamStorage:    teamStorage,
-		UserStorage:    userStorage,
+		RequestStorage:   requestStorage,
+		TeamStorage:      teamStorage,
+		UserStorage:      userStorage,
+		CodeOwnerStorage: codeOwnerStorage,
 	}
 	s.GetAuthorTeamIDFn = s.getAuthorTeamID
+	s.NewRandFn = selection.NewRand
 	return s
 }
 
@@ -33,29 +52,157 @@ func (s *PullRequestService) RegisterRoutes(r *gin.RouterGroup) {
 	prRouter := r.Group("/" + pullRequestPrefix)
 
 	prRouter.POST("/create", s.CreatePullRequest)
+	prRouter.GET("/get", s.GetPullRequest)
+	prRouter.GET("/list", s.ListPullRequests)
+	prRouter.PATCH("/update", s.UpdatePullRequest)
+	prRouter.GET("/history", s.GetPullRequestHistory)
+	prRouter.GET("/timeline", s.GetTimeline)
+	prRouter.POST("/ready", s.ReadyPullRequest)
+	prRouter.POST("/close", s.ClosePullRequest)
+	prRouter.POST("/reopen", s.ReopenPullRequest)
+	prRouter.POST("/review", s.ReviewPullRequest)
+	prRouter.GET("/reviews", s.GetReviews)
+	prRouter.GET("/mergeability", s.GetMergeability)
 	prRouter.POST("/merge", s.MergePullRequest)
 	prRouter.POST("/reassign", s.ReassignReviewer)
+	prRouter.POST("/addReviewer", s.AddReviewer)
+	prRouter.POST("/removeReviewer", s.RemoveReviewer)
 }
 
 type CreatePRRequest struct {
-	PullRequestID   string `json:"pull_request_id" binding:"required"`
-	PullRequestName string `json:"pull_request_name" binding:"required"`
-	AuthorID        string `json:"author_id" binding:"required"`
+	PullRequestID   string   `json:"pull_request_id" binding:"required"`
+	PullRequestName string   `json:"pull_request_name" binding:"required"`
+	AuthorID        string   `json:"author_id" binding:"required"`
+	ReviewersCount  *int     `json:"reviewers_count,omitempty"`
+	ChangedPaths    []string `json:"changed_paths,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	// Seed overrides the pull request ID as the seed of reviewer selection.
+	Seed string `json:"seed,omitempty"`
+	// Draft creates the pull request without reviewers until it is ready;
+	// ReviewersCount and ChangedPaths are then passed to the ready request.
+	Draft bool `json:"draft,omitempty"`
+	// PreferredReviewers are assigned first when active and eligible, on
+	// creation or once a draft is ready; ExcludedReviewers are never
+	// assigned, replacements included.
+	PreferredReviewers []string `json:"preferred_reviewers,omitempty"`
+	ExcludedReviewers  []string `json:"excluded_reviewers,omitempty"`
+	// TeamName picks the team reviewers are drawn from instead of the
+	// author's primary team. The author must be one of its members.
+	TeamName string `json:"team_name,omitempty"`
+}
+
+// UpdatePRRequest changes the fields that are set.
+type UpdatePRRequest struct {
+	PullRequestID   string  `json:"pull_request_id" binding:"required"`
+	PullRequestName *string `json:"pull_request_name,omitempty"`
+	AuthorID        *string `json:"author_id,omitempty"`
+	PerformedBy     string  `json:"performed_by" binding:"required"`
+}
+
+// TransitionRequest moves a pull request to another status. ReviewersCount
+// and ChangedPaths apply when reviewers are assigned on entering OPEN.
+type TransitionRequest struct {
+	PullRequestID  string   `json:"pull_request_id" binding:"required"`
+	ReviewersCount *int     `json:"reviewers_count,omitempty"`
+	ChangedPaths   []string `json:"changed_paths,omitempty"`
 }
 
 type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
 }
 
+type ReviewRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	ReviewerID    string `json:"reviewer_id" binding:"required"`
+	Decision      string `json:"decision" binding:"required,oneof=APPROVE REQUEST_CHANGES COMMENT"`
+	Comment       string `json:"comment,omitempty"`
+}
+
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
 	OldReviewerID string `json:"old_reviewer_id" binding:"required"`
 }
 
+// ReviewerChangeRequest adds or removes a reviewer by hand. PerformedBy is
+// recorded with the change.
+type ReviewerChangeRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	ReviewerID    string `json:"reviewer_id" binding:"required"`
+	PerformedBy   string `json:"performed_by" binding:"required"`
+}
+
 type PRResponse struct {
 	PR models.PullRequest `json:"pr"`
 }
 
+type CreatePRResponse struct {
+	PR models.PullRequest `json:"pr"`
+	// UnhonoredPreferences lists preferred reviewers that were not assigned.
+	UnhonoredPreferences []UnhonoredPreference `json:"unhonored_preferences,omitempty"`
+}
+
+// Reasons a preferred reviewer was not assigned.
+const (
+	ReasonIsAuthor      = "IS_AUTHOR"
+	ReasonExcluded      = "EXCLUDED"
+	ReasonNotFound      = "NOT_FOUND"
+	ReasonInactive      = "INACTIVE"
+	ReasonUnavailable   = "UNAVAILABLE"
+	ReasonAtCapacity    = "AT_CAPACITY"
+	ReasonNotTeamMember = "NOT_TEAM_MEMBER"
+	ReasonNoSlot        = "NO_SLOT"
+)
+
+type UnhonoredPreference struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+type UpdatePRResponse struct {
+	PR      models.PullRequest         `json:"pr"`
+	Changes []models.PullRequestChange `json:"changes"`
+	// RemovedReviewer is the new author when they were a reviewer, and
+	// ReplacedBy the reviewer assigned in their place.
+	RemovedReviewer string `json:"removed_reviewer,omitempty"`
+	ReplacedBy      string `json:"replaced_by,omitempty"`
+}
+
+type HistoryResponse struct {
+	PullRequestID string                     `json:"pull_request_id"`
+	Changes       []models.PullRequestChange `json:"changes"`
+}
+
+type TimelineResponse struct {
+	PullRequestID string                    `json:"pull_request_id"`
+	Events        []models.PullRequestEvent `json:"events"`
+}
+
+type ListResponse struct {
+	PullRequests []models.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+type ReviewResponse struct {
+	Review models.Review `json:"review"`
+}
+
+type ReviewsResponse struct {
+	PullRequestID string          `json:"pull_request_id"`
+	Reviews       []models.Review `json:"reviews"`
+}
+
+type MergeabilityResponse struct {
+	PullRequestID string               `json:"pull_request_id"`
+	Status        string               `json:"status"`
+	Mergeable     bool                 `json:"mergeable"`
+	Rules         []mergepolicy.Result `json:"rules"`
+}
+
+type ReviewerChangeResponse struct {
+	PR     models.PullRequest    `json:"pr"`
+	Change models.ReviewerChange `json:"change"`
+}
+
 type ReassignResponse struct {
 	PR         models.PullRequest `json:"pr"`
 	ReplacedBy string             `json:"replaced_by"`
@@ -74,73 +221,1083 @@ func (s *PullRequestService) CreatePullRequest(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := context.Background()
+
+	teamID, ok := s.createTeamID(ctx, c, req)
+	if !ok {
+		return
+	}
+
+	pr := models.PullRequest{
+		ID:       req.PullRequestID,
+		Name:     req.PullRequestName,
+		AuthorID: req.AuthorID,
+		Status:   models.StatusOpen,
+		Labels:   models.NormalizeTags(req.Labels),
+	}
+
+	pr.SelectionSeed = req.Seed
+	if pr.SelectionSeed == "" {
+		pr.SelectionSeed = req.PullRequestID
+	}
+
+	prefs := newReviewerPreferences(req.AuthorID, req.PreferredReviewers, req.ExcludedReviewers)
+	pr.PreferredReviewers = prefs.preferred
+	pr.ExcludedReviewers = prefs.excludedIDs()
+
+	var owners []string
+	var reviewersCount int
+	var pick storage.ReviewerPicker
+	if req.Draft {
+		if req.ReviewersCount != nil || len(req.ChangedPaths) > 0 {
+			invalidRequest(c, "reviewers_count and changed_paths are passed to /pullRequest/ready for a draft")
+			return
+		}
+		pr.Status = models.StatusDraft
+	} else {
+		var ok bool
+		pick, owners, reviewersCount, ok = s.reviewerPicker(c, teamID, req.ReviewersCount, req.ChangedPaths, pr.SelectionSeed, prefs)
+		if !ok {
+			return
+		}
+	}
+
+	createdPR, err := s.RequestStorage.CreatePullRequest(ctx, pr, teamID, owners, reviewersCount, pick)
+	if err != nil {
+		if err.Error() == "PR_EXISTS" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    "PR_EXISTS",
+					"message": "PR id already exists",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to create PR", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to create pull request",
+			},
+		})
+		return
+	}
+
+	warnMissingReviewers(createdPR)
+	c.JSON(http.StatusCreated, CreatePRResponse{
+		PR:                   createdPR,
+		UnhonoredPreferences: s.explainUnhonored(ctx, prefs.unhonored),
+	})
+}
+
+// createTeamID resolves the team a new pull request draws reviewers from and
+// writes the error response when it cannot.
+func (s *PullRequestService) createTeamID(ctx context.Context, c *gin.Context, req CreatePRRequest) (int, bool) {
+	if req.TeamName == "" {
+		teamID, err := s.GetAuthorTeamIDFn(ctx, req.AuthorID)
+		if err != nil {
+			if err.Error() == "NOT_FOUND" {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"code":    "NOT_FOUND",
+						"message": "author or team not found",
+					},
+				})
+				return 0, false
+			}
+			slog.Error("Failed to get author team", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "failed to get author team",
+				},
+			})
+			return 0, false
+		}
+		return teamID, true
+	}
+
+	team, err := s.TeamStorage.GetTeamByName(ctx, req.TeamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return 0, false
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return 0, false
+	}
+
+	isMember := slices.ContainsFunc(team.Members, func(member models.User) bool {
+		return member.ID == req.AuthorID
+	})
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "NOT_TEAM_MEMBER",
+				"message": "author is not a member of the team",
+			},
+		})
+		return 0, false
+	}
+	return team.ID, true
+}
+
+// pullRequestTeamID returns the team reviewers of pr are drawn from. Pull
+// requests created before the team was recorded use the author's primary
+// team.
+func (s *PullRequestService) pullRequestTeamID(ctx context.Context, pr models.PullRequest) (int, error) {
+	if pr.TeamID != 0 {
+		return pr.TeamID, nil
+	}
+	return s.GetAuthorTeamIDFn(ctx, pr.AuthorID)
+}
+
+// reviewerPreferences carries the reviewers an author asked for or against.
+// rejected holds preferences refused before any selection, and pick adds to
+// them in unhonored the preferred reviewers it could not assign.
+type reviewerPreferences struct {
+	preferred []string
+	excluded  map[string]bool
+	rejected  []UnhonoredPreference
+	unhonored []UnhonoredPreference
+}
+
+func newReviewerPreferences(authorID string, preferred, excluded []string) *reviewerPreferences {
+	prefs := &reviewerPreferences{excluded: make(map[string]bool, len(excluded))}
+	for _, userID := range excluded {
+		prefs.excluded[userID] = true
+	}
+
+	seen := make(map[string]bool, len(preferred))
+	for _, userID := range preferred {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		switch {
+		case userID == authorID:
+			prefs.rejected = append(prefs.rejected, UnhonoredPreference{UserID: userID, Reason: ReasonIsAuthor})
+		case prefs.excluded[userID]:
+			prefs.rejected = append(prefs.rejected, UnhonoredPreference{UserID: userID, Reason: ReasonExcluded})
+		default:
+			prefs.preferred = append(prefs.preferred, userID)
+		}
+	}
+	prefs.unhonored = prefs.rejected
+	return prefs
+}
+
+// excludedIDs returns the excluded reviewers in a stable order.
+func (p *reviewerPreferences) excludedIDs() []string {
+	return slices.Sorted(maps.Keys(p.excluded))
+}
+
+// pick drops excluded candidates and takes preferred ones, in the requested
+// order, ahead of selectRest which fills the remaining of count slots.
+func (p *reviewerPreferences) pick(candidates []models.ReviewerCandidate, count int, selectRest func([]models.ReviewerCandidate, int) []string) []string {
+	unhonored := slices.Clone(p.rejected)
+
+	available := make(map[string]bool, len(candidates))
+	rest := make([]models.ReviewerCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !p.excluded[candidate.UserID] {
+			available[candidate.UserID] = true
+			rest = append(rest, candidate)
+		}
+	}
+
+	var chosen []string
+	for _, userID := range p.preferred {
+		switch {
+		case !available[userID]:
+			// Refined by explainUnhonored once the transaction is over.
+			unhonored = append(unhonored, UnhonoredPreference{UserID: userID, Reason: ReasonNotTeamMember})
+		case len(chosen) >= count:
+			unhonored = append(unhonored, UnhonoredPreference{UserID: userID, Reason: ReasonNoSlot})
+		default:
+			chosen = append(chosen, userID)
+		}
+	}
+	p.unhonored = unhonored
+
+	rest = slices.DeleteFunc(rest, func(c models.ReviewerCandidate) bool {
+		return slices.Contains(chosen, c.UserID)
+	})
+	return append(chosen, selectRest(rest, count-len(chosen))...)
+}
+
+// warnMissingReviewers logs pull requests left short of reviewers by
+// capacity limits.
+func warnMissingReviewers(pr models.PullRequest) {
+	if pr.MissingReviewers > 0 {
+		slog.Warn("Reviewers at capacity, pull request understaffed", "prID", pr.ID, "missing", pr.MissingReviewers)
+	}
+}
+
+// explainUnhonored tells unknown, inactive, absent and fully loaded users
+// apart from those outside the teams reviewers are chosen from.
+func (s *PullRequestService) explainUnhonored(ctx context.Context, unhonored []UnhonoredPreference) []UnhonoredPreference {
+	for i, u := range unhonored {
+		if u.Reason != ReasonNotTeamMember {
+			continue
+		}
+		isActive, err := s.UserStorage.GetIsActive(ctx, u.UserID)
+		switch {
+		case err != nil && err.Error() == "NOT_FOUND":
+			unhonored[i].Reason = ReasonNotFound
+		case err != nil:
+			slog.Error("Failed to get preferred reviewer status", "userID", u.UserID, "err", err)
+		case !isActive:
+			unhonored[i].Reason = ReasonInactive
+		default:
+			absent, err := s.UserStorage.IsAbsent(ctx, u.UserID)
+			if err != nil {
+				slog.Error("Failed to get preferred reviewer absence", "userID", u.UserID, "err", err)
+				continue
+			}
+			if absent {
+				unhonored[i].Reason = ReasonUnavailable
+				continue
+			}
+			capacity, err := s.UserStorage.GetReviewCapacity(ctx, u.UserID)
+			if err != nil {
+				slog.Error("Failed to get preferred reviewer capacity", "userID", u.UserID, "err", err)
+			} else if capacity.Full() {
+				unhonored[i].Reason = ReasonAtCapacity
+			}
+		}
+	}
+	return unhonored
+}
+
+// reviewerPicker prepares reviewer selection for a pull request of the team:
+// the reviewers count, the code owners of changedPaths, the author's
+// preferences when prefs is not nil and the team strategy seeded with seed.
+// It returns the picker, the code owners and the reviewers count, or writes
+// the error response and returns false when the selection cannot be prepared.
+func (s *PullRequestService) reviewerPicker(c *gin.Context, teamID int, requestedCount *int, changedPaths []string, seed string, prefs *reviewerPreferences) (storage.ReviewerPicker, []string, int, bool) {
+	ctx := context.Background()
+
+	settings, err := s.TeamStorage.GetTeamSettings(ctx, teamID)
+	if err != nil {
+		slog.Error("Failed to get team settings", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to assign reviewers",
+			},
+		})
+		return nil, nil, 0, false
+	}
+
+	reviewersCount := settings.RequiredReviewers
+	if requestedCount != nil {
+		if !settings.ReviewersCountAllowed(*requestedCount) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_REVIEWERS_COUNT",
+					"message": fmt.Sprintf("reviewers_count must be between %d and %d", settings.MinReviewers, settings.MaxReviewers),
+				},
+			})
+			return nil, nil, 0, false
+		}
+		reviewersCount = *requestedCount
+	}
+
+	selector, err := selection.Get(settings.ReviewerStrategy)
+	if err != nil {
+		slog.Error("Failed to get team reviewer selector", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to assign reviewers",
+			},
+		})
+		return nil, nil, 0, false
+	}
+
+	owners, err := s.codeOwners(ctx, changedPaths)
+	if err != nil {
+		slog.Error("Failed to get code owners", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to assign reviewers",
+			},
+		})
+		return nil, nil, 0, false
+	}
+
+	rng := s.NewRandFn(seed)
+	selectRest := func(candidates []models.ReviewerCandidate, count int) []string {
+		return selection.SelectWithFallback(selector, rng, candidates, count)
+	}
+	pick := func(candidates []models.ReviewerCandidate) []string {
+		if prefs != nil {
+			return prefs.pick(candidates, reviewersCount, selectRest)
+		}
+		return selectRest(candidates, reviewersCount)
+	}
+	return pick, owners, reviewersCount, true
+}
+
+// ReadyPullRequest takes a draft out for review and assigns its reviewers.
+func (s *PullRequestService) ReadyPullRequest(c *gin.Context) {
+	s.transition(c, models.TransitionReady, "mark ready")
+}
+
+// ClosePullRequest closes a draft or open pull request without merging it.
+func (s *PullRequestService) ClosePullRequest(c *gin.Context) {
+	s.transition(c, models.TransitionClose, "close")
+}
+
+// ReopenPullRequest brings a closed pull request back to review. Reviewers
+// are assigned if it was closed as a draft.
+func (s *PullRequestService) ReopenPullRequest(c *gin.Context) {
+	s.transition(c, models.TransitionReopen, "reopen")
+}
+
+func (s *PullRequestService) transition(c *gin.Context, transition models.PullRequestTransition, action string) {
+	var req TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	pr, err := s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
+	if err == nil && !transition.Allowed(pr.Status) {
+		err = errors.New(models.StatusError(pr.Status))
+	}
+	if err != nil {
+		s.writeTransitionError(c, err, action)
+		return
+	}
+
+	var teamID, reviewersCount int
+	var owners []string
+	var pick storage.ReviewerPicker
+	if transition.To == models.StatusOpen && len(pr.AssignedReviewers) == 0 {
+		var ok bool
+		teamID, pick, owners, reviewersCount, ok = s.transitionPicker(c, pr, req)
+		if !ok {
+			return
+		}
+	}
+
+	updatedPR, err := s.RequestStorage.TransitionPullRequest(ctx, req.PullRequestID, transition, teamID, owners, reviewersCount, pick)
+	if err != nil && err.Error() == "PICKER_REQUIRED" {
+		// The reviewers were removed after the pull request was read, e.g. by
+		// a transfer of authorship, so they are selected now.
+		pr, err = s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
+		if err == nil {
+			var ok bool
+			teamID, pick, owners, reviewersCount, ok = s.transitionPicker(c, pr, req)
+			if !ok {
+				return
+			}
+			updatedPR, err = s.RequestStorage.TransitionPullRequest(ctx, req.PullRequestID, transition, teamID, owners, reviewersCount, pick)
+		}
+	}
+	if err != nil {
+		s.writeTransitionError(c, err, action)
+		return
+	}
+	if pick != nil {
+		warnMissingReviewers(updatedPR)
+	}
+
+	c.JSON(http.StatusOK, PRResponse{PR: updatedPR})
+}
+
+// transitionPicker prepares the reviewer selection for pr entering OPEN
+// without reviewers, honoring the reviewer preferences stored with it. It
+// writes the error response itself and returns false on failure.
+func (s *PullRequestService) transitionPicker(c *gin.Context, pr models.PullRequest, req TransitionRequest) (int, storage.ReviewerPicker, []string, int, bool) {
+	teamID, err := s.pullRequestTeamID(context.Background(), pr)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "author or team not found",
+				},
+			})
+			return 0, nil, nil, 0, false
+		}
+		slog.Error("Failed to get author team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get author team",
+			},
+		})
+		return 0, nil, nil, 0, false
+	}
+
+	seed := pr.SelectionSeed
+	if seed == "" {
+		seed = pr.ID
+	}
+	prefs := newReviewerPreferences(pr.AuthorID, pr.PreferredReviewers, pr.ExcludedReviewers)
+	pick, owners, reviewersCount, ok := s.reviewerPicker(c, teamID, req.ReviewersCount, req.ChangedPaths, seed, prefs)
+	return teamID, pick, owners, reviewersCount, ok
+}
+
+func (s *PullRequestService) writeTransitionError(c *gin.Context, err error, action string) {
+	if err.Error() == "NOT_FOUND" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "NOT_FOUND",
+				"message": "pull request not found",
+			},
+		})
+		return
+	}
+	if writeStatusError(c, err, action) {
+		return
+	}
+	slog.Error("Failed to "+action+" PR", "err", err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "failed to " + action + " pull request",
+		},
+	})
+}
+
+// writeStatusError answers 409 when err refused action in the current status
+// of the pull request, e.g. PR_MERGED.
+func writeStatusError(c *gin.Context, err error, action string) bool {
+	for _, status := range []string{models.StatusDraft, models.StatusOpen, models.StatusMerged, models.StatusClosed} {
+		if err.Error() == models.StatusError(status) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    err.Error(),
+					"message": fmt.Sprintf("cannot %s: PR is %s", action, strings.ToLower(status)),
+				},
+			})
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PullRequestService) GetPullRequest(c *gin.Context) {
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": "pull_request_id query parameter is required",
+			},
+		})
+		return
+	}
+
+	pr, err := s.RequestStorage.GetPullRequest(context.Background(), pullRequestID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get PR", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get pull request",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PRResponse{PR: pr})
+}
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var listStatuses = map[string]bool{
+	models.StatusDraft:  true,
+	models.StatusOpen:   true,
+	models.StatusMerged: true,
+	models.StatusClosed: true,
+}
+
+var listSortFields = map[string]bool{
+	models.SortByCreatedAt: true,
+	models.SortByMergedAt:  true,
+	models.SortByName:      true,
+	models.SortByID:        true,
+}
+
+// listCursor is the opaque next_cursor. It remembers the ordering it was
+// issued for, so it cannot be replayed against a different one.
+type listCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d"`
+	models.PullRequestCursor
+}
+
+// UpdatePullRequest renames a pull request or transfers its authorship. A new
+// author who reviews the pull request is replaced by a member of their team.
+func (s *PullRequestService) UpdatePullRequest(c *gin.Context) {
+	var req UpdatePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	if req.PullRequestName == nil && req.AuthorID == nil {
+		invalidRequest(c, "pull_request_name or author_id is required")
+		return
+	}
+	if (req.PullRequestName != nil && *req.PullRequestName == "") || (req.AuthorID != nil && *req.AuthorID == "") {
+		invalidRequest(c, "pull_request_name and author_id cannot be empty")
+		return
+	}
+
+	ctx := context.Background()
+
+	pr, err := s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
+	if err != nil {
+		writeUpdateError(c, err)
+		return
+	}
+
+	authorID := pr.AuthorID
+	if req.AuthorID != nil {
+		authorID = *req.AuthorID
+	}
+
+	// The pull request stays with its team when the author changes.
+	pr.AuthorID = authorID
+	teamID, err := s.pullRequestTeamID(ctx, pr)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "author or team not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get author team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get author team",
+			},
+		})
+		return
+	}
+
+	selector, err := s.teamSelector(ctx, teamID)
+	if err != nil {
+		slog.Error("Failed to get team reviewer selector", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to update pull request",
+			},
+		})
+		return
+	}
+
+	rng := s.NewRandFn(req.PullRequestID + "/" + authorID)
+	pick := func(candidates []models.ReviewerCandidate) []string {
+		return selection.SelectWithFallback(selector, rng, candidates, 1)
+	}
+
+	result, err := s.RequestStorage.UpdatePullRequest(ctx, models.PullRequestUpdate{
+		PullRequestID: req.PullRequestID,
+		Name:          req.PullRequestName,
+		AuthorID:      req.AuthorID,
+		ChangedBy:     req.PerformedBy,
+	}, teamID, pick)
+	if err != nil {
+		writeUpdateError(c, err)
+		return
+	}
+
+	changes := result.Changes
+	if changes == nil {
+		changes = []models.PullRequestChange{}
+	}
+	c.JSON(http.StatusOK, UpdatePRResponse{
+		PR:              result.PullRequest,
+		Changes:         changes,
+		RemovedReviewer: result.RemovedReviewer,
+		ReplacedBy:      result.ReplacedBy,
+	})
+}
+
+func writeUpdateError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "NOT_FOUND":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "NOT_FOUND",
+				"message": "pull request not found",
+			},
+		})
+	case err.Error() == "AUTHOR_NOT_FOUND":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "NOT_FOUND",
+				"message": "author not found",
+			},
+		})
+	case writeStatusError(c, err, "update"):
+	default:
+		slog.Error("Failed to update PR", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to update pull request",
+			},
+		})
+	}
+}
+
+// GetPullRequestHistory lists the field changes of a pull request, oldest
+// first.
+func (s *PullRequestService) GetPullRequestHistory(c *gin.Context) {
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		invalidRequest(c, "pull_request_id query parameter is required")
+		return
+	}
+
+	changes, err := s.RequestStorage.GetPullRequestChanges(context.Background(), pullRequestID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get PR history", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get pull request history",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, HistoryResponse{PullRequestID: pullRequestID, Changes: changes})
+}
+
+// GetTimeline lists what happened to a pull request, oldest first.
+func (s *PullRequestService) GetTimeline(c *gin.Context) {
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		invalidRequest(c, "pull_request_id query parameter is required")
+		return
+	}
+
+	events, err := s.RequestStorage.GetPullRequestEvents(context.Background(), pullRequestID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get PR timeline", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get pull request timeline",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TimelineResponse{PullRequestID: pullRequestID, Events: events})
+}
+
+func (s *PullRequestService) ListPullRequests(c *gin.Context) {
+	ctx := context.Background()
+
+	filter := models.PullRequestFilter{
+		Status:     c.Query("status"),
+		AuthorID:   c.Query("author_id"),
+		ReviewerID: c.Query("reviewer_id"),
+		SortBy:     c.DefaultQuery("sort_by", models.SortByCreatedAt),
+		Limit:      defaultListLimit,
+	}
+
+	if filter.Status != "" && !listStatuses[filter.Status] {
+		invalidRequest(c, "unknown status "+filter.Status)
+		return
+	}
+	if !listSortFields[filter.SortBy] {
+		invalidRequest(c, "unknown sort_by "+filter.SortBy)
+		return
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		invalidRequest(c, "order must be asc or desc")
+		return
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxListLimit {
+			invalidRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return
+		}
+		filter.Limit = n
+	}
+
+	if understaffed := c.Query("understaffed"); understaffed != "" {
+		var err error
+		filter.Understaffed, err = strconv.ParseBool(understaffed)
+		if err != nil {
+			invalidRequest(c, "understaffed must be true or false")
+			return
+		}
+	}
+
+	for _, r := range []struct {
+		param  string
+		target *sql.NullTime
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	} {
+		value := c.Query(r.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			invalidRequest(c, r.param+" must be an RFC 3339 timestamp")
+			return
+		}
+		*r.target = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, ok := decodeCursor(cursor, filter)
+		if !ok {
+			invalidRequest(c, "invalid cursor")
+			return
+		}
+		filter.After = &after
+	}
+
+	if teamName := c.Query("team_name"); teamName != "" {
+		team, err := s.TeamStorage.GetTeamByName(ctx, teamName)
+		if err != nil {
+			if err.Error() == "NOT_FOUND" {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"code":    "NOT_FOUND",
+						"message": "team not found",
+					},
+				})
+				return
+			}
+			slog.Error("Failed to get team", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "failed to list pull requests",
+				},
+			})
+			return
+		}
+		filter.TeamID = team.ID
+	}
+
+	page, err := s.RequestStorage.ListPullRequests(ctx, filter)
+	if err != nil {
+		slog.Error("Failed to list PRs", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to list pull requests",
+			},
+		})
+		return
+	}
+
+	response := ListResponse{PullRequests: page.PullRequests}
+	if response.PullRequests == nil {
+		response.PullRequests = []models.PullRequest{}
+	}
+	if page.Next != nil {
+		response.NextCursor = encodeCursor(*page.Next, filter)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func encodeCursor(next models.PullRequestCursor, filter models.PullRequestFilter) string {
+	data, _ := json.Marshal(listCursor{
+		SortBy:            filter.SortBy,
+		Descending:        filter.Descending,
+		PullRequestCursor: next,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, filter models.PullRequestFilter) (models.PullRequestCursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.PullRequestCursor{}, false
+	}
+
+	var decoded listCursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return models.PullRequestCursor{}, false
+	}
+	if decoded.SortBy != filter.SortBy || decoded.Descending != filter.Descending || decoded.ID == "" {
+		return models.PullRequestCursor{}, false
+	}
+	return decoded.PullRequestCursor, true
+}
+
+func invalidRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"code":    "INVALID_REQUEST",
+			"message": message,
+		},
+	})
+}
+
+func (s *PullRequestService) MergePullRequest(c *gin.Context) {
+	var req MergePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	pr, err := s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get PR", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to merge pull request",
+			},
+		})
+		return
+	}
+
+	// An already merged pull request is returned as is, without checks.
+	var policy models.MergePolicy
+	if pr.Status != models.StatusMerged {
+		policy, err = s.mergePolicy(ctx, pr)
+		if err != nil {
+			if err.Error() == "NOT_FOUND" {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"code":    "NOT_FOUND",
+						"message": "author or team not found",
+					},
+				})
+				return
+			}
+			slog.Error("Failed to get team settings", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "failed to merge pull request",
+				},
+			})
+			return
+		}
+	}
+
+	var failures []string
+	check := func(state models.MergeState) error {
+		failures = mergepolicy.Failures(mergepolicy.Evaluate(policy, state))
+		if len(failures) > 0 {
+			return errors.New("NOT_APPROVED")
+		}
+		return nil
+	}
 
-	teamID, err := s.GetAuthorTeamIDFn(ctx, req.AuthorID)
+	mergedPR, err := s.RequestStorage.MergePullRequest(ctx, req.PullRequestID, check)
 	if err != nil {
+		if err.Error() == "NOT_APPROVED" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    "NOT_APPROVED",
+					"message": "cannot merge: " + strings.Join(failures, "; "),
+				},
+			})
+			return
+		}
 		if err.Error() == "NOT_FOUND" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
 					"code":    "NOT_FOUND",
-					"message": "author or team not found",
+					"message": "pull request not found",
 				},
 			})
 			return
 		}
-		slog.Error("Failed to get author team", "err", err)
+		if writeStatusError(c, err, "merge") {
+			return
+		}
+		slog.Error("Failed to merge PR", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
-				"message": "failed to get author team",
+				"message": "failed to merge pull request",
 			},
 		})
 		return
 	}
 
-	reviewerIDs, err := s.TeamStorage.GetRandomReviewers(ctx, teamID, req.AuthorID, 2)
+	c.JSON(http.StatusOK, PRResponse{PR: mergedPR})
+}
+
+// mergePolicy returns the merge policy of the pull request's team.
+func (s *PullRequestService) mergePolicy(ctx context.Context, pr models.PullRequest) (models.MergePolicy, error) {
+	teamID, err := s.pullRequestTeamID(ctx, pr)
+	if err != nil {
+		return models.MergePolicy{}, err
+	}
+	settings, err := s.TeamStorage.GetTeamSettings(ctx, teamID)
+	if err != nil {
+		return models.MergePolicy{}, err
+	}
+	return settings.MergePolicy, nil
+}
+
+// GetMergeability explains which rules of the team merge policy a merge of
+// the pull request would pass or fail right now.
+func (s *PullRequestService) GetMergeability(c *gin.Context) {
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		invalidRequest(c, "pull_request_id query parameter is required")
+		return
+	}
+
+	ctx := context.Background()
+
+	state, err := s.RequestStorage.GetMergeState(ctx, pullRequestID)
 	if err != nil {
-		slog.Error("Failed to get reviewers", "err", err)
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get merge state", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
-				"message": "failed to assign reviewers",
+				"message": "failed to check mergeability",
 			},
 		})
 		return
 	}
 
-	pr := models.PullRequest{
-		ID:       req.PullRequestID,
-		Name:     req.PullRequestName,
-		AuthorID: req.AuthorID,
-	}
-
-	createdPR, err := s.RequestStorage.CreatePullRequest(ctx, pr, reviewerIDs)
+	policy, err := s.mergePolicy(ctx, state.PullRequest)
 	if err != nil {
-		if err.Error() == "PR_EXISTS" {
-			c.JSON(http.StatusConflict, gin.H{
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
-					"code":    "PR_EXISTS",
-					"message": "PR id already exists",
+					"code":    "NOT_FOUND",
+					"message": "author or team not found",
 				},
 			})
 			return
 		}
-		slog.Error("Failed to create PR", "err", err)
+		slog.Error("Failed to get merge policy", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
-				"message": "failed to create pull request",
+				"message": "failed to check mergeability",
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, PRResponse{PR: createdPR})
+	results := mergepolicy.Evaluate(policy, state)
+	c.JSON(http.StatusOK, MergeabilityResponse{
+		PullRequestID: pullRequestID,
+		Status:        state.PullRequest.Status,
+		Mergeable:     mergepolicy.Mergeable(results),
+		Rules:         results,
+	})
 }
 
-func (s *PullRequestService) MergePullRequest(c *gin.Context) {
-	var req MergePRRequest
+func (s *PullRequestService) ReviewPullRequest(c *gin.Context) {
+	var req ReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		slog.Error("Invalid request body", "err", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -152,7 +1309,12 @@ func (s *PullRequestService) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	mergedPR, err := s.RequestStorage.MergePullRequest(context.Background(), req.PullRequestID)
+	review, err := s.RequestStorage.AddReview(context.Background(), models.Review{
+		PullRequestID: req.PullRequestID,
+		ReviewerID:    req.ReviewerID,
+		Decision:      req.Decision,
+		Comment:       req.Comment,
+	})
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -163,17 +1325,124 @@ func (s *PullRequestService) MergePullRequest(c *gin.Context) {
 			})
 			return
 		}
-		slog.Error("Failed to merge PR", "err", err)
+		if err.Error() == "NOT_ASSIGNED" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    "NOT_ASSIGNED",
+					"message": "reviewer is not assigned to this PR",
+				},
+			})
+			return
+		}
+		if writeStatusError(c, err, "review") {
+			return
+		}
+		slog.Error("Failed to add review", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
-				"message": "failed to merge pull request",
+				"message": "failed to add review",
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, PRResponse{PR: mergedPR})
+	c.JSON(http.StatusCreated, ReviewResponse{Review: review})
+}
+
+func (s *PullRequestService) GetReviews(c *gin.Context) {
+	pullRequestID := c.Query("pull_request_id")
+	if pullRequestID == "" {
+		invalidRequest(c, "pull_request_id query parameter is required")
+		return
+	}
+
+	reviews, err := s.RequestStorage.GetReviews(context.Background(), pullRequestID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "pull request not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get reviews", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get reviews",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReviewsResponse{PullRequestID: pullRequestID, Reviews: reviews})
+}
+
+// replacementPicker picks the one reviewer of teamID taking over from
+// oldReviewerID on a pull request.
+func (s *PullRequestService) replacementPicker(ctx context.Context, teamID int, pullRequestID, oldReviewerID string) (storage.ReviewerPicker, error) {
+	selector, err := s.teamSelector(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := s.NewRandFn(pullRequestID + "/" + oldReviewerID)
+	return func(candidates []models.ReviewerCandidate) []string {
+		return selection.SelectWithFallback(selector, rng, candidates, 1)
+	}, nil
+}
+
+// replacementTeamID returns the team replacements on the pull request come
+// from, the same one its reviewers were assigned from.
+func (s *PullRequestService) replacementTeamID(ctx context.Context, pullRequestID string) (int, error) {
+	pr, err := s.RequestStorage.GetPullRequest(ctx, pullRequestID)
+	if err != nil {
+		return 0, fmt.Errorf("get pull request: %w", err)
+	}
+
+	teamID, err := s.pullRequestTeamID(ctx, pr)
+	if err != nil {
+		return 0, fmt.Errorf("get pull request team: %w", err)
+	}
+	return teamID, nil
+}
+
+// ReassignOverdue replaces a reviewer who missed the review SLA the way
+// ReassignReviewer does. It returns the new reviewer, or an empty string
+// when nobody could take over and the reviewer was kept.
+func (s *PullRequestService) ReassignOverdue(ctx context.Context, assignment models.SLAAssignment) (string, error) {
+	teamID, err := s.replacementTeamID(ctx, assignment.PullRequestID)
+	if err != nil {
+		return "", err
+	}
+
+	pick, err := s.replacementPicker(ctx, teamID, assignment.PullRequestID, assignment.ReviewerID)
+	if err != nil {
+		return "", fmt.Errorf("get team reviewer selector: %w", err)
+	}
+
+	_, newReviewerID, err := s.RequestStorage.ReassignOverdueReviewer(ctx, assignment, teamID, pick)
+	return newReviewerID, err
+}
+
+// ReassignAbsent hands a review over from a reviewer who is out of office the
+// way ReassignReviewer does and returns the new reviewer.
+func (s *PullRequestService) ReassignAbsent(ctx context.Context, pullRequestID, reviewerID string) (string, error) {
+	teamID, err := s.replacementTeamID(ctx, pullRequestID)
+	if err != nil {
+		return "", err
+	}
+
+	pick, err := s.replacementPicker(ctx, teamID, pullRequestID, reviewerID)
+	if err != nil {
+		return "", fmt.Errorf("get team reviewer selector: %w", err)
+	}
+
+	_, newReviewerID, err := s.RequestStorage.ReassignReviewer(ctx, pullRequestID, reviewerID, teamID, pick, models.EventReasonAbsence)
+	return newReviewerID, err
 }
 
 func (s *PullRequestService) ReassignReviewer(c *gin.Context) {
@@ -191,41 +1460,51 @@ func (s *PullRequestService) ReassignReviewer(c *gin.Context) {
 
 	ctx := context.Background()
 
-	teamID, err := s.GetAuthorTeamIDFn(ctx, req.OldReviewerID)
+	pr, err := s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
 					"code":    "NOT_FOUND",
-					"message": "reviewer not found",
+					"message": "pull request not found",
 				},
 			})
 			return
 		}
-		slog.Error("Failed to get reviewer team", "err", err)
+		slog.Error("Failed to get pull request", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
-				"message": "failed to get reviewer team",
+				"message": "failed to get pull request",
 			},
 		})
 		return
 	}
 
-	excludeIDs := []string{req.OldReviewerID}
-
-	newReviewerID, err := s.TeamStorage.GetReplacementCandidate(ctx, teamID, excludeIDs)
+	teamID, err := s.pullRequestTeamID(ctx, pr)
 	if err != nil {
-		if err.Error() == "NO_CANDIDATE" {
-			c.JSON(http.StatusConflict, gin.H{
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
-					"code":    "NO_CANDIDATE",
-					"message": "no active replacement candidate in team",
+					"code":    "NOT_FOUND",
+					"message": "author or team not found",
 				},
 			})
 			return
 		}
-		slog.Error("Failed to get replacement candidate", "err", err)
+		slog.Error("Failed to get pull request team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get pull request team",
+			},
+		})
+		return
+	}
+
+	pick, err := s.replacementPicker(ctx, teamID, req.PullRequestID, req.OldReviewerID)
+	if err != nil {
+		slog.Error("Failed to get team reviewer selector", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -235,7 +1514,7 @@ func (s *PullRequestService) ReassignReviewer(c *gin.Context) {
 		return
 	}
 
-	updatedPR, err := s.RequestStorage.ReassignReviewer(ctx, req.PullRequestID, req.OldReviewerID, newReviewerID)
+	updatedPR, newReviewerID, err := s.RequestStorage.ReassignReviewer(ctx, req.PullRequestID, req.OldReviewerID, teamID, pick, models.EventReasonReassign)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -246,20 +1525,23 @@ func (s *PullRequestService) ReassignReviewer(c *gin.Context) {
 			})
 			return
 		}
-		if err.Error() == "PR_MERGED" {
+		if writeStatusError(c, err, "reassign") {
+			return
+		}
+		if err.Error() == "NOT_ASSIGNED" {
 			c.JSON(http.StatusConflict, gin.H{
 				"error": gin.H{
-					"code":    "PR_MERGED",
-					"message": "cannot reassign on merged PR",
+					"code":    "NOT_ASSIGNED",
+					"message": "reviewer is not assigned to this PR",
 				},
 			})
 			return
 		}
-		if err.Error() == "NOT_ASSIGNED" {
+		if err.Error() == "NO_CANDIDATE" {
 			c.JSON(http.StatusConflict, gin.H{
 				"error": gin.H{
-					"code":    "NOT_ASSIGNED",
-					"message": "reviewer is not assigned to this PR",
+					"code":    "NO_CANDIDATE",
+					"message": "no active replacement candidate in team",
 				},
 			})
 			return
@@ -280,6 +1562,177 @@ func (s *PullRequestService) ReassignReviewer(c *gin.Context) {
 	})
 }
 
+// AddReviewer assigns an extra reviewer chosen by hand. The reviewer must be
+// an active member of the author's team or of one of its fallback teams.
+func (s *PullRequestService) AddReviewer(c *gin.Context) {
+	var req ReviewerChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	pr, err := s.RequestStorage.GetPullRequest(ctx, req.PullRequestID)
+	if err != nil {
+		writeReviewerChangeError(c, err, "add reviewer")
+		return
+	}
+
+	teamID, err := s.pullRequestTeamID(ctx, pr)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "author not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get author team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get author team",
+			},
+		})
+		return
+	}
+
+	updatedPR, change, err := s.RequestStorage.AddReviewer(ctx, models.ReviewerChange{
+		PullRequestID: req.PullRequestID,
+		ReviewerID:    req.ReviewerID,
+		PerformedBy:   req.PerformedBy,
+	}, teamID)
+	if err != nil {
+		writeReviewerChangeError(c, err, "add reviewer")
+		return
+	}
+
+	c.JSON(http.StatusOK, ReviewerChangeResponse{PR: updatedPR, Change: change})
+}
+
+// RemoveReviewer unassigns a reviewer without picking a replacement.
+func (s *PullRequestService) RemoveReviewer(c *gin.Context) {
+	var req ReviewerChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	updatedPR, change, err := s.RequestStorage.RemoveReviewer(context.Background(), models.ReviewerChange{
+		PullRequestID: req.PullRequestID,
+		ReviewerID:    req.ReviewerID,
+		PerformedBy:   req.PerformedBy,
+	})
+	if err != nil {
+		writeReviewerChangeError(c, err, "remove reviewer")
+		return
+	}
+
+	c.JSON(http.StatusOK, ReviewerChangeResponse{PR: updatedPR, Change: change})
+}
+
+var reviewerChangeErrors = map[string]struct {
+	status  int
+	code    string
+	message string
+}{
+	"NOT_FOUND":            {http.StatusNotFound, "NOT_FOUND", "pull request not found"},
+	"REVIEWER_NOT_FOUND":   {http.StatusNotFound, "NOT_FOUND", "reviewer not found"},
+	"REVIEWER_IS_AUTHOR":   {http.StatusConflict, "REVIEWER_IS_AUTHOR", "author cannot review own PR"},
+	"REVIEWER_INACTIVE":    {http.StatusConflict, "REVIEWER_INACTIVE", "reviewer is not active"},
+	"REVIEWER_UNAVAILABLE": {http.StatusConflict, "REVIEWER_UNAVAILABLE", "reviewer is absent"},
+	"NOT_TEAM_MEMBER":      {http.StatusConflict, "NOT_TEAM_MEMBER", "reviewer is not in the pull request's team or its fallback teams"},
+	"ALREADY_ASSIGNED":     {http.StatusConflict, "ALREADY_ASSIGNED", "reviewer is already assigned to this PR"},
+	"NOT_ASSIGNED":         {http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR"},
+}
+
+func writeReviewerChangeError(c *gin.Context, err error, action string) {
+	if known, ok := reviewerChangeErrors[err.Error()]; ok {
+		c.JSON(known.status, gin.H{
+			"error": gin.H{
+				"code":    known.code,
+				"message": known.message,
+			},
+		})
+		return
+	}
+	if writeStatusError(c, err, action) {
+		return
+	}
+	slog.Error("Failed to "+action, "err", err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "failed to " + action,
+		},
+	})
+}
+
+func (s *PullRequestService) teamSelector(ctx context.Context, teamID int) (selection.ReviewerSelector, error) {
+	settings, err := s.TeamStorage.GetTeamSettings(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return selection.Get(settings.ReviewerStrategy)
+}
+
+// codeOwners returns the users owning the changed paths, with owner teams
+// expanded into their members.
+func (s *PullRequestService) codeOwners(ctx context.Context, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	rules, err := s.CodeOwnerStorage.GetCodeOwnerRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+	add := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			owners = append(owners, userID)
+		}
+	}
+
+	for _, rule := range codeowners.Owners(rules, paths) {
+		for _, userID := range rule.Users {
+			add(userID)
+		}
+		for _, teamName := range rule.Teams {
+			team, err := s.TeamStorage.GetTeamByName(ctx, teamName)
+			if err != nil {
+				if err.Error() == "NOT_FOUND" {
+					continue
+				}
+				return nil, err
+			}
+			for _, member := range team.Members {
+				add(member.ID)
+			}
+		}
+	}
+
+	return owners, nil
+}
+
 func (s *PullRequestService) getAuthorTeamID(ctx context.Context, userID string) (int, error) {
 	return s.UserStorage.GetUserTeamID(ctx, userID)
 }
@@ -3,13 +3,17 @@ package pullrequests
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/selection"
 	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
@@ -35,14 +39,12 @@ func TestCreatePullRequest_Success(t *testing.T) {
 			{ID: "u2", Username: "Bob", IsActive: true},
 			{ID: "u3", Username: "Charlie", IsActive: true},
 		},
+		Settings: models.DefaultTeamSettings(),
 	}
 	teamStorage.TeamsByID[1] = team
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
 
-	teamStorage.GetRandomReviewersFunc = func(ctx context.Context, teamID int, authorID string, limit int) ([]string, error) {
-		return []string{"u2", "u3"}, nil
-	}
-
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -80,6 +82,88 @@ func TestCreatePullRequest_Success(t *testing.T) {
 	}
 }
 
+func TestCreatePullRequest_TeamName(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	// u1 belongs to Backend, their primary team, and to Platform.
+	backend := models.Team{
+		ID:       1,
+		Name:     "Backend",
+		Members:  []models.User{{ID: "u1", IsActive: true}, {ID: "u2", IsActive: true}},
+		Settings: models.DefaultTeamSettings(),
+	}
+	platform := models.Team{
+		ID:       2,
+		Name:     "Platform",
+		Members:  []models.User{{ID: "u1", IsActive: true}, {ID: "u3", IsActive: true}},
+		Settings: models.DefaultTeamSettings(),
+	}
+	for _, team := range []models.Team{backend, platform} {
+		teamStorage.Teams[team.Name] = team
+		teamStorage.TeamsByID[team.ID] = team
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}}
+	requestStorage.TeamCandidates[2] = []models.ReviewerCandidate{{UserID: "u3"}}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	tests := []struct {
+		name         string
+		prID         string
+		authorID     string
+		teamName     string
+		expectedCode int
+		reviewer     string
+	}{
+		{"primary team by default", "pr-1", "u1", "", http.StatusCreated, "u2"},
+		{"named team", "pr-2", "u1", "Platform", http.StatusCreated, "u3"},
+		{"author not a member", "pr-3", "u2", "Platform", http.StatusBadRequest, ""},
+		{"unknown team", "pr-4", "u1", "Mobile", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(CreatePRRequest{
+				PullRequestID:   tt.prID,
+				PullRequestName: "Add feature",
+				AuthorID:        tt.authorID,
+				TeamName:        tt.teamName,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.reviewer == "" {
+				return
+			}
+
+			var response PRResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if len(response.PR.AssignedReviewers) != 1 || response.PR.AssignedReviewers[0] != tt.reviewer {
+				t.Errorf("Expected reviewers [%s], got %v", tt.reviewer, response.PR.AssignedReviewers)
+			}
+		})
+	}
+
+	if _, exists := requestStorage.PullRequests["pr-3"]; exists {
+		t.Error("Expected no pull request created for a non-member author")
+	}
+	if requestStorage.PullRequests["pr-2"].TeamID != 2 {
+		t.Errorf("Expected pr-2 to keep team 2, got %d", requestStorage.PullRequests["pr-2"].TeamID)
+	}
+}
+
 func TestCreatePullRequest_AlreadyExists(t *testing.T) {
 	requestStorage := mocks.NewMockRequestStorage()
 	teamStorage := mocks.NewMockTeamStorage()
@@ -92,11 +176,9 @@ func TestCreatePullRequest_AlreadyExists(t *testing.T) {
 		Status:   "OPEN",
 	}
 
-	teamStorage.GetRandomReviewersFunc = func(ctx context.Context, teamID int, authorID string, limit int) ([]string, error) {
-		return []string{"u2"}, nil
-	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -134,7 +216,9 @@ func TestMergePullRequest_Success(t *testing.T) {
 		AssignedReviewers: []string{"u2"},
 	}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	userStorage.UserTeams["u1"] = 1
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	router := setupRouter(service)
 
 	reqBody := MergePRRequest{
@@ -165,7 +249,7 @@ func TestMergePullRequest_NotFound(t *testing.T) {
 	teamStorage := mocks.NewMockTeamStorage()
 	userStorage := mocks.NewMockUserStorage()
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	router := setupRouter(service)
 
 	reqBody := MergePRRequest{
@@ -197,7 +281,7 @@ func TestMergePullRequest_Idempotent(t *testing.T) {
 		AssignedReviewers: []string{"u2"},
 	}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	router := setupRouter(service)
 
 	reqBody := MergePRRequest{
@@ -238,8 +322,9 @@ func TestReassignReviewer_Success(t *testing.T) {
 		},
 	}
 	teamStorage.TeamsByID[1] = team
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -265,8 +350,113 @@ func TestReassignReviewer_Success(t *testing.T) {
 	var response ReassignResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
 
-	if response.ReplacedBy == "" {
-		t.Error("Expected ReplacedBy to be set")
+	if response.ReplacedBy != "u3" {
+		t.Errorf("Expected ReplacedBy to be 'u3', got %q", response.ReplacedBy)
+	}
+}
+
+func TestReassignReviewer_ExcludesAuthorAndCurrentReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            "OPEN",
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := ReassignRequest{
+		PullRequestID: "pr-1",
+		OldReviewerID: "u2",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/reassign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response ReassignResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.ReplacedBy != "u4" {
+		t.Errorf("Expected ReplacedBy to be 'u4', got %q", response.ReplacedBy)
+	}
+	if len(response.PR.AssignedReviewers) != 2 {
+		t.Errorf("Expected reviewer set to keep 2 reviewers, got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestReassignReviewer_UsesPullRequestTeam(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            "OPEN",
+		AssignedReviewers: []string{"u2"},
+		TeamID:            2,
+		TeamName:          "Data",
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u3"}}
+	requestStorage.TeamCandidates[2] = []models.ReviewerCandidate{{UserID: "u4"}}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(ReassignRequest{PullRequestID: "pr-1", OldReviewerID: "u2"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/reassign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response ReassignResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.ReplacedBy != "u4" {
+		t.Errorf("Expected a reviewer of the pull request's team, got %q", response.ReplacedBy)
+	}
+
+	requestStorage.PullRequests["pr-2"] = models.PullRequest{
+		ID:                "pr-2",
+		AuthorID:          "u1",
+		Status:            "OPEN",
+		AssignedReviewers: []string{"u3"},
+		TeamID:            2,
+	}
+	newReviewerID, err := service.ReassignAbsent(context.Background(), "pr-2", "u3")
+	if err != nil || newReviewerID != "u4" {
+		t.Errorf("Expected 'u4' to take over the absent reviewer, got %q, %v", newReviewerID, err)
 	}
 }
 
@@ -283,11 +473,9 @@ func TestReassignReviewer_PRMerged(t *testing.T) {
 		AssignedReviewers: []string{"u2"},
 	}
 
-	teamStorage.GetReplacementCandidateFunc = func(ctx context.Context, teamID int, excludeUserIDs []string) (string, error) {
-		return "u3", nil
-	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u3"}}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -324,11 +512,9 @@ func TestReassignReviewer_NotAssigned(t *testing.T) {
 		AssignedReviewers: []string{"u2"},
 	}
 
-	teamStorage.GetReplacementCandidateFunc = func(ctx context.Context, teamID int, excludeUserIDs []string) (string, error) {
-		return "u3", nil
-	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u3"}}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -365,11 +551,9 @@ func TestReassignReviewer_NoCandidate(t *testing.T) {
 		AssignedReviewers: []string{"u2"},
 	}
 
-	teamStorage.GetReplacementCandidateFunc = func(ctx context.Context, teamID int, excludeUserIDs []string) (string, error) {
-		return "", errors.New("NO_CANDIDATE")
-	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u1"}, {UserID: "u2"}}
 
-	service := New(requestStorage, teamStorage, userStorage)
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
 	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
 		return 1, nil
 	}
@@ -392,3 +576,1644 @@ func TestReassignReviewer_NoCandidate(t *testing.T) {
 		t.Errorf("Expected status 409, got %d", w.Code)
 	}
 }
+
+func TestReassignOverdue(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            "OPEN",
+		AssignedReviewers: []string{"u2"},
+	}
+	assignment := models.SLAAssignment{PullRequestID: "pr-1", ReviewerID: "u2", SLA: models.ReviewSLA{ResponseHours: 1}}
+	requestStorage.Overdue = []models.SLAAssignment{assignment}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	newReviewerID, err := service.ReassignOverdue(context.Background(), assignment)
+	if err != nil {
+		t.Fatalf("ReassignOverdue() error = %v", err)
+	}
+	if newReviewerID != "u3" {
+		t.Errorf("Expected 'u3' to take over, got %q", newReviewerID)
+	}
+	if len(requestStorage.Events) != 1 || requestStorage.Events[0].Type != models.EventSLABreached {
+		t.Errorf("Expected an SLA breach event, got %+v", requestStorage.Events)
+	}
+
+	// A second replica finds the assignment already handled.
+	if _, err := service.ReassignOverdue(context.Background(), assignment); err == nil || err.Error() != "SLA_NOT_BREACHED" {
+		t.Errorf("Expected SLA_NOT_BREACHED, got %v", err)
+	}
+}
+
+func TestCreatePullRequest_UsesTeamStrategy(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{
+		ID:       1,
+		Name:     "Backend",
+		Settings: models.TeamSettings{ReviewerStrategy: "round_robin", RequiredReviewers: 2, MinReviewers: 1, MaxReviewers: 5},
+	}
+
+	now := time.Now()
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2", LastAssignedAt: sql.NullTime{Time: now, Valid: true}},
+		{UserID: "u3", LastAssignedAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}},
+		{UserID: "u4"},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add feature",
+		AuthorID:        "u1",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	reviewers := response.PR.AssignedReviewers
+	if len(reviewers) != 2 || reviewers[0] != "u4" || reviewers[1] != "u3" {
+		t.Errorf("Expected round robin reviewers [u4 u3], got %v", reviewers)
+	}
+}
+
+func TestCreatePullRequest_TeamRequiredReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	settings := models.DefaultTeamSettings()
+	settings.RequiredReviewers = 3
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u5"},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add feature",
+		AuthorID:        "u1",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.PR.AssignedReviewers) != 3 {
+		t.Errorf("Expected 3 reviewers, got %d", len(response.PR.AssignedReviewers))
+	}
+}
+
+func TestCreatePullRequest_ReviewersCountOverride(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	count := 1
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add feature",
+		AuthorID:        "u1",
+		ReviewersCount:  &count,
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.PR.AssignedReviewers) != 1 {
+		t.Errorf("Expected 1 reviewer, got %d", len(response.PR.AssignedReviewers))
+	}
+}
+
+func TestCreatePullRequest_ReviewersCountOutOfBounds(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	count := 10
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add feature",
+		AuthorID:        "u1",
+		ReviewersCount:  &count,
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if _, exists := requestStorage.PullRequests["pr-1"]; exists {
+		t.Error("Expected PR not to be created")
+	}
+}
+
+func TestCreatePullRequest_FallbackReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2"},
+		{UserID: "f1", Tier: 1},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add feature",
+		AuthorID:        "u1",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.PR.AssignedReviewers) != 2 {
+		t.Errorf("Expected 2 reviewers, got %v", response.PR.AssignedReviewers)
+	}
+	if len(response.PR.FallbackReviewers) != 1 || response.PR.FallbackReviewers[0] != "f1" {
+		t.Errorf("Expected fallback reviewers [f1], got %v", response.PR.FallbackReviewers)
+	}
+}
+
+func TestCreatePullRequest_PrefersCodeOwners(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	codeOwnerStorage := mocks.NewMockCodeOwnerStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Storage"] = models.Team{ID: 2, Name: "Storage", Members: []models.User{{ID: "s1"}}}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2"},
+		{UserID: "u3"},
+		{UserID: "u4", OpenReviews: 9},
+	}
+	codeOwnerStorage.Rules = []models.CodeOwnerRule{
+		{ID: 1, Pattern: "*", Users: []string{"u3"}},
+		{ID: 2, Pattern: "/internals/storage/", Users: []string{"u4"}, Teams: []string{"Storage"}},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, codeOwnerStorage)
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add index",
+		AuthorID:        "u1",
+		ChangedPaths:    []string{"internals/storage/pgsql/teams.go"},
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	reviewers := map[string]bool{}
+	for _, id := range response.PR.AssignedReviewers {
+		reviewers[id] = true
+	}
+	if len(reviewers) != 2 || !reviewers["u4"] || !reviewers["s1"] {
+		t.Errorf("Expected code owners [u4 s1], got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestCreatePullRequest_PrefersMatchingTags(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2"},
+		{UserID: "u3"},
+		{UserID: "u4", OpenReviews: 3},
+	}
+	requestStorage.UserTags["u4"] = []string{"go", "postgres"}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+
+	router := setupRouter(service)
+
+	reqBody := CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add index",
+		AuthorID:        "u1",
+		Labels:          []string{"Postgres"},
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.PR.AssignedReviewers) != 2 || response.PR.AssignedReviewers[0] != "u4" {
+		t.Errorf("Expected u4 to be picked first, got %v", response.PR.AssignedReviewers)
+	}
+	if len(response.PR.Labels) != 1 || response.PR.Labels[0] != "postgres" {
+		t.Errorf("Expected labels [postgres], got %v", response.PR.Labels)
+	}
+}
+
+func TestCreatePullRequest_SameSeedSameReviewers(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u5"}, {UserID: "u6"},
+	}
+
+	create := func(seed string) models.PullRequest {
+		requestStorage := mocks.NewMockRequestStorage()
+		teamStorage := mocks.NewMockTeamStorage()
+		userStorage := mocks.NewMockUserStorage()
+
+		settings := models.DefaultTeamSettings()
+		settings.ReviewerStrategy = selection.StrategyRandom
+		teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+		requestStorage.TeamCandidates[1] = candidates
+
+		service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+		service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+			return 1, nil
+		}
+
+		router := setupRouter(service)
+
+		reqBody := CreatePRRequest{
+			PullRequestID:   "pr-1",
+			PullRequestName: "Add feature",
+			AuthorID:        "u1",
+			Seed:            seed,
+		}
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		var response PRResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.PR
+	}
+
+	tests := []struct {
+		seed     string
+		wantSeed string
+	}{
+		{seed: "", wantSeed: "pr-1"},
+		{seed: "incident-17", wantSeed: "incident-17"},
+	}
+
+	for _, tt := range tests {
+		pr := create(tt.seed)
+		if pr.SelectionSeed != tt.wantSeed {
+			t.Errorf("Expected selection seed %q, got %q", tt.wantSeed, pr.SelectionSeed)
+		}
+
+		want := selection.RandomSelector{}.Select(selection.NewRand(tt.wantSeed), candidates, 2)
+		if len(pr.AssignedReviewers) != 2 || pr.AssignedReviewers[0] != want[0] || pr.AssignedReviewers[1] != want[1] {
+			t.Errorf("Expected reviewers %v for seed %q, got %v", want, tt.wantSeed, pr.AssignedReviewers)
+		}
+	}
+}
+
+func TestCreatePullRequest_ReviewCapacity(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams[team.Name] = team
+	teamStorage.TeamsByID[team.ID] = team
+	// u2 and u4 have as many open reviews as they may take.
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u2", OpenReviews: 3, ReviewLimit: 3},
+		{UserID: "u3", OpenReviews: 5},
+		{UserID: "u4", OpenReviews: 2, ReviewLimit: 2},
+	}
+	userStorage.Users["u2"] = models.User{ID: "u2", IsActive: true}
+	userStorage.Capacities["u2"] = models.ReviewCapacity{Limit: 3, OpenReviews: 3}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	post := func(path string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("create", CreatePRRequest{
+		PullRequestID:      "pr-1",
+		PullRequestName:    "Add feature",
+		AuthorID:           "u1",
+		PreferredReviewers: []string{"u2"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var created CreatePRResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if strings.Join(created.PR.AssignedReviewers, ",") != "u3" || created.PR.MissingReviewers != 1 {
+		t.Errorf("Expected u3 with one missing reviewer, got %v and %d", created.PR.AssignedReviewers, created.PR.MissingReviewers)
+	}
+	if len(created.UnhonoredPreferences) != 1 || created.UnhonoredPreferences[0].Reason != ReasonAtCapacity {
+		t.Errorf("Expected u2 to be unhonored as AT_CAPACITY, got %v", created.UnhonoredPreferences)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/list?understaffed=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list ListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.PullRequests) != 1 || list.PullRequests[0].ID != "pr-1" {
+		t.Errorf("Expected pr-1 to be listed as understaffed, got %s", w.Body.String())
+	}
+
+	// Capacity limits automatic selection only.
+	w = post("addReviewer", ReviewerChangeRequest{PullRequestID: "pr-1", ReviewerID: "u4", PerformedBy: "lead"})
+	var added PRResponse
+	json.Unmarshal(w.Body.Bytes(), &added)
+	if w.Code != http.StatusOK || added.PR.MissingReviewers != 0 {
+		t.Errorf("Expected the added reviewer to fill the slot, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetPullRequest_Success(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add feature",
+		AuthorID:          "u1",
+		Status:            "OPEN",
+		AssignedReviewers: []string{"u2"},
+	}
+
+	service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=pr-1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.ID != "pr-1" || len(response.PR.AssignedReviewers) != 1 {
+		t.Errorf("Expected pr-1 with one reviewer, got %+v", response.PR)
+	}
+}
+
+func TestGetPullRequest_NotFound(t *testing.T) {
+	service := New(mocks.NewMockRequestStorage(), mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=ghost", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestListPullRequests_Paginates(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		requestStorage.PullRequests[id] = models.PullRequest{ID: id, AuthorID: "u1", Status: "OPEN"}
+	}
+
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	var seen []string
+	url := "/api/v1/pullRequest/list?sort_by=pull_request_id&order=asc&limit=2"
+	for page := 0; page < 3; page++ {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+
+		var response ListResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		for _, pr := range response.PullRequests {
+			seen = append(seen, pr.ID)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		url = "/api/v1/pullRequest/list?sort_by=pull_request_id&order=asc&limit=2&cursor=" + response.NextCursor
+	}
+
+	if len(seen) != 3 || seen[0] != "pr-1" || seen[2] != "pr-3" {
+		t.Errorf("Expected [pr-1 pr-2 pr-3], got %v", seen)
+	}
+}
+
+func TestListPullRequests_PassesFilter(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.Teams["Backend"] = models.Team{ID: 3, Name: "Backend"}
+
+	var got models.PullRequestFilter
+	requestStorage.ListPullRequestsFunc = func(ctx context.Context, filter models.PullRequestFilter) (models.PullRequestPage, error) {
+		got = filter
+		return models.PullRequestPage{}, nil
+	}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/list?status=MERGED&reviewer_id=u2&team_name=Backend&merged_from=2025-11-01T00:00:00Z&sort_by=merged_at", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if got.Status != "MERGED" || got.ReviewerID != "u2" || got.TeamID != 3 || !got.MergedFrom.Valid {
+		t.Errorf("Unexpected filter %+v", got)
+	}
+	if got.SortBy != models.SortByMergedAt || !got.Descending || got.Limit != 20 {
+		t.Errorf("Expected merged_at desc with default limit, got %+v", got)
+	}
+	if !strings.Contains(w.Body.String(), `"pull_requests":[]`) {
+		t.Errorf("Expected empty pull_requests array, got %s", w.Body.String())
+	}
+}
+
+func TestListPullRequests_InvalidParams(t *testing.T) {
+	service := New(mocks.NewMockRequestStorage(), mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	for _, query := range []string{
+		"?status=PENDING",
+		"?sort_by=author",
+		"?order=up",
+		"?limit=0",
+		"?limit=1000",
+		"?created_from=yesterday",
+		"?cursor=not-a-cursor",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/list"+query, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestCreatePullRequest_DraftHasNoReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "WIP",
+		AuthorID:        "u1",
+		Draft:           true,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.Status != models.StatusDraft {
+		t.Errorf("Expected status DRAFT, got %s", response.PR.Status)
+	}
+	if len(response.PR.AssignedReviewers) != 0 {
+		t.Errorf("Expected no reviewers on a draft, got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestReadyPullRequest_AssignsReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "WIP",
+		AuthorID:          "u1",
+		Status:            models.StatusDraft,
+		AssignedReviewers: []string{},
+	}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(TransitionRequest{PullRequestID: "pr-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/ready", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.Status != models.StatusOpen {
+		t.Errorf("Expected status OPEN, got %s", response.PR.Status)
+	}
+	if len(response.PR.AssignedReviewers) != 2 {
+		t.Fatalf("Expected 2 reviewers, got %v", response.PR.AssignedReviewers)
+	}
+	for _, reviewerID := range response.PR.AssignedReviewers {
+		if reviewerID == "u1" {
+			t.Error("Author must not be assigned as reviewer")
+		}
+	}
+}
+
+func TestReopenPullRequest_KeepsReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u3"}}
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            models.StatusClosed,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		t.Error("Reopening a PR with reviewers must not select new ones")
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(TransitionRequest{PullRequestID: "pr-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/reopen", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.Status != models.StatusOpen {
+		t.Errorf("Expected status OPEN, got %s", response.PR.Status)
+	}
+	if len(response.PR.AssignedReviewers) != 1 || response.PR.AssignedReviewers[0] != "u2" {
+		t.Errorf("Expected reviewers [u2], got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestReopenPullRequest_ReviewersRemovedMeanwhile(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u1"}, {UserID: "u3"}}
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            models.StatusClosed,
+		AssignedReviewers: []string{},
+	}
+
+	// The first read still sees the reviewer the author transfer removed.
+	reads := 0
+	requestStorage.GetPullRequestFunc = func(ctx context.Context, pullRequestID string) (models.PullRequest, error) {
+		reads++
+		pr := requestStorage.PullRequests[pullRequestID]
+		if reads == 1 {
+			pr.AssignedReviewers = []string{"u2"}
+		}
+		return pr, nil
+	}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(TransitionRequest{PullRequestID: "pr-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/reopen", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.Status != models.StatusOpen {
+		t.Errorf("Expected status OPEN, got %s", response.PR.Status)
+	}
+	if len(response.PR.AssignedReviewers) != 1 || response.PR.AssignedReviewers[0] != "u3" {
+		t.Errorf("Expected reviewers [u3], got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestPullRequestTransitions_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		endpoint string
+		code     string
+	}{
+		{"ready open", models.StatusOpen, "ready", "PR_OPEN"},
+		{"ready closed", models.StatusClosed, "ready", "PR_CLOSED"},
+		{"reopen draft", models.StatusDraft, "reopen", "PR_DRAFT"},
+		{"reopen merged", models.StatusMerged, "reopen", "PR_MERGED"},
+		{"close closed", models.StatusClosed, "close", "PR_CLOSED"},
+		{"close merged", models.StatusMerged, "close", "PR_MERGED"},
+		{"merge draft", models.StatusDraft, "merge", "PR_DRAFT"},
+		{"merge closed", models.StatusClosed, "merge", "PR_CLOSED"},
+		{"reassign closed", models.StatusClosed, "reassign", "PR_CLOSED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{
+				ID:                "pr-1",
+				Name:              "Test PR",
+				AuthorID:          "u1",
+				Status:            tt.status,
+				AssignedReviewers: []string{"u2"},
+			}
+
+			service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+				return 1, nil
+			}
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(ReassignRequest{PullRequestID: "pr-1", OldReviewerID: "u2"})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/"+tt.endpoint, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusConflict {
+				t.Fatalf("Expected status 409, got %d. Body: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.code) {
+				t.Errorf("Expected error %s, got %s", tt.code, w.Body.String())
+			}
+			if requestStorage.PullRequests["pr-1"].Status != tt.status {
+				t.Errorf("Expected status to stay %s, got %s", tt.status, requestStorage.PullRequests["pr-1"].Status)
+			}
+		})
+	}
+}
+
+func TestReviewPullRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		reviewerID string
+		decision   string
+		wantCode   int
+	}{
+		{"approve", "u2", models.DecisionApprove, http.StatusCreated},
+		{"comment", "u2", models.DecisionComment, http.StatusCreated},
+		{"not assigned", "u3", models.DecisionApprove, http.StatusConflict},
+		{"unknown decision", "u2", "LGTM", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{
+				ID:                "pr-1",
+				AuthorID:          "u1",
+				Status:            models.StatusOpen,
+				AssignedReviewers: []string{"u2"},
+			}
+
+			service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(ReviewRequest{PullRequestID: "pr-1", ReviewerID: tt.reviewerID, Decision: tt.decision})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/review", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_RequiresApprovals(t *testing.T) {
+	review := func(reviewerID, decision string) models.Review {
+		return models.Review{PullRequestID: "pr-1", ReviewerID: reviewerID, Decision: decision}
+	}
+
+	tests := []struct {
+		name     string
+		reviews  []models.Review
+		wantCode int
+	}{
+		{"no reviews", nil, http.StatusConflict},
+		{"one approval", []models.Review{review("u2", models.DecisionApprove)}, http.StatusConflict},
+		{"two approvals", []models.Review{
+			review("u2", models.DecisionApprove),
+			review("u3", models.DecisionApprove),
+		}, http.StatusOK},
+		{"approval of unassigned reviewer", []models.Review{
+			review("u2", models.DecisionApprove),
+			review("u4", models.DecisionApprove),
+		}, http.StatusConflict},
+		{"outstanding change request", []models.Review{
+			review("u2", models.DecisionApprove),
+			review("u3", models.DecisionApprove),
+			review("u2", models.DecisionRequestChanges),
+			review("u2", models.DecisionComment),
+		}, http.StatusConflict},
+		{"change request resolved", []models.Review{
+			review("u2", models.DecisionRequestChanges),
+			review("u2", models.DecisionApprove),
+			review("u3", models.DecisionApprove),
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{
+				ID:                "pr-1",
+				AuthorID:          "u1",
+				Status:            models.StatusOpen,
+				AssignedReviewers: []string{"u2", "u3"},
+			}
+			requestStorage.Reviews = tt.reviews
+
+			settings := models.DefaultTeamSettings()
+			settings.MergePolicy.MinApprovals = 2
+			teamStorage := mocks.NewMockTeamStorage()
+			teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+
+			service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+				return 1, nil
+			}
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(MergePRRequest{PullRequestID: "pr-1"})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/merge", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode == http.StatusConflict && !strings.Contains(w.Body.String(), "NOT_APPROVED") {
+				t.Errorf("Expected NOT_APPROVED, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_RemovedReviewerClearsChangeRequest(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	requestStorage.Reviews = []models.Review{
+		{PullRequestID: "pr-1", ReviewerID: "u2", Decision: models.DecisionApprove},
+		{PullRequestID: "pr-1", ReviewerID: "u3", Decision: models.DecisionRequestChanges},
+	}
+
+	settings := models.DefaultTeamSettings()
+	settings.MergePolicy.MinApprovals = 1
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	merge := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(MergePRRequest{PullRequestID: "pr-1"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/merge", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := merge(); w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409 while u3 requests changes, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	body, _ := json.Marshal(ReviewerChangeRequest{PullRequestID: "pr-1", ReviewerID: "u3", PerformedBy: "lead"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/removeReviewer", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 removing u3, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	if w := merge(); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 once u3 is removed, got %d. Body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetMergeability(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	requestStorage.InactiveUsers["u3"] = true
+	requestStorage.Reviews = []models.Review{
+		{PullRequestID: "pr-1", ReviewerID: "u2", Decision: models.DecisionApprove},
+	}
+
+	settings := models.DefaultTeamSettings()
+	settings.MergePolicy = models.MergePolicy{MinApprovals: 1, BlockInactiveReviewers: true}
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/mergeability?pull_request_id=pr-1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response MergeabilityResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Mergeable {
+		t.Error("Expected PR with an inactive reviewer not to be mergeable")
+	}
+	passed := make(map[string]bool)
+	for _, rule := range response.Rules {
+		passed[rule.Rule] = rule.Passed
+	}
+	if !passed["min_approvals"] || passed["active_reviewers"] {
+		t.Errorf("Expected min_approvals to pass and active_reviewers to fail, got %+v", response.Rules)
+	}
+}
+
+func TestAddReviewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		reviewerID string
+		wantCode   int
+		wantError  string
+	}{
+		{"success", models.StatusOpen, "u3", http.StatusOK, ""},
+		{"author", models.StatusOpen, "u1", http.StatusConflict, "REVIEWER_IS_AUTHOR"},
+		{"already assigned", models.StatusOpen, "u2", http.StatusConflict, "ALREADY_ASSIGNED"},
+		{"inactive", models.StatusOpen, "u4", http.StatusConflict, "REVIEWER_INACTIVE"},
+		{"absent", models.StatusOpen, "u5", http.StatusConflict, "REVIEWER_UNAVAILABLE"},
+		{"other team", models.StatusOpen, "u9", http.StatusConflict, "NOT_TEAM_MEMBER"},
+		{"merged", models.StatusMerged, "u3", http.StatusConflict, "PR_MERGED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{
+				ID:                "pr-1",
+				AuthorID:          "u1",
+				Status:            tt.status,
+				AssignedReviewers: []string{"u2"},
+			}
+			requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}}
+			requestStorage.InactiveUsers["u4"] = true
+			requestStorage.AbsentUsers["u5"] = true
+
+			service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+				return 1, nil
+			}
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(ReviewerChangeRequest{PullRequestID: "pr-1", ReviewerID: tt.reviewerID, PerformedBy: "lead"})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/addReviewer", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(w.Body.String(), tt.wantError) {
+					t.Errorf("Expected error %s, got %s", tt.wantError, w.Body.String())
+				}
+				if len(requestStorage.ReviewerChanges) != 0 {
+					t.Errorf("Expected no recorded changes, got %v", requestStorage.ReviewerChanges)
+				}
+				return
+			}
+
+			var resp ReviewerChangeResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.PR.AssignedReviewers) != 2 || resp.PR.AssignedReviewers[1] != "u3" {
+				t.Errorf("Expected reviewers [u2 u3], got %v", resp.PR.AssignedReviewers)
+			}
+			if resp.Change.Action != models.ReviewerAdded || resp.Change.PerformedBy != "lead" {
+				t.Errorf("Unexpected change %+v", resp.Change)
+			}
+		})
+	}
+}
+
+func TestRemoveReviewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		reviewerID string
+		wantCode   int
+		wantError  string
+	}{
+		{"success", models.StatusOpen, "u2", http.StatusOK, ""},
+		{"not assigned", models.StatusOpen, "u5", http.StatusConflict, "NOT_ASSIGNED"},
+		{"closed", models.StatusClosed, "u2", http.StatusConflict, "PR_CLOSED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{
+				ID:                "pr-1",
+				AuthorID:          "u1",
+				Status:            tt.status,
+				AssignedReviewers: []string{"u2", "u3"},
+			}
+
+			service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(ReviewerChangeRequest{PullRequestID: "pr-1", ReviewerID: tt.reviewerID, PerformedBy: "lead"})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/removeReviewer", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(w.Body.String(), tt.wantError) {
+					t.Errorf("Expected error %s, got %s", tt.wantError, w.Body.String())
+				}
+				return
+			}
+
+			reviewers := requestStorage.PullRequests["pr-1"].AssignedReviewers
+			if len(reviewers) != 1 || reviewers[0] != "u3" {
+				t.Errorf("Expected reviewers [u3], got %v", reviewers)
+			}
+			if len(requestStorage.ReviewerChanges) != 1 || requestStorage.ReviewerChanges[0].Action != models.ReviewerRemoved {
+				t.Errorf("Expected one REMOVE change, got %v", requestStorage.ReviewerChanges)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest_ReviewerPreferences(t *testing.T) {
+	tests := []struct {
+		name          string
+		count         int
+		preferred     []string
+		excluded      []string
+		wantReviewers []string
+		wantUnhonored []UnhonoredPreference
+	}{
+		{
+			name:          "preferred first",
+			count:         2,
+			preferred:     []string{"u5"},
+			excluded:      []string{"u2", "u3"},
+			wantReviewers: []string{"u5", "u4"},
+		},
+		{
+			name:          "excluded never chosen",
+			count:         2,
+			excluded:      []string{"u2", "u3", "u4"},
+			wantReviewers: []string{"u5"},
+		},
+		{
+			name:          "unhonored reasons",
+			count:         1,
+			preferred:     []string{"u1", "u3", "u6", "u7", "u8", "ghost", "u4", "u5"},
+			excluded:      []string{"u3"},
+			wantReviewers: []string{"u4"},
+			wantUnhonored: []UnhonoredPreference{
+				{UserID: "u1", Reason: ReasonIsAuthor},
+				{UserID: "u3", Reason: ReasonExcluded},
+				{UserID: "u6", Reason: ReasonInactive},
+				{UserID: "u7", Reason: ReasonNotTeamMember},
+				{UserID: "u8", Reason: ReasonUnavailable},
+				{UserID: "ghost", Reason: ReasonNotFound},
+				{UserID: "u5", Reason: ReasonNoSlot},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+				{UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u5"},
+			}
+			teamStorage := mocks.NewMockTeamStorage()
+			teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+			userStorage := mocks.NewMockUserStorage()
+			userStorage.Users["u6"] = models.User{ID: "u6", IsActive: false}
+			userStorage.Users["u7"] = models.User{ID: "u7", IsActive: true}
+			userStorage.Users["u8"] = models.User{ID: "u8", IsActive: true}
+			userStorage.Absences["u8"] = []models.Absence{{UserID: "u8", StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour)}}
+
+			service := New(requestStorage, teamStorage, userStorage, mocks.NewMockCodeOwnerStorage())
+			service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+				return 1, nil
+			}
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(CreatePRRequest{
+				PullRequestID:      "pr-1",
+				PullRequestName:    "Add feature",
+				AuthorID:           "u1",
+				ReviewersCount:     &tt.count,
+				PreferredReviewers: tt.preferred,
+				ExcludedReviewers:  tt.excluded,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+			}
+
+			var response CreatePRResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			if strings.Join(response.PR.AssignedReviewers, ",") != strings.Join(tt.wantReviewers, ",") {
+				t.Errorf("Expected reviewers %v, got %v", tt.wantReviewers, response.PR.AssignedReviewers)
+			}
+			if len(response.UnhonoredPreferences) != len(tt.wantUnhonored) {
+				t.Fatalf("Expected unhonored %v, got %v", tt.wantUnhonored, response.UnhonoredPreferences)
+			}
+			for i, want := range tt.wantUnhonored {
+				if response.UnhonoredPreferences[i] != want {
+					t.Errorf("Expected unhonored %v, got %v", want, response.UnhonoredPreferences[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadyPullRequest_AppliesStoredPreferences(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	settings := models.DefaultTeamSettings()
+	settings.RequiredReviewers = 1
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: settings}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(CreatePRRequest{
+		PullRequestID:      "pr-1",
+		PullRequestName:    "WIP",
+		AuthorID:           "u1",
+		Draft:              true,
+		PreferredReviewers: []string{"u4"},
+		ExcludedReviewers:  []string{"u2"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	stored := requestStorage.PullRequests["pr-1"]
+	if !slices.Equal(stored.PreferredReviewers, []string{"u4"}) || !slices.Equal(stored.ExcludedReviewers, []string{"u2"}) {
+		t.Fatalf("Expected preferences to be stored, got preferred %v and excluded %v", stored.PreferredReviewers, stored.ExcludedReviewers)
+	}
+
+	body, _ = json.Marshal(TransitionRequest{PullRequestID: "pr-1"})
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/ready", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response PRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if !slices.Equal(response.PR.AssignedReviewers, []string{"u4"}) {
+		t.Errorf("Expected the preferred reviewer u4, got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestReassignReviewer_SkipsExcludedReviewers(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2"},
+		ExcludedReviewers: []string{"u3"},
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{{UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}}
+
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(ReassignRequest{PullRequestID: "pr-1", OldReviewerID: "u2"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/reassign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response ReassignResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ReplacedBy != "u4" {
+		t.Errorf("Expected the excluded u3 to be passed over for u4, got %q", response.ReplacedBy)
+	}
+}
+
+func TestCreatePullRequest_DraftRejectsSelectionInput(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	count := 2
+	tests := []struct {
+		name string
+		req  CreatePRRequest
+	}{
+		{"reviewers_count", CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "WIP", AuthorID: "u1", Draft: true, ReviewersCount: &count}},
+		{"changed_paths", CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "WIP", AuthorID: "u1", Draft: true, ChangedPaths: []string{"api/handler.go"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d. Body: %s", w.Code, w.Body.String())
+			}
+			if _, ok := requestStorage.PullRequests["pr-1"]; ok {
+				t.Error("Expected the draft not to be created")
+			}
+		})
+	}
+}
+
+func TestUpdatePullRequest_Rename(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Old name",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	name := "New name"
+	body, _ := json.Marshal(UpdatePRRequest{PullRequestID: "pr-1", PullRequestName: &name, PerformedBy: "u1"})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/pullRequest/update", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response UpdatePRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.PR.Name != "New name" {
+		t.Errorf("Expected name 'New name', got %s", response.PR.Name)
+	}
+	if len(response.Changes) != 1 || response.Changes[0].Field != models.FieldName || response.Changes[0].OldValue != "Old name" {
+		t.Errorf("Expected one name change, got %+v", response.Changes)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/history?pull_request_id=pr-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var history HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history.Changes) != 1 || history.Changes[0].ChangedBy != "u1" {
+		t.Errorf("Expected history with one change by u1, got %s", w.Body.String())
+	}
+}
+
+func TestUpdatePullRequest_TransferToReviewer(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"},
+	}
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	author := "u2"
+	body, _ := json.Marshal(UpdatePRRequest{PullRequestID: "pr-1", AuthorID: &author, PerformedBy: "lead"})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/pullRequest/update", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response UpdatePRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.PR.AuthorID != "u2" || response.RemovedReviewer != "u2" {
+		t.Errorf("Expected u2 to become author and leave reviewers, got %s", w.Body.String())
+	}
+	if response.ReplacedBy != "u1" && response.ReplacedBy != "u4" {
+		t.Errorf("Expected replacement from u1 or u4, got %q", response.ReplacedBy)
+	}
+	for _, reviewerID := range response.PR.AssignedReviewers {
+		if reviewerID == "u2" {
+			t.Errorf("New author still reviews the PR: %v", response.PR.AssignedReviewers)
+		}
+	}
+	if len(response.PR.AssignedReviewers) != 2 {
+		t.Errorf("Expected 2 reviewers, got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestUpdatePullRequest_TransferToReviewerOfClosedPR(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		Status:            models.StatusClosed,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	requestStorage.TeamCandidates[1] = []models.ReviewerCandidate{
+		{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"},
+	}
+	teamStorage := mocks.NewMockTeamStorage()
+	teamStorage.TeamsByID[1] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	service := New(requestStorage, teamStorage, mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+		return 1, nil
+	}
+	router := setupRouter(service)
+
+	author := "u2"
+	body, _ := json.Marshal(UpdatePRRequest{PullRequestID: "pr-1", AuthorID: &author, PerformedBy: "lead"})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/pullRequest/update", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response UpdatePRResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.RemovedReviewer != "u2" || response.ReplacedBy != "" {
+		t.Errorf("Expected u2 to leave reviewers without a replacement, got %s", w.Body.String())
+	}
+	if len(response.PR.AssignedReviewers) != 1 || response.PR.AssignedReviewers[0] != "u3" {
+		t.Errorf("Expected reviewers [u3], got %v", response.PR.AssignedReviewers)
+	}
+	if response.PR.MissingReviewers != 0 {
+		t.Errorf("Expected no missing reviewers on a closed PR, got %d", response.PR.MissingReviewers)
+	}
+}
+
+func TestUpdatePullRequest_Invalid(t *testing.T) {
+	name := "New name"
+	empty := ""
+	tests := []struct {
+		name     string
+		status   string
+		body     UpdatePRRequest
+		wantCode int
+	}{
+		{"no fields", models.StatusOpen, UpdatePRRequest{PullRequestID: "pr-1", PerformedBy: "u1"}, http.StatusBadRequest},
+		{"empty name", models.StatusOpen, UpdatePRRequest{PullRequestID: "pr-1", PullRequestName: &empty, PerformedBy: "u1"}, http.StatusBadRequest},
+		{"no performer", models.StatusOpen, UpdatePRRequest{PullRequestID: "pr-1", PullRequestName: &name}, http.StatusBadRequest},
+		{"unknown PR", models.StatusOpen, UpdatePRRequest{PullRequestID: "pr-2", PullRequestName: &name, PerformedBy: "u1"}, http.StatusNotFound},
+		{"merged", models.StatusMerged, UpdatePRRequest{PullRequestID: "pr-1", PullRequestName: &name, PerformedBy: "u1"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestStorage := mocks.NewMockRequestStorage()
+			requestStorage.PullRequests["pr-1"] = models.PullRequest{ID: "pr-1", Name: "Old", AuthorID: "u1", Status: tt.status}
+
+			service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+			service.GetAuthorTeamIDFn = func(ctx context.Context, userID string) (int, error) {
+				return 1, nil
+			}
+			router := setupRouter(service)
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/pullRequest/update", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if len(requestStorage.Changes) != 0 {
+				t.Errorf("Expected no changes, got %v", requestStorage.Changes)
+			}
+		})
+	}
+}
+
+func TestGetTimeline(t *testing.T) {
+	requestStorage := mocks.NewMockRequestStorage()
+	requestStorage.PullRequests["pr-1"] = models.PullRequest{ID: "pr-1", AuthorID: "u1", Status: models.StatusOpen}
+	requestStorage.Events = []models.PullRequestEvent{
+		{ID: 1, PullRequestID: "pr-1", Type: models.EventCreated, ActorID: "u1", ToStatus: models.StatusOpen},
+		{ID: 2, PullRequestID: "pr-2", Type: models.EventCreated, ActorID: "u3"},
+		{ID: 3, PullRequestID: "pr-1", Type: models.EventReviewerReassigned, ReviewerID: "u3", OldReviewerID: "u2", Reason: models.EventReasonReassign},
+	}
+
+	service := New(requestStorage, mocks.NewMockTeamStorage(), mocks.NewMockUserStorage(), mocks.NewMockCodeOwnerStorage())
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/timeline?pull_request_id=pr-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response TimelineResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Events) != 2 || response.Events[1].OldReviewerID != "u2" {
+		t.Errorf("Expected 2 events of pr-1, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/timeline?pull_request_id=pr-9", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
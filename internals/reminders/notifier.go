@@ -0,0 +1,80 @@
+package reminders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Notifier delivers reminders to reviewers. Implementations for chats or
+// mail can be plugged into the Scheduler alongside the built-in ones.
+type Notifier interface {
+	Notify(ctx context.Context, reminder models.ReviewReminder) error
+}
+
+// Built-in notifiers selectable with NewNotifier.
+const (
+	NotifierLog  = "log"
+	NotifierFile = "file"
+)
+
+// NewNotifier returns a built-in notifier by name. path is used by the file
+// notifier only.
+func NewNotifier(kind, path string) (Notifier, error) {
+	switch kind {
+	case NotifierLog, "":
+		return &LogNotifier{Logger: slog.Default()}, nil
+	case NotifierFile:
+		if path == "" {
+			return nil, fmt.Errorf("file notifier needs a path")
+		}
+		return &FileNotifier{Path: path}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
+
+// LogNotifier writes reminders to the service log.
+type LogNotifier struct {
+	Logger *slog.Logger
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, reminder models.ReviewReminder) error {
+	n.Logger.InfoContext(ctx, reminder.Message,
+		"reviewerID", reminder.ReviewerID,
+		"pendingCount", reminder.PendingCount,
+		"prIDs", reminder.PullRequestIDs,
+	)
+	return nil
+}
+
+// FileNotifier appends reminders to Path, one JSON object per line.
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, reminder models.ReviewReminder) error {
+	line, err := json.Marshal(reminder)
+	if err != nil {
+		return fmt.Errorf("encode reminder: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open reminders file: %w", err)
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write reminder: %w", err)
+	}
+	return f.Close()
+}
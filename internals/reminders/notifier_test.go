@@ -0,0 +1,58 @@
+package reminders
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func TestFileNotifier_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.log")
+	notifier, err := NewNotifier(NotifierFile, path)
+	if err != nil {
+		t.Fatalf("NewNotifier() error = %v", err)
+	}
+
+	for _, reviewerID := range []string{"u2", "u3"} {
+		err := notifier.Notify(context.Background(), models.ReviewReminder{ReviewerID: reviewerID, PendingCount: 1})
+		if err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open reminders file: %v", err)
+	}
+	defer f.Close()
+
+	var reviewerIDs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var reminder models.ReviewReminder
+		if err := json.Unmarshal(scanner.Bytes(), &reminder); err != nil {
+			t.Fatalf("line %q is not a reminder: %v", scanner.Text(), err)
+		}
+		reviewerIDs = append(reviewerIDs, reminder.ReviewerID)
+	}
+	if len(reviewerIDs) != 2 || reviewerIDs[0] != "u2" || reviewerIDs[1] != "u3" {
+		t.Errorf("Expected reminders for u2 and u3, got %v", reviewerIDs)
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if _, err := NewNotifier(NotifierLog, ""); err != nil {
+		t.Errorf("log notifier: %v", err)
+	}
+	if _, err := NewNotifier(NotifierFile, ""); err == nil {
+		t.Error("Expected an error for a file notifier without a path")
+	}
+	if _, err := NewNotifier("pigeon", ""); err == nil {
+		t.Error("Expected an error for an unknown notifier")
+	}
+}
@@ -0,0 +1,98 @@
+// Package reminders periodically reminds reviewers about reviews they have
+// left pending for too long.
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Scheduler sends every reviewer with stale reviews one reminder per
+// RepeatAfter. Reminders are claimed in the storage before delivery, so
+// restarts and other replicas do not repeat them.
+type Scheduler struct {
+	Storage  storage.ReminderStorage
+	Notifier Notifier
+	Interval time.Duration
+	Policy   models.ReminderPolicy
+}
+
+func NewScheduler(storage storage.ReminderStorage, notifier Notifier, interval time.Duration, policy models.ReminderPolicy) *Scheduler {
+	return &Scheduler{
+		Storage:  storage,
+		Notifier: notifier,
+		Interval: interval,
+		Policy:   policy,
+	}
+}
+
+// Run sends reminders every Interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	slog.Info("Reminder scheduler started", "interval", s.Interval, "staleAfter", s.Policy.StaleAfter)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Reminder scheduler stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.RunOnce(ctx); err != nil {
+				slog.Error("Sending reminders failed", "err", err)
+			}
+		}
+	}
+}
+
+// RunOnce sends the reminders due now and returns how many were delivered.
+// An undelivered reminder is released, so the next run retries it.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	reminders, err := s.Storage.ClaimReminders(ctx, s.Policy)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		reminder.Message = Message(reminder.PendingCount, s.Policy.StaleAfter)
+
+		if err := s.Notifier.Notify(ctx, reminder); err != nil {
+			slog.Error("Failed to deliver reminder", "reviewerID", reminder.ReviewerID, "err", err)
+			if err := s.Storage.ReleaseReminder(ctx, reminder); err != nil {
+				slog.Error("Failed to release reminder", "reviewerID", reminder.ReviewerID, "err", err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Message is the reminder text for count reviews pending longer than age.
+func Message(count int, age time.Duration) string {
+	noun := "reviews"
+	if count == 1 {
+		noun = "review"
+	}
+	return fmt.Sprintf("you have %d pending %s older than %s", count, noun, formatAge(age))
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour && age%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", age/(24*time.Hour))
+	case age >= time.Hour && age%time.Hour == 0:
+		return fmt.Sprintf("%dh", age/time.Hour)
+	case age >= time.Minute && age%time.Minute == 0:
+		return fmt.Sprintf("%dm", age/time.Minute)
+	default:
+		return age.String()
+	}
+}
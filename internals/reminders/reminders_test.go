@@ -0,0 +1,84 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type recordingNotifier struct {
+	sent []models.ReviewReminder
+	fail map[string]bool
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, reminder models.ReviewReminder) error {
+	if n.fail[reminder.ReviewerID] {
+		return errors.New("delivery failed")
+	}
+	n.sent = append(n.sent, reminder)
+	return nil
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	storage := mocks.NewMockReminderStorage()
+	storage.Pending = []models.ReviewReminder{
+		{ReviewerID: "u2", PendingCount: 2, PullRequestIDs: []string{"pr-1", "pr-2"}},
+		{ReviewerID: "u3", PendingCount: 1, PullRequestIDs: []string{"pr-3"}},
+	}
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(storage, notifier, time.Hour, models.ReminderPolicy{StaleAfter: 24 * time.Hour, RepeatAfter: 24 * time.Hour})
+
+	sent, err := scheduler.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if sent != 2 {
+		t.Fatalf("sent = %d, want 2", sent)
+	}
+	if got := notifier.sent[0].Message; got != "you have 2 pending reviews older than 1d" {
+		t.Errorf("Message = %q", got)
+	}
+
+	if sent, _ := scheduler.RunOnce(context.Background()); sent != 0 {
+		t.Errorf("Expected no repeated reminders, sent %d", sent)
+	}
+}
+
+func TestScheduler_RunOnceReleasesUndelivered(t *testing.T) {
+	storage := mocks.NewMockReminderStorage()
+	storage.Pending = []models.ReviewReminder{{ReviewerID: "u2", PendingCount: 1}}
+	notifier := &recordingNotifier{fail: map[string]bool{"u2": true}}
+	scheduler := NewScheduler(storage, notifier, time.Hour, models.ReminderPolicy{StaleAfter: time.Hour})
+
+	if sent, err := scheduler.RunOnce(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("RunOnce() = %d, %v; want 0, nil", sent, err)
+	}
+
+	notifier.fail = nil
+	if sent, _ := scheduler.RunOnce(context.Background()); sent != 1 {
+		t.Errorf("Expected the undelivered reminder to be retried, sent %d", sent)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		count int
+		age   time.Duration
+		want  string
+	}{
+		{1, 4 * time.Hour, "you have 1 pending review older than 4h"},
+		{3, 48 * time.Hour, "you have 3 pending reviews older than 2d"},
+		{2, 30 * time.Minute, "you have 2 pending reviews older than 30m"},
+		{2, 90 * time.Second, "you have 2 pending reviews older than 1m30s"},
+	}
+
+	for _, tt := range tests {
+		if got := Message(tt.count, tt.age); got != tt.want {
+			t.Errorf("Message(%d, %v) = %q, want %q", tt.count, tt.age, got, tt.want)
+		}
+	}
+}
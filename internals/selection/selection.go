@@ -0,0 +1,189 @@
+package selection
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+const (
+	StrategyRandom         = "random"
+	StrategyRoundRobin     = "round_robin"
+	StrategyLeastLoaded    = "least_loaded"
+	StrategyWeightedRandom = "weighted_random"
+
+	DefaultStrategy = StrategyLeastLoaded
+)
+
+// ReviewerSelector picks up to count reviewers out of the given candidates.
+// All randomness comes from rng, so a selector is deterministic for a given
+// source and candidate order.
+type ReviewerSelector interface {
+	Select(rng *rand.Rand, candidates []models.ReviewerCandidate, count int) []string
+}
+
+var selectors = map[string]ReviewerSelector{
+	StrategyRandom:         RandomSelector{},
+	StrategyRoundRobin:     RoundRobinSelector{},
+	StrategyLeastLoaded:    LeastLoadedSelector{},
+	StrategyWeightedRandom: WeightedRandomSelector{},
+}
+
+// Get returns the selector registered for strategy. An empty strategy means
+// DefaultStrategy.
+func Get(strategy string) (ReviewerSelector, error) {
+	if strategy == "" {
+		strategy = DefaultStrategy
+	}
+
+	selector, ok := selectors[strategy]
+	if !ok {
+		return nil, errors.New("UNKNOWN_STRATEGY")
+	}
+	return selector, nil
+}
+
+// NewRand returns a source seeded from seed. The same seed against the same
+// candidates always yields the same reviewers.
+func NewRand(seed string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	sum := h.Sum64()
+	return rand.New(rand.NewPCG(sum, sum^0x9e3779b97f4a7c15))
+}
+
+func IsKnown(strategy string) bool {
+	_, ok := selectors[strategy]
+	return ok
+}
+
+// SelectWithFallback fills count slots from the author's team first and tops
+// them up from fallback teams in their configured order, applying selector
+// within every tier. Inside a tier candidates whose tags match the pull
+// request labels go before the rest.
+func SelectWithFallback(selector ReviewerSelector, rng *rand.Rand, candidates []models.ReviewerCandidate, count int) []string {
+	type group struct {
+		tier      int
+		unmatched bool
+	}
+
+	byGroup := make(map[group][]models.ReviewerCandidate)
+	var groups []group
+	for _, c := range candidates {
+		g := group{tier: c.Tier, unmatched: c.MatchingTags == 0}
+		if _, ok := byGroup[g]; !ok {
+			groups = append(groups, g)
+		}
+		byGroup[g] = append(byGroup[g], c)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].tier != groups[j].tier {
+			return groups[i].tier < groups[j].tier
+		}
+		return !groups[i].unmatched && groups[j].unmatched
+	})
+
+	var reviewers []string
+	for _, g := range groups {
+		if len(reviewers) >= count {
+			break
+		}
+		reviewers = append(reviewers, selector.Select(rng, byGroup[g], count-len(reviewers))...)
+	}
+	return reviewers
+}
+
+// RandomSelector picks reviewers uniformly at random.
+type RandomSelector struct{}
+
+func (RandomSelector) Select(rng *rand.Rand, candidates []models.ReviewerCandidate, count int) []string {
+	shuffled := shuffle(rng, candidates)
+	return firstIDs(shuffled, count)
+}
+
+// RoundRobinSelector rotates through the team by picking the members who were
+// assigned least recently. Members who were never assigned come first.
+type RoundRobinSelector struct{}
+
+func (RoundRobinSelector) Select(_ *rand.Rand, candidates []models.ReviewerCandidate, count int) []string {
+	ordered := append([]models.ReviewerCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].LastAssignedAt, ordered[j].LastAssignedAt
+		if a.Valid != b.Valid {
+			return !a.Valid
+		}
+		if a.Valid && !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		return ordered[i].UserID < ordered[j].UserID
+	})
+	return firstIDs(ordered, count)
+}
+
+// LeastLoadedSelector picks the members with the fewest open reviews, breaking
+// ties randomly.
+type LeastLoadedSelector struct{}
+
+func (LeastLoadedSelector) Select(rng *rand.Rand, candidates []models.ReviewerCandidate, count int) []string {
+	ordered := shuffle(rng, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].OpenReviews < ordered[j].OpenReviews
+	})
+	return firstIDs(ordered, count)
+}
+
+// WeightedRandomSelector picks reviewers at random with a probability
+// inversely proportional to their open reviews.
+type WeightedRandomSelector struct{}
+
+func (WeightedRandomSelector) Select(rng *rand.Rand, candidates []models.ReviewerCandidate, count int) []string {
+	pool := append([]models.ReviewerCandidate(nil), candidates...)
+
+	var reviewers []string
+	for len(pool) > 0 && len(reviewers) < count {
+		total := 0.0
+		for _, c := range pool {
+			total += inverseLoad(c)
+		}
+
+		point := rng.Float64() * total
+		picked := len(pool) - 1
+		for i, c := range pool {
+			point -= inverseLoad(c)
+			if point < 0 {
+				picked = i
+				break
+			}
+		}
+
+		reviewers = append(reviewers, pool[picked].UserID)
+		pool = append(pool[:picked], pool[picked+1:]...)
+	}
+	return reviewers
+}
+
+func inverseLoad(c models.ReviewerCandidate) float64 {
+	return 1 / float64(c.OpenReviews+1)
+}
+
+func shuffle(rng *rand.Rand, candidates []models.ReviewerCandidate) []models.ReviewerCandidate {
+	shuffled := append([]models.ReviewerCandidate(nil), candidates...)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func firstIDs(candidates []models.ReviewerCandidate, count int) []string {
+	var ids []string
+	for _, c := range candidates {
+		if len(ids) == count {
+			break
+		}
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
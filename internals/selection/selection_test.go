@@ -0,0 +1,161 @@
+package selection
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func TestGet_DefaultStrategy(t *testing.T) {
+	selector, err := Get("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := selector.(LeastLoadedSelector); !ok {
+		t.Errorf("Expected LeastLoadedSelector for empty strategy, got %T", selector)
+	}
+}
+
+func TestGet_UnknownStrategy(t *testing.T) {
+	_, err := Get("by_mood")
+	if err == nil || err.Error() != "UNKNOWN_STRATEGY" {
+		t.Errorf("Expected UNKNOWN_STRATEGY error, got %v", err)
+	}
+}
+
+func TestLeastLoadedSelector_PicksLowestLoad(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u1", OpenReviews: 3},
+		{UserID: "u2", OpenReviews: 0},
+		{UserID: "u3", OpenReviews: 5},
+		{UserID: "u4", OpenReviews: 1},
+	}
+
+	picked := LeastLoadedSelector{}.Select(NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] != "u2" || picked[1] != "u4" {
+		t.Errorf("Expected [u2 u4], got %v", picked)
+	}
+}
+
+func TestRoundRobinSelector_PicksLeastRecentlyAssigned(t *testing.T) {
+	now := time.Now()
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u1", LastAssignedAt: sql.NullTime{Time: now, Valid: true}},
+		{UserID: "u2", LastAssignedAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}},
+		{UserID: "u3"},
+	}
+
+	picked := RoundRobinSelector{}.Select(NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] != "u3" || picked[1] != "u2" {
+		t.Errorf("Expected [u3 u2], got %v", picked)
+	}
+}
+
+func TestRandomSelector_ReturnsDistinctCandidates(t *testing.T) {
+	candidates := []models.ReviewerCandidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+
+	picked := RandomSelector{}.Select(NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] == picked[1] {
+		t.Errorf("Expected 2 distinct reviewers, got %v", picked)
+	}
+}
+
+func TestWeightedRandomSelector_ReturnsAllWhenCountExceedsPool(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u1", OpenReviews: 10},
+		{UserID: "u2", OpenReviews: 0},
+	}
+
+	picked := WeightedRandomSelector{}.Select(NewRand("test"), candidates, 5)
+
+	if len(picked) != 2 {
+		t.Errorf("Expected 2 reviewers, got %v", picked)
+	}
+}
+
+func TestSelectors_EmptyCandidates(t *testing.T) {
+	for name, selector := range selectors {
+		if picked := selector.Select(NewRand("test"), nil, 2); len(picked) != 0 {
+			t.Errorf("Expected no reviewers from %s, got %v", name, picked)
+		}
+	}
+}
+
+func TestSelectWithFallback_TopsUpFromFallbackTiers(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "f2", Tier: 2},
+		{UserID: "own", Tier: 0},
+		{UserID: "f1", Tier: 1},
+	}
+
+	picked := SelectWithFallback(LeastLoadedSelector{}, NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] != "own" || picked[1] != "f1" {
+		t.Errorf("Expected [own f1], got %v", picked)
+	}
+}
+
+func TestSelectWithFallback_OwnTeamSuffices(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u1"},
+		{UserID: "u2"},
+		{UserID: "f1", Tier: 1},
+	}
+
+	picked := SelectWithFallback(RandomSelector{}, NewRand("test"), candidates, 2)
+
+	for _, id := range picked {
+		if id == "f1" {
+			t.Errorf("Expected no fallback reviewer, got %v", picked)
+		}
+	}
+}
+
+func TestSelectWithFallback_PrefersMatchingTags(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "frontend"},
+		{UserID: "gopher", MatchingTags: 1, OpenReviews: 4},
+		{UserID: "dba", MatchingTags: 2, OpenReviews: 6},
+	}
+
+	picked := SelectWithFallback(LeastLoadedSelector{}, NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] != "gopher" || picked[1] != "dba" {
+		t.Errorf("Expected [gopher dba], got %v", picked)
+	}
+}
+
+func TestSelectWithFallback_TopsUpWithUnmatchedTeamMembers(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "fallback", Tier: 1, MatchingTags: 1},
+		{UserID: "frontend"},
+		{UserID: "gopher", MatchingTags: 1},
+	}
+
+	picked := SelectWithFallback(LeastLoadedSelector{}, NewRand("test"), candidates, 2)
+
+	if len(picked) != 2 || picked[0] != "gopher" || picked[1] != "frontend" {
+		t.Errorf("Expected [gopher frontend], got %v", picked)
+	}
+}
+
+func TestNewRand_SameSeedSameReviewers(t *testing.T) {
+	candidates := []models.ReviewerCandidate{
+		{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u5"},
+	}
+
+	for name, selector := range selectors {
+		first := selector.Select(NewRand("pr-42"), candidates, 2)
+		for i := 0; i < 10; i++ {
+			again := selector.Select(NewRand("pr-42"), candidates, 2)
+			if len(again) != len(first) || again[0] != first[0] || again[1] != first[1] {
+				t.Fatalf("Expected %s to pick %v for the same seed, got %v", name, first, again)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package sla computes review deadlines of team SLAs and reassigns reviewers
+// who miss them.
+package sla
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// Default working day of an SLA counted in working hours.
+const (
+	DefaultWorkdayStart = 9
+	DefaultWorkdayEnd   = 18
+	DefaultTimezone     = "UTC"
+)
+
+// MaxResponseHours bounds the SLA to a month of wall-clock time.
+const MaxResponseHours = 720
+
+// Normalize fills the working day of an SLA counted in working hours.
+func Normalize(policy models.ReviewSLA) models.ReviewSLA {
+	if !policy.WorkingHoursOnly {
+		policy.WorkdayStart, policy.WorkdayEnd, policy.Timezone = 0, 0, ""
+		return policy
+	}
+	if policy.WorkdayStart == 0 && policy.WorkdayEnd == 0 {
+		policy.WorkdayStart, policy.WorkdayEnd = DefaultWorkdayStart, DefaultWorkdayEnd
+	}
+	if policy.Timezone == "" {
+		policy.Timezone = DefaultTimezone
+	}
+	return policy
+}
+
+// Validate reports why a normalized policy cannot be used.
+func Validate(policy models.ReviewSLA) error {
+	if policy.ResponseHours < 0 || policy.ResponseHours > MaxResponseHours {
+		return errors.New("review_sla.response_hours must be between 0 and 720")
+	}
+	if !policy.WorkingHoursOnly {
+		return nil
+	}
+	if policy.WorkdayStart < 0 || policy.WorkdayStart >= policy.WorkdayEnd || policy.WorkdayEnd > 24 {
+		return errors.New("review_sla must satisfy 0 <= workday_start < workday_end <= 24")
+	}
+	if _, err := time.LoadLocation(policy.Timezone); err != nil {
+		return errors.New("review_sla.timezone is not a known time zone")
+	}
+	return nil
+}
+
+// Deadline returns when a reviewer assigned at assignedAt must have responded
+// under a valid, normalized policy with ResponseHours set.
+func Deadline(policy models.ReviewSLA, assignedAt time.Time) time.Time {
+	remaining := time.Duration(policy.ResponseHours) * time.Hour
+	if !policy.WorkingHoursOnly {
+		return assignedAt.Add(remaining)
+	}
+
+	loc, err := time.LoadLocation(policy.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	t := assignedAt.In(loc)
+	for {
+		dayStart := time.Date(t.Year(), t.Month(), t.Day(), policy.WorkdayStart, 0, 0, 0, loc)
+		dayEnd := time.Date(t.Year(), t.Month(), t.Day(), policy.WorkdayEnd, 0, 0, 0, loc)
+
+		if isWeekend(t) || !t.Before(dayEnd) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, policy.WorkdayStart, 0, 0, 0, loc)
+			continue
+		}
+		if t.Before(dayStart) {
+			t = dayStart
+		}
+
+		available := dayEnd.Sub(t)
+		if remaining <= available {
+			return t.Add(remaining)
+		}
+		remaining -= available
+		t = dayEnd
+	}
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
@@ -0,0 +1,116 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func TestDeadline(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	workingHours := func(hours int) models.ReviewSLA {
+		return Normalize(models.ReviewSLA{ResponseHours: hours, WorkingHoursOnly: true})
+	}
+
+	tests := []struct {
+		name       string
+		policy     models.ReviewSLA
+		assignedAt time.Time
+		want       time.Time
+	}{
+		{
+			name:       "wall clock",
+			policy:     Normalize(models.ReviewSLA{ResponseHours: 24}),
+			assignedAt: time.Date(2025, 11, 7, 17, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 8, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "working hours over several days",
+			policy:     workingHours(24),
+			assignedAt: time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 5, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "ends with the working day",
+			policy:     workingHours(2),
+			assignedAt: time.Date(2025, 11, 7, 16, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 7, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "skips the weekend",
+			policy:     workingHours(2),
+			assignedAt: time.Date(2025, 11, 7, 17, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 10, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "assigned on a weekend",
+			policy:     workingHours(1),
+			assignedAt: time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "assigned before the working day",
+			policy:     workingHours(1),
+			assignedAt: time.Date(2025, 11, 3, 7, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "assigned after the working day",
+			policy:     workingHours(1),
+			assignedAt: time.Date(2025, 11, 3, 20, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 4, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "team time zone",
+			policy:     Normalize(models.ReviewSLA{ResponseHours: 2, WorkingHoursOnly: true, Timezone: "Europe/Moscow"}),
+			assignedAt: time.Date(2025, 11, 3, 5, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 11, 3, 11, 0, 0, 0, moscow),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Deadline(tt.policy, tt.assignedAt)
+			if !got.Equal(tt.want) {
+				t.Errorf("Deadline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  models.ReviewSLA
+		wantErr bool
+	}{
+		{name: "disabled", policy: models.ReviewSLA{}},
+		{name: "wall clock", policy: models.ReviewSLA{ResponseHours: 48}},
+		{name: "working hours defaults", policy: models.ReviewSLA{ResponseHours: 24, WorkingHoursOnly: true}},
+		{name: "negative hours", policy: models.ReviewSLA{ResponseHours: -1}, wantErr: true},
+		{name: "too many hours", policy: models.ReviewSLA{ResponseHours: MaxResponseHours + 1}, wantErr: true},
+		{name: "empty working day", policy: models.ReviewSLA{ResponseHours: 8, WorkingHoursOnly: true, WorkdayStart: 10, WorkdayEnd: 10}, wantErr: true},
+		{name: "working day past midnight", policy: models.ReviewSLA{ResponseHours: 8, WorkingHoursOnly: true, WorkdayStart: 10, WorkdayEnd: 25}, wantErr: true},
+		{name: "unknown time zone", policy: models.ReviewSLA{ResponseHours: 8, WorkingHoursOnly: true, Timezone: "Mars/Olympus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(Normalize(tt.policy))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalize_DropsWorkingDayOfWallClockSLA(t *testing.T) {
+	got := Normalize(models.ReviewSLA{ResponseHours: 4, WorkdayStart: 8, WorkdayEnd: 17, Timezone: "UTC"})
+	if got != (models.ReviewSLA{ResponseHours: 4}) {
+		t.Errorf("Normalize() = %+v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package sla
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// AssignmentLister finds assignments that may have missed their SLA.
+type AssignmentLister interface {
+	ListUnansweredAssignments(ctx context.Context) ([]models.SLAAssignment, time.Time, error)
+}
+
+// Reassigner hands an overdue assignment over to another reviewer and
+// returns them, or an empty string when the reviewer was kept.
+type Reassigner interface {
+	ReassignOverdue(ctx context.Context, assignment models.SLAAssignment) (string, error)
+}
+
+// Worker periodically reassigns reviewers past their SLA deadline. Every
+// replica of the service may run one: the storage skips pull requests another
+// replica is working on and refuses assignments already handled.
+type Worker struct {
+	Storage    AssignmentLister
+	Reassigner Reassigner
+	Interval   time.Duration
+}
+
+func NewWorker(storage AssignmentLister, reassigner Reassigner, interval time.Duration) *Worker {
+	return &Worker{
+		Storage:    storage,
+		Reassigner: reassigner,
+		Interval:   interval,
+	}
+}
+
+// Run checks the deadlines every Interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("SLA worker started", "interval", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("SLA worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := w.RunOnce(ctx); err != nil {
+				slog.Error("SLA check failed", "err", err)
+			}
+		}
+	}
+}
+
+// RunOnce reassigns every assignment past its deadline and returns how many
+// breaches it handled. A failed reassignment is logged and does not stop the
+// others.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	assignments, now, err := w.Storage.ListUnansweredAssignments(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, a := range assignments {
+		if now.Before(Deadline(Normalize(a.SLA), a.AssignedAt)) {
+			continue
+		}
+
+		newReviewerID, err := w.Reassigner.ReassignOverdue(ctx, a)
+		if err != nil {
+			if err.Error() != "SLA_NOT_BREACHED" {
+				slog.Error("Failed to reassign overdue reviewer", "prID", a.PullRequestID, "reviewerID", a.ReviewerID, "err", err)
+			}
+			continue
+		}
+
+		handled++
+		if newReviewerID == "" {
+			slog.Warn("Review SLA breached, no replacement available", "prID", a.PullRequestID, "reviewerID", a.ReviewerID)
+			continue
+		}
+		slog.Info("Review SLA breached, reviewer reassigned", "prID", a.PullRequestID, "oldID", a.ReviewerID, "newID", newReviewerID)
+	}
+	return handled, nil
+}
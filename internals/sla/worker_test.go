@@ -0,0 +1,77 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type fakeReassigner struct {
+	results map[string]string
+	errs    map[string]error
+	calls   []string
+}
+
+func (f *fakeReassigner) ReassignOverdue(ctx context.Context, assignment models.SLAAssignment) (string, error) {
+	f.calls = append(f.calls, assignment.PullRequestID)
+	return f.results[assignment.PullRequestID], f.errs[assignment.PullRequestID]
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC) // Monday
+	wallClock := models.ReviewSLA{ResponseHours: 24}
+	workingHours := models.ReviewSLA{ResponseHours: 24, WorkingHoursOnly: true}
+
+	storage := mocks.NewMockRequestStorage()
+	storage.Now = now
+	storage.Overdue = []models.SLAAssignment{
+		{PullRequestID: "pr-overdue", ReviewerID: "u2", AssignedAt: now.Add(-25 * time.Hour), SLA: wallClock},
+		// Friday morning: 24 working hours end on Tuesday.
+		{PullRequestID: "pr-weekend", ReviewerID: "u3", AssignedAt: now.Add(-74 * time.Hour), SLA: workingHours},
+		{PullRequestID: "pr-no-candidate", ReviewerID: "u4", AssignedAt: now.Add(-48 * time.Hour), SLA: wallClock},
+		{PullRequestID: "pr-handled", ReviewerID: "u5", AssignedAt: now.Add(-48 * time.Hour), SLA: wallClock},
+		{PullRequestID: "pr-failed", ReviewerID: "u6", AssignedAt: now.Add(-48 * time.Hour), SLA: wallClock},
+	}
+
+	reassigner := &fakeReassigner{
+		results: map[string]string{"pr-overdue": "u7"},
+		errs: map[string]error{
+			"pr-handled": errors.New("SLA_NOT_BREACHED"),
+			"pr-failed":  errors.New("connection reset"),
+		},
+	}
+
+	handled, err := NewWorker(storage, reassigner, time.Minute).RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if handled != 2 {
+		t.Errorf("handled = %d, want 2", handled)
+	}
+
+	want := []string{"pr-overdue", "pr-no-candidate", "pr-handled", "pr-failed"}
+	if len(reassigner.calls) != len(want) {
+		t.Fatalf("reassigned %v, want %v", reassigner.calls, want)
+	}
+	for i := range want {
+		if reassigner.calls[i] != want[i] {
+			t.Errorf("reassigned %v, want %v", reassigner.calls, want)
+			break
+		}
+	}
+}
+
+func TestWorker_RunOnceListError(t *testing.T) {
+	storage := mocks.NewMockRequestStorage()
+	storage.ListUnansweredAssignmentsFunc = func(ctx context.Context) ([]models.SLAAssignment, time.Time, error) {
+		return nil, time.Time{}, errors.New("db down")
+	}
+
+	if _, err := NewWorker(storage, &fakeReassigner{}, time.Minute).RunOnce(context.Background()); err == nil {
+		t.Error("RunOnce() error = nil, want error")
+	}
+}
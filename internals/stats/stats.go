@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/storage"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type StatsService struct {
+	StatsStorage storage.StatsStorage
+	TeamStorage  storage.TeamStorage
+}
+
+var statsPrefix = "stats"
+
+func New(statsStorage storage.StatsStorage, teamStorage storage.TeamStorage) *StatsService {
+	return &StatsService{
+		StatsStorage: statsStorage,
+		TeamStorage:  teamStorage,
+	}
+}
+
+func (s *StatsService) RegisterRoutes(r *gin.RouterGroup) {
+	statsRouter := r.Group("/" + statsPrefix)
+
+	statsRouter.GET("/reviewers", s.GetReviewerStats)
+	statsRouter.GET("/pullRequests", s.GetPullRequestStats)
+}
+
+type ReviewerStatsResponse struct {
+	Reviewers []models.ReviewerStats `json:"reviewers"`
+}
+
+type PullRequestStatsResponse struct {
+	PullRequests []models.PullRequestStats `json:"pull_requests"`
+}
+
+func (s *StatsService) GetReviewerStats(c *gin.Context) {
+	ctx := context.Background()
+
+	filter, ok := s.bindFilter(ctx, c)
+	if !ok {
+		return
+	}
+
+	reviewers, err := s.StatsStorage.GetReviewerStats(ctx, filter)
+	if err != nil {
+		slog.Error("Failed to get reviewer stats", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get reviewer stats",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReviewerStatsResponse{Reviewers: reviewers})
+}
+
+func (s *StatsService) GetPullRequestStats(c *gin.Context) {
+	ctx := context.Background()
+
+	filter, ok := s.bindFilter(ctx, c)
+	if !ok {
+		return
+	}
+
+	pullRequests, err := s.StatsStorage.GetPullRequestStats(ctx, filter)
+	if err != nil {
+		slog.Error("Failed to get pull request stats", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get pull request stats",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PullRequestStatsResponse{PullRequests: pullRequests})
+}
+
+// bindFilter reads the team_name, from and to query parameters. It writes the
+// error response itself and reports false when the request cannot proceed.
+func (s *StatsService) bindFilter(ctx context.Context, c *gin.Context) (models.StatsFilter, bool) {
+	var filter models.StatsFilter
+
+	from, err := parseTime(c.Query("from"))
+	if err != nil {
+		invalidRequest(c, "from must be an RFC 3339 timestamp")
+		return filter, false
+	}
+	to, err := parseTime(c.Query("to"))
+	if err != nil {
+		invalidRequest(c, "to must be an RFC 3339 timestamp")
+		return filter, false
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		invalidRequest(c, "from must be before to")
+		return filter, false
+	}
+	filter.From = from
+	filter.To = to
+
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		return filter, true
+	}
+
+	team, err := s.TeamStorage.GetTeamByName(ctx, teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return filter, false
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return filter, false
+	}
+	filter.TeamID = team.ID
+
+	return filter, true
+}
+
+// parseTime parses an optional RFC 3339 query value. The schema stores
+// timestamps without a time zone in UTC, so the result is converted to UTC.
+func parseTime(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullTime{}, errors.New("INVALID_TIME")
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+func invalidRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"code":    "INVALID_REQUEST",
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+func setupRouter(service *StatsService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api/v1")
+	service.RegisterRoutes(api)
+	return r
+}
+
+func TestGetReviewerStats_Success(t *testing.T) {
+	statsStorage := mocks.NewMockStatsStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(statsStorage, teamStorage)
+	router := setupRouter(service)
+
+	statsStorage.Reviewers = []models.ReviewerStats{
+		{UserID: "u1", Username: "Alice", TotalAssignments: 3, OpenAssignments: 1, MergedReviews: 2},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/reviewers", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response ReviewerStatsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.Reviewers) != 1 || response.Reviewers[0].MergedReviews != 2 {
+		t.Errorf("Expected stats of u1, got %+v", response.Reviewers)
+	}
+}
+
+func TestGetReviewerStats_PassesFilter(t *testing.T) {
+	statsStorage := mocks.NewMockStatsStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(statsStorage, teamStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 7, Name: "Backend"}
+
+	var got models.StatsFilter
+	statsStorage.GetReviewerStatsFunc = func(ctx context.Context, filter models.StatsFilter) ([]models.ReviewerStats, error) {
+		got = filter
+		return []models.ReviewerStats{}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/reviewers?team_name=Backend&from=2025-11-01T03:00:00%2B03:00&to=2025-11-02T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if got.TeamID != 7 {
+		t.Errorf("Expected team ID 7, got %d", got.TeamID)
+	}
+	if !got.From.Valid || !got.From.Time.Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)) || got.From.Time.Location() != time.UTC {
+		t.Errorf("Expected from in UTC, got %v", got.From)
+	}
+	if !got.To.Valid {
+		t.Error("Expected to to be set")
+	}
+}
+
+func TestGetReviewerStats_TeamNotFound(t *testing.T) {
+	statsStorage := mocks.NewMockStatsStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(statsStorage, teamStorage)
+	router := setupRouter(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/reviewers?team_name=Ghosts", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGetPullRequestStats_InvalidWindow(t *testing.T) {
+	statsStorage := mocks.NewMockStatsStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(statsStorage, teamStorage)
+	router := setupRouter(service)
+
+	for _, query := range []string{
+		"?from=yesterday",
+		"?to=2025-11-01",
+		"?from=2025-11-02T00:00:00Z&to=2025-11-01T00:00:00Z",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/pullRequests"+query, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestGetPullRequestStats_Success(t *testing.T) {
+	statsStorage := mocks.NewMockStatsStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(statsStorage, teamStorage)
+	router := setupRouter(service)
+
+	seconds := int64(3600)
+	statsStorage.PullRequests = []models.PullRequestStats{
+		{ID: "pr-1", Name: "Feature", AuthorID: "u1", Status: "MERGED", ReviewersCount: 2, TimeToMergeSeconds: &seconds},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/pullRequests", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response PullRequestStatsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.PullRequests) != 1 || response.PullRequests[0].TimeToMergeSeconds == nil || *response.PullRequests[0].TimeToMergeSeconds != 3600 {
+		t.Errorf("Expected time to merge of 3600s, got %+v", response.PullRequests)
+	}
+}
@@ -2,28 +2,42 @@ package mocks
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
 
 type MockTeamStorage struct {
-	mu                          sync.RWMutex
-	Teams                       map[string]models.Team
-	TeamsByID                   map[int]models.Team
-	TeamMembers                 map[int][]string
-	AddTeamFunc                 func(ctx context.Context, team models.Team) (models.Team, error)
-	GetTeamByNameFunc           func(ctx context.Context, name string) (models.Team, error)
-	GetRandomReviewersFunc      func(ctx context.Context, teamID int, authorID string, limit int) ([]string, error)
-	GetReplacementCandidateFunc func(ctx context.Context, teamID int, excludeUserIDs []string) (string, error)
+	mu          sync.RWMutex
+	Teams       map[string]models.Team
+	TeamsByID   map[int]models.Team
+	TeamMembers map[int][]string
+	// OpenReviewers review OPEN pull requests, which keeps their teams from
+	// being deleted.
+	OpenReviewers          map[string]bool
+	AddTeamFunc            func(ctx context.Context, team models.Team) (models.Team, error)
+	GetTeamByNameFunc      func(ctx context.Context, name string) (models.Team, error)
+	GetTeamSettingsFunc    func(ctx context.Context, teamID int) (models.TeamSettings, error)
+	UpdateTeamSettingsFunc func(ctx context.Context, teamName string, settings models.TeamSettings) (models.Team, error)
+	DeactivateMembersFunc  func(ctx context.Context, teamID int, userIDs []string, pickFor storage.TeamReviewerPicker) ([]models.ReviewerReassignment, error)
+	AddMembersFunc         func(ctx context.Context, teamID int, members []models.User) error
+	RemoveMembersFunc      func(ctx context.Context, teamID int, userIDs []string) error
+	RenameTeamFunc         func(ctx context.Context, teamName, newName string) (models.Team, error)
+	DeleteTeamFunc         func(ctx context.Context, teamName string) error
 }
 
 func NewMockTeamStorage() *MockTeamStorage {
 	return &MockTeamStorage{
-		Teams:       make(map[string]models.Team),
-		TeamsByID:   make(map[int]models.Team),
-		TeamMembers: make(map[int][]string),
+		Teams:         make(map[string]models.Team),
+		TeamsByID:     make(map[int]models.Team),
+		TeamMembers:   make(map[int][]string),
+		OpenReviewers: make(map[string]bool),
 	}
 }
 
@@ -39,6 +53,7 @@ func (m *MockTeamStorage) AddTeam(ctx context.Context, team models.Team) (models
 	}
 
 	team.ID = len(m.Teams) + 1
+	team.Settings = models.DefaultTeamSettings()
 	m.Teams[team.Name] = team
 	m.TeamsByID[team.ID] = team
 	return team, nil
@@ -58,61 +73,206 @@ func (m *MockTeamStorage) GetTeamByName(ctx context.Context, name string) (model
 	return team, nil
 }
 
-func (m *MockTeamStorage) GetRandomReviewers(ctx context.Context, teamID int, authorID string, limit int) ([]string, error) {
-	if m.GetRandomReviewersFunc != nil {
-		return m.GetRandomReviewersFunc(ctx, teamID, authorID, limit)
+func (m *MockTeamStorage) GetTeamSettings(ctx context.Context, teamID int) (models.TeamSettings, error) {
+	if m.GetTeamSettingsFunc != nil {
+		return m.GetTeamSettingsFunc(ctx, teamID)
 	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	team, exists := m.TeamsByID[teamID]
 	if !exists {
-		return nil, errors.New("NOT_FOUND")
+		return models.DefaultTeamSettings(), nil
 	}
+	return team.Settings, nil
+}
 
-	var reviewers []string
-	for _, member := range team.Members {
-		if member.ID != authorID && member.IsActive && len(reviewers) < limit {
-			reviewers = append(reviewers, member.ID)
+func (m *MockTeamStorage) UpdateTeamSettings(ctx context.Context, teamName string, settings models.TeamSettings) (models.Team, error) {
+	if m.UpdateTeamSettingsFunc != nil {
+		return m.UpdateTeamSettingsFunc(ctx, teamName, settings)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, exists := m.Teams[teamName]
+	if !exists {
+		return models.Team{}, errors.New("NOT_FOUND")
+	}
+
+	for _, fallbackName := range settings.FallbackTeams {
+		if _, exists := m.Teams[fallbackName]; !exists {
+			return models.Team{}, errors.New("FALLBACK_NOT_FOUND")
 		}
 	}
-	return reviewers, nil
+
+	team.Settings = settings
+	m.Teams[teamName] = team
+	m.TeamsByID[team.ID] = team
+	return team, nil
 }
 
-func (m *MockTeamStorage) GetReplacementCandidate(ctx context.Context, teamID int, excludeUserIDs []string) (string, error) {
-	if m.GetReplacementCandidateFunc != nil {
-		return m.GetReplacementCandidateFunc(ctx, teamID, excludeUserIDs)
+func (m *MockTeamStorage) DeactivateMembers(ctx context.Context, teamID int, userIDs []string, pickFor storage.TeamReviewerPicker) ([]models.ReviewerReassignment, error) {
+	if m.DeactivateMembersFunc != nil {
+		return m.DeactivateMembersFunc(ctx, teamID, userIDs, pickFor)
 	}
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	team, exists := m.TeamsByID[teamID]
+	deactivated := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		deactivated[id] = true
+	}
+
+	for name, team := range m.Teams {
+		members := append([]models.User(nil), team.Members...)
+		for i := range members {
+			if deactivated[members[i].ID] {
+				members[i].IsActive = false
+			}
+		}
+		team.Members = members
+		m.Teams[name] = team
+		if _, exists := m.TeamsByID[team.ID]; exists {
+			m.TeamsByID[team.ID] = team
+		}
+	}
+	return []models.ReviewerReassignment{}, nil
+}
+
+// teamByID returns the team stored under id in Teams. Callers hold m.mu.
+func (m *MockTeamStorage) teamByID(teamID int) (models.Team, bool) {
+	for _, team := range m.Teams {
+		if team.ID == teamID {
+			return team, true
+		}
+	}
+	return models.Team{}, false
+}
+
+// storeTeam saves team under its name and ID. Callers hold m.mu.
+func (m *MockTeamStorage) storeTeam(team models.Team) {
+	m.Teams[team.Name] = team
+	m.TeamsByID[team.ID] = team
+}
+
+func (m *MockTeamStorage) AddMembers(ctx context.Context, teamID int, members []models.User) error {
+	if m.AddMembersFunc != nil {
+		return m.AddMembersFunc(ctx, teamID, members)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, exists := m.teamByID(teamID)
 	if !exists {
-		return "", errors.New("NOT_FOUND")
+		return errors.New("NOT_FOUND")
 	}
 
-	excludeMap := make(map[string]bool)
-	for _, id := range excludeUserIDs {
-		excludeMap[id] = true
+	team.Members = slices.Clone(team.Members)
+	for _, member := range members {
+		index := slices.IndexFunc(team.Members, func(u models.User) bool { return u.ID == member.ID })
+		if index == -1 {
+			team.Members = append(team.Members, member)
+			continue
+		}
+		team.Members[index] = member
+	}
+	m.storeTeam(team)
+	return nil
+}
+
+func (m *MockTeamStorage) RemoveMembers(ctx context.Context, teamID int, userIDs []string) error {
+	if m.RemoveMembersFunc != nil {
+		return m.RemoveMembersFunc(ctx, teamID, userIDs)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, exists := m.teamByID(teamID)
+	if !exists {
+		return errors.New("NOT_FOUND")
+	}
+
+	for _, userID := range userIDs {
+		if !slices.ContainsFunc(team.Members, func(u models.User) bool { return u.ID == userID }) {
+			return errors.New("NOT_TEAM_MEMBER")
+		}
+	}
+
+	team.Members = slices.DeleteFunc(slices.Clone(team.Members), func(u models.User) bool {
+		return slices.Contains(userIDs, u.ID)
+	})
+	m.storeTeam(team)
+	return nil
+}
+
+func (m *MockTeamStorage) RenameTeam(ctx context.Context, teamName, newName string) (models.Team, error) {
+	if m.RenameTeamFunc != nil {
+		return m.RenameTeamFunc(ctx, teamName, newName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, exists := m.Teams[teamName]
+	if !exists {
+		return models.Team{}, errors.New("NOT_FOUND")
+	}
+	if _, taken := m.Teams[newName]; taken {
+		return models.Team{}, errors.New("TEAM_EXISTS")
 	}
 
+	delete(m.Teams, teamName)
+	team.Name = newName
+	m.storeTeam(team)
+	return team, nil
+}
+
+func (m *MockTeamStorage) DeleteTeam(ctx context.Context, teamName string) error {
+	if m.DeleteTeamFunc != nil {
+		return m.DeleteTeamFunc(ctx, teamName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, exists := m.Teams[teamName]
+	if !exists {
+		return errors.New("NOT_FOUND")
+	}
 	for _, member := range team.Members {
-		if member.IsActive && !excludeMap[member.ID] {
-			return member.ID, nil
+		if m.OpenReviewers[member.ID] {
+			return errors.New("TEAM_HAS_OPEN_REVIEWS")
 		}
 	}
-	return "", errors.New("NO_CANDIDATE")
+
+	delete(m.Teams, teamName)
+	delete(m.TeamsByID, team.ID)
+	return nil
 }
 
 type MockUserStorage struct {
-	mu                 sync.RWMutex
-	Users              map[string]models.User
-	UserReviews        map[string][]models.PullRequest
-	UserTeams          map[string]int
-	SetIsActiveFunc    func(ctx context.Context, userID string, isActive bool) error
-	GetIsActiveFunc    func(ctx context.Context, userID string) (bool, error)
-	GetUserReviewsFunc func(ctx context.Context, userID string) ([]models.PullRequest, error)
-	GetUserTeamIDFunc  func(ctx context.Context, userID string) (int, error)
+	mu          sync.RWMutex
+	Users       map[string]models.User
+	UserReviews map[string][]models.PullRequest
+	// UserTeams holds the primary team of each user, Memberships all of them.
+	UserTeams     map[string]int
+	Memberships   map[string][]int
+	TeamNames     map[int]string
+	Absences      map[string][]models.Absence
+	nextAbsenceID int64
+	// Capacities holds the review capacity of users; others have none set.
+	Capacities            map[string]models.ReviewCapacity
+	SetIsActiveFunc       func(ctx context.Context, userID string, isActive bool) error
+	SetTagsFunc           func(ctx context.Context, userID string, tags []string) error
+	GetIsActiveFunc       func(ctx context.Context, userID string) (bool, error)
+	GetUserReviewsFunc    func(ctx context.Context, userID string) ([]models.PullRequest, error)
+	GetUserTeamIDFunc     func(ctx context.Context, userID string) (int, error)
+	Events                []models.PullRequestEvent
+	GetUserActivityFunc   func(ctx context.Context, filter models.ActivityFilter) ([]models.PullRequestEvent, error)
+	SetPrimaryTeamFunc    func(ctx context.Context, userID string, teamID int) error
+	GetUserFunc           func(ctx context.Context, userID string) (models.UserProfile, error)
+	ListUsersFunc         func(ctx context.Context, filter models.UserFilter) (models.UserPage, error)
+	AddAbsenceFunc        func(ctx context.Context, absence models.Absence) (models.Absence, error)
+	IsAbsentFunc          func(ctx context.Context, userID string) (bool, error)
+	SetMaxOpenReviewsFunc func(ctx context.Context, userID string, maxOpenReviews *int) error
 }
 
 func NewMockUserStorage() *MockUserStorage {
@@ -120,6 +280,10 @@ func NewMockUserStorage() *MockUserStorage {
 		Users:       make(map[string]models.User),
 		UserReviews: make(map[string][]models.PullRequest),
 		UserTeams:   make(map[string]int),
+		Memberships: make(map[string][]int),
+		TeamNames:   make(map[int]string),
+		Absences:    make(map[string][]models.Absence),
+		Capacities:  make(map[string]models.ReviewCapacity),
 	}
 }
 
@@ -140,6 +304,23 @@ func (m *MockUserStorage) SetIsActive(ctx context.Context, userID string, isActi
 	return nil
 }
 
+func (m *MockUserStorage) SetTags(ctx context.Context, userID string, tags []string) error {
+	if m.SetTagsFunc != nil {
+		return m.SetTagsFunc(ctx, userID, tags)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, exists := m.Users[userID]
+	if !exists {
+		return errors.New("NOT_FOUND")
+	}
+
+	user.Tags = tags
+	m.Users[userID] = user
+	return nil
+}
+
 func (m *MockUserStorage) GetIsActive(ctx context.Context, userID string) (bool, error) {
 	if m.GetIsActiveFunc != nil {
 		return m.GetIsActiveFunc(ctx, userID)
@@ -182,25 +363,309 @@ func (m *MockUserStorage) GetUserTeamID(ctx context.Context, userID string) (int
 	return teamID, nil
 }
 
+func (m *MockUserStorage) SetPrimaryTeam(ctx context.Context, userID string, teamID int) error {
+	if m.SetPrimaryTeamFunc != nil {
+		return m.SetPrimaryTeamFunc(ctx, userID, teamID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.Users[userID]; !exists {
+		return errors.New("NOT_FOUND")
+	}
+	if !slices.Contains(m.Memberships[userID], teamID) {
+		return errors.New("NOT_TEAM_MEMBER")
+	}
+	m.UserTeams[userID] = teamID
+	return nil
+}
+
+func (m *MockUserStorage) GetUser(ctx context.Context, userID string) (models.UserProfile, error) {
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(ctx, userID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, exists := m.Users[userID]
+	if !exists {
+		return models.UserProfile{}, errors.New("NOT_FOUND")
+	}
+	return m.profile(user), nil
+}
+
+func (m *MockUserStorage) ListUsers(ctx context.Context, filter models.UserFilter) (models.UserPage, error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(ctx, filter)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userIDs := make([]string, 0, len(m.Users))
+	for userID := range m.Users {
+		userIDs = append(userIDs, userID)
+	}
+	slices.Sort(userIDs)
+
+	page := models.UserPage{Users: []models.UserProfile{}}
+	for _, userID := range userIDs {
+		user := m.Users[userID]
+		switch {
+		case userID <= filter.After:
+		case filter.Search != "" && !strings.Contains(strings.ToLower(user.Username), strings.ToLower(filter.Search)):
+		case filter.TeamID != 0 && !slices.Contains(m.Memberships[userID], filter.TeamID):
+		case filter.IsActive != nil && user.IsActive != *filter.IsActive:
+		default:
+			if len(page.Users) == filter.Limit {
+				page.NextAfter = page.Users[len(page.Users)-1].ID
+				return page, nil
+			}
+			page.Users = append(page.Users, m.profile(user))
+		}
+	}
+	return page, nil
+}
+
+func (m *MockUserStorage) AddAbsence(ctx context.Context, absence models.Absence) (models.Absence, error) {
+	if m.AddAbsenceFunc != nil {
+		return m.AddAbsenceFunc(ctx, absence)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.Users[absence.UserID]; !exists {
+		return models.Absence{}, errors.New("NOT_FOUND")
+	}
+	m.nextAbsenceID++
+	absence.ID = m.nextAbsenceID
+	absence.CreatedAt = time.Now()
+	m.Absences[absence.UserID] = append(m.Absences[absence.UserID], absence)
+	return absence, nil
+}
+
+func (m *MockUserStorage) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.Users[userID]; !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+	now := time.Now()
+	absences := []models.Absence{}
+	for _, absence := range m.Absences[userID] {
+		if absence.EndsAt.After(now) {
+			absences = append(absences, absence)
+		}
+	}
+	slices.SortStableFunc(absences, func(a, b models.Absence) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+	return absences, nil
+}
+
+func (m *MockUserStorage) DeleteAbsence(ctx context.Context, userID string, absenceID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	absences := m.Absences[userID]
+	i := slices.IndexFunc(absences, func(a models.Absence) bool { return a.ID == absenceID })
+	if i < 0 {
+		return errors.New("NOT_FOUND")
+	}
+	m.Absences[userID] = slices.Delete(absences, i, i+1)
+	return nil
+}
+
+func (m *MockUserStorage) IsAbsent(ctx context.Context, userID string) (bool, error) {
+	if m.IsAbsentFunc != nil {
+		return m.IsAbsentFunc(ctx, userID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	return slices.ContainsFunc(m.Absences[userID], func(a models.Absence) bool { return a.Active(now) }), nil
+}
+
+// ClaimStartedAbsences takes the pull requests under review from UserReviews.
+func (m *MockUserStorage) ClaimStartedAbsences(ctx context.Context) ([]models.Absence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	claimed := []models.Absence{}
+	for userID, absences := range m.Absences {
+		for i, absence := range absences {
+			if !absence.ReassignReviews || absence.ReviewsReassignedAt.Valid || absence.ReviewsClaimedAt.Valid || !absence.Active(now) {
+				continue
+			}
+			absences[i].ReviewsClaimedAt = sql.NullTime{Time: now, Valid: true}
+			absence.ReviewsClaimedAt = absences[i].ReviewsClaimedAt
+			for _, pr := range m.UserReviews[userID] {
+				if pr.Status == models.StatusOpen {
+					absence.PullRequestIDs = append(absence.PullRequestIDs, pr.ID)
+				}
+			}
+			claimed = append(claimed, absence)
+		}
+	}
+	slices.SortFunc(claimed, func(a, b models.Absence) int { return int(a.ID - b.ID) })
+	return claimed, nil
+}
+
+func (m *MockUserStorage) CompleteAbsence(ctx context.Context, absence models.Absence) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if claimed := m.claimedAbsence(absence); claimed != nil {
+		claimed.ReviewsReassignedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (m *MockUserStorage) ReleaseAbsence(ctx context.Context, absence models.Absence) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if claimed := m.claimedAbsence(absence); claimed != nil {
+		claimed.ReviewsClaimedAt = sql.NullTime{}
+	}
+	return nil
+}
+
+// claimedAbsence returns the stored absence while it still holds the claim
+// of absence.
+func (m *MockUserStorage) claimedAbsence(absence models.Absence) *models.Absence {
+	for i, stored := range m.Absences[absence.UserID] {
+		if stored.ID == absence.ID && stored.ReviewsClaimedAt.Valid && stored.ReviewsClaimedAt.Time.Equal(absence.ReviewsClaimedAt.Time) {
+			return &m.Absences[absence.UserID][i]
+		}
+	}
+	return nil
+}
+
+// SetMaxOpenReviews keeps the limit in effect of Capacities in step with the
+// user's own one; a cleared limit falls back to none.
+func (m *MockUserStorage) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) error {
+	if m.SetMaxOpenReviewsFunc != nil {
+		return m.SetMaxOpenReviewsFunc(ctx, userID, maxOpenReviews)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.Users[userID]; !exists {
+		return errors.New("NOT_FOUND")
+	}
+	capacity := m.Capacities[userID]
+	capacity.MaxOpenReviews = maxOpenReviews
+	capacity.Limit = 0
+	if maxOpenReviews != nil {
+		capacity.Limit = *maxOpenReviews
+	}
+	m.Capacities[userID] = capacity
+	return nil
+}
+
+func (m *MockUserStorage) GetReviewCapacity(ctx context.Context, userID string) (models.ReviewCapacity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.Users[userID]; !exists {
+		return models.ReviewCapacity{}, errors.New("NOT_FOUND")
+	}
+	return m.Capacities[userID], nil
+}
+
+func (m *MockUserStorage) profile(user models.User) models.UserProfile {
+	profile := models.UserProfile{User: user, Teams: []models.TeamMembership{}, ReviewCapacity: m.Capacities[user.ID]}
+	for _, teamID := range m.Memberships[user.ID] {
+		membership := models.TeamMembership{
+			TeamName:  m.TeamNames[teamID],
+			IsPrimary: m.UserTeams[user.ID] == teamID,
+		}
+		if membership.IsPrimary {
+			profile.TeamName = membership.TeamName
+		}
+		profile.Teams = append(profile.Teams, membership)
+	}
+	return profile
+}
+
+func (m *MockUserStorage) GetUserActivity(ctx context.Context, filter models.ActivityFilter) ([]models.PullRequestEvent, error) {
+	if m.GetUserActivityFunc != nil {
+		return m.GetUserActivityFunc(ctx, filter)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.Users[filter.UserID]; !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	events := []models.PullRequestEvent{}
+	for i := len(m.Events) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		event := m.Events[i]
+		if filter.BeforeID != 0 && event.ID >= filter.BeforeID {
+			continue
+		}
+		if event.ActorID == filter.UserID || event.ReviewerID == filter.UserID || event.OldReviewerID == filter.UserID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
 type MockRequestStorage struct {
-	mu                    sync.RWMutex
-	PullRequests          map[string]models.PullRequest
-	PRReviewers           map[string][]string
-	CreatePullRequestFunc func(ctx context.Context, pr models.PullRequest, reviewerIDs []string) (models.PullRequest, error)
-	MergePullRequestFunc  func(ctx context.Context, pullRequestID string) (models.PullRequest, error)
-	ReassignReviewerFunc  func(ctx context.Context, pullRequestID string, oldReviewerID string, newReviewerID string) (models.PullRequest, error)
+	mu             sync.RWMutex
+	PullRequests   map[string]models.PullRequest
+	PRReviewers    map[string][]string
+	TeamCandidates map[int][]models.ReviewerCandidate
+	UserTags       map[string][]string
+	Reviews        []models.Review
+	// ReviewerChanges holds manual reviewer changes in the order they were made.
+	ReviewerChanges []models.ReviewerChange
+	Changes         []models.PullRequestChange
+	Events          []models.PullRequestEvent
+	// Overdue are returned by ListUnansweredAssignments until reassigned,
+	// together with Now, or the current time when Now is zero.
+	Overdue []models.SLAAssignment
+	Now     time.Time
+	// InactiveUsers are reported as inactive reviewers in merge states.
+	InactiveUsers map[string]bool
+	// AbsentUsers are active users on a current absence.
+	AbsentUsers                   map[string]bool
+	CreatePullRequestFunc         func(ctx context.Context, pr models.PullRequest, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error)
+	GetPullRequestFunc            func(ctx context.Context, pullRequestID string) (models.PullRequest, error)
+	ListPullRequestsFunc          func(ctx context.Context, filter models.PullRequestFilter) (models.PullRequestPage, error)
+	MergePullRequestFunc          func(ctx context.Context, pullRequestID string, check storage.MergeCheck) (models.PullRequest, error)
+	GetMergeStateFunc             func(ctx context.Context, pullRequestID string) (models.MergeState, error)
+	AddReviewFunc                 func(ctx context.Context, review models.Review) (models.Review, error)
+	GetReviewsFunc                func(ctx context.Context, pullRequestID string) ([]models.Review, error)
+	TransitionPullRequestFunc     func(ctx context.Context, pullRequestID string, transition models.PullRequestTransition, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error)
+	ReassignReviewerFunc          func(ctx context.Context, pullRequestID string, oldReviewerID string, teamID int, pick storage.ReviewerPicker, reason string) (models.PullRequest, string, error)
+	AddReviewerFunc               func(ctx context.Context, change models.ReviewerChange, teamID int) (models.PullRequest, models.ReviewerChange, error)
+	RemoveReviewerFunc            func(ctx context.Context, change models.ReviewerChange) (models.PullRequest, models.ReviewerChange, error)
+	UpdatePullRequestFunc         func(ctx context.Context, update models.PullRequestUpdate, teamID int, pick storage.ReviewerPicker) (models.PullRequestUpdateResult, error)
+	GetPullRequestChangesFunc     func(ctx context.Context, pullRequestID string) ([]models.PullRequestChange, error)
+	GetPullRequestEventsFunc      func(ctx context.Context, pullRequestID string) ([]models.PullRequestEvent, error)
+	ListUnansweredAssignmentsFunc func(ctx context.Context) ([]models.SLAAssignment, time.Time, error)
+	ReassignOverdueReviewerFunc   func(ctx context.Context, assignment models.SLAAssignment, teamID int, pick storage.ReviewerPicker) (models.PullRequest, string, error)
 }
 
 func NewMockRequestStorage() *MockRequestStorage {
 	return &MockRequestStorage{
-		PullRequests: make(map[string]models.PullRequest),
-		PRReviewers:  make(map[string][]string),
+		PullRequests:   make(map[string]models.PullRequest),
+		PRReviewers:    make(map[string][]string),
+		TeamCandidates: make(map[int][]models.ReviewerCandidate),
+		UserTags:       make(map[string][]string),
+		InactiveUsers:  make(map[string]bool),
+		AbsentUsers:    make(map[string]bool),
 	}
 }
 
-func (m *MockRequestStorage) CreatePullRequest(ctx context.Context, pr models.PullRequest, reviewerIDs []string) (models.PullRequest, error) {
+func (m *MockRequestStorage) CreatePullRequest(ctx context.Context, pr models.PullRequest, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error) {
 	if m.CreatePullRequestFunc != nil {
-		return m.CreatePullRequestFunc(ctx, pr, reviewerIDs)
+		return m.CreatePullRequestFunc(ctx, pr, teamID, preferredUserIDs, reviewersCount, pick)
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -209,16 +674,130 @@ func (m *MockRequestStorage) CreatePullRequest(ctx context.Context, pr models.Pu
 		return models.PullRequest{}, errors.New("PR_EXISTS")
 	}
 
-	pr.Status = "OPEN"
-	pr.AssignedReviewers = reviewerIDs
+	if pr.Status == "" {
+		pr.Status = models.StatusOpen
+	}
+	pr.TeamID = teamID
+	pr.AssignedReviewers = []string{}
+	if pr.Status == models.StatusOpen {
+		m.assignReviewers(&pr, teamID, preferredUserIDs, reviewersCount, pick)
+	}
+
 	m.PullRequests[pr.ID] = pr
-	m.PRReviewers[pr.ID] = reviewerIDs
+	m.PRReviewers[pr.ID] = pr.AssignedReviewers
+	return pr, nil
+}
+
+// assignReviewers leaves out the TeamCandidates at capacity and counts the
+// slots they leave empty into MissingReviewers.
+func (m *MockRequestStorage) assignReviewers(pr *models.PullRequest, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) {
+	preferred := make(map[string]bool, len(preferredUserIDs))
+	for _, userID := range preferredUserIDs {
+		preferred[userID] = true
+	}
+
+	var candidates []models.ReviewerCandidate
+	atCapacity := 0
+	for _, candidate := range m.TeamCandidates[teamID] {
+		if candidate.UserID == pr.AuthorID || slices.Contains(pr.ExcludedReviewers, candidate.UserID) {
+			continue
+		}
+		if preferred[candidate.UserID] {
+			candidate.Tier = models.PreferredTier
+			delete(preferred, candidate.UserID)
+		}
+		if candidate.AtCapacity() {
+			atCapacity++
+			continue
+		}
+		candidate.MatchingTags = m.matchingTags(candidate.UserID, pr.Labels)
+		candidates = append(candidates, candidate)
+	}
+	for _, userID := range preferredUserIDs {
+		if preferred[userID] && userID != pr.AuthorID && !slices.Contains(pr.ExcludedReviewers, userID) {
+			candidates = append(candidates, models.ReviewerCandidate{
+				UserID:       userID,
+				Tier:         models.PreferredTier,
+				MatchingTags: m.matchingTags(userID, pr.Labels),
+			})
+		}
+	}
+	reviewerIDs := pick(candidates)
+
+	for _, reviewerID := range reviewerIDs {
+		for _, candidate := range candidates {
+			if candidate.UserID == reviewerID && candidate.Tier > 0 {
+				pr.FallbackReviewers = append(pr.FallbackReviewers, reviewerID)
+			}
+		}
+	}
+
+	pr.AssignedReviewers = reviewerIDs
+	pr.MissingReviewers = max(min(reviewersCount-len(reviewerIDs), atCapacity), 0)
+}
+
+func (m *MockRequestStorage) GetPullRequest(ctx context.Context, pullRequestID string) (models.PullRequest, error) {
+	if m.GetPullRequestFunc != nil {
+		return m.GetPullRequestFunc(ctx, pullRequestID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pr, exists := m.PullRequests[pullRequestID]
+	if !exists {
+		return models.PullRequest{}, errors.New("NOT_FOUND")
+	}
 	return pr, nil
 }
 
-func (m *MockRequestStorage) MergePullRequest(ctx context.Context, pullRequestID string) (models.PullRequest, error) {
+// ListPullRequests filters by status, author, reviewer and understaffing and
+// pages in pull request ID order; other filter fields are ignored.
+func (m *MockRequestStorage) ListPullRequests(ctx context.Context, filter models.PullRequestFilter) (models.PullRequestPage, error) {
+	if m.ListPullRequestsFunc != nil {
+		return m.ListPullRequestsFunc(ctx, filter)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []models.PullRequest
+	for _, pr := range m.PullRequests {
+		if filter.Status != "" && pr.Status != filter.Status {
+			continue
+		}
+		if filter.AuthorID != "" && pr.AuthorID != filter.AuthorID {
+			continue
+		}
+		if filter.ReviewerID != "" && !slices.Contains(pr.AssignedReviewers, filter.ReviewerID) {
+			continue
+		}
+		if filter.TeamID != 0 && pr.TeamID != filter.TeamID {
+			continue
+		}
+		if filter.Understaffed && pr.MissingReviewers == 0 {
+			continue
+		}
+		if filter.After != nil && pr.ID <= filter.After.ID {
+			continue
+		}
+		matched = append(matched, pr)
+	}
+	slices.SortFunc(matched, func(a, b models.PullRequest) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var page models.PullRequestPage
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+		last := matched[len(matched)-1]
+		page.Next = &models.PullRequestCursor{Value: last.ID, ID: last.ID}
+	}
+	page.PullRequests = matched
+	return page, nil
+}
+
+func (m *MockRequestStorage) MergePullRequest(ctx context.Context, pullRequestID string, check storage.MergeCheck) (models.PullRequest, error) {
 	if m.MergePullRequestFunc != nil {
-		return m.MergePullRequestFunc(ctx, pullRequestID)
+		return m.MergePullRequestFunc(ctx, pullRequestID, check)
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -228,14 +807,101 @@ func (m *MockRequestStorage) MergePullRequest(ctx context.Context, pullRequestID
 		return models.PullRequest{}, errors.New("NOT_FOUND")
 	}
 
-	pr.Status = "MERGED"
+	if pr.Status == models.StatusDraft || pr.Status == models.StatusClosed {
+		return models.PullRequest{}, errors.New(models.StatusError(pr.Status))
+	}
+
+	if pr.Status != models.StatusMerged {
+		if err := check(m.mergeState(pr)); err != nil {
+			return models.PullRequest{}, err
+		}
+	}
+
+	pr.Status = models.StatusMerged
 	m.PullRequests[pullRequestID] = pr
 	return pr, nil
 }
 
-func (m *MockRequestStorage) ReassignReviewer(ctx context.Context, pullRequestID string, oldReviewerID string, newReviewerID string) (models.PullRequest, error) {
-	if m.ReassignReviewerFunc != nil {
-		return m.ReassignReviewerFunc(ctx, pullRequestID, oldReviewerID, newReviewerID)
+func (m *MockRequestStorage) GetMergeState(ctx context.Context, pullRequestID string) (models.MergeState, error) {
+	if m.GetMergeStateFunc != nil {
+		return m.GetMergeStateFunc(ctx, pullRequestID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pr, exists := m.PullRequests[pullRequestID]
+	if !exists {
+		return models.MergeState{}, errors.New("NOT_FOUND")
+	}
+	return m.mergeState(pr), nil
+}
+
+func (m *MockRequestStorage) mergeState(pr models.PullRequest) models.MergeState {
+	state := models.MergeState{PullRequest: pr, Now: time.Now()}
+	for _, reviewerID := range pr.AssignedReviewers {
+		state.Reviewers = append(state.Reviewers, models.User{
+			ID:       reviewerID,
+			IsActive: !m.InactiveUsers[reviewerID],
+			Tags:     m.UserTags[reviewerID],
+		})
+	}
+	for _, review := range m.Reviews {
+		if review.PullRequestID == pr.ID && slices.Contains(pr.AssignedReviewers, review.ReviewerID) {
+			state.Reviews = append(state.Reviews, review)
+		}
+	}
+	return state
+}
+
+func (m *MockRequestStorage) AddReview(ctx context.Context, review models.Review) (models.Review, error) {
+	if m.AddReviewFunc != nil {
+		return m.AddReviewFunc(ctx, review)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pr, exists := m.PullRequests[review.PullRequestID]
+	if !exists {
+		return models.Review{}, errors.New("NOT_FOUND")
+	}
+	if pr.Status != models.StatusOpen {
+		return models.Review{}, errors.New(models.StatusError(pr.Status))
+	}
+	if !slices.Contains(pr.AssignedReviewers, review.ReviewerID) {
+		return models.Review{}, errors.New("NOT_ASSIGNED")
+	}
+
+	review.ID = len(m.Reviews) + 1
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now()
+	}
+	m.Reviews = append(m.Reviews, review)
+	return review, nil
+}
+
+func (m *MockRequestStorage) GetReviews(ctx context.Context, pullRequestID string) ([]models.Review, error) {
+	if m.GetReviewsFunc != nil {
+		return m.GetReviewsFunc(ctx, pullRequestID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.PullRequests[pullRequestID]; !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	reviews := []models.Review{}
+	for _, review := range m.Reviews {
+		if review.PullRequestID == pullRequestID {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (m *MockRequestStorage) TransitionPullRequest(ctx context.Context, pullRequestID string, transition models.PullRequestTransition, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error) {
+	if m.TransitionPullRequestFunc != nil {
+		return m.TransitionPullRequestFunc(ctx, pullRequestID, transition, teamID, preferredUserIDs, reviewersCount, pick)
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -245,23 +911,444 @@ func (m *MockRequestStorage) ReassignReviewer(ctx context.Context, pullRequestID
 		return models.PullRequest{}, errors.New("NOT_FOUND")
 	}
 
-	if pr.Status == "MERGED" {
-		return models.PullRequest{}, errors.New("PR_MERGED")
+	if !transition.Allowed(pr.Status) {
+		return models.PullRequest{}, errors.New(models.StatusError(pr.Status))
+	}
+
+	if transition.To == models.StatusOpen && len(pr.AssignedReviewers) == 0 && pick == nil {
+		return models.PullRequest{}, errors.New("PICKER_REQUIRED")
+	}
+
+	pr.Status = transition.To
+	if transition.To == models.StatusOpen && len(pr.AssignedReviewers) == 0 {
+		m.assignReviewers(&pr, teamID, preferredUserIDs, reviewersCount, pick)
+		m.PRReviewers[pr.ID] = pr.AssignedReviewers
+	}
+
+	m.PullRequests[pullRequestID] = pr
+	return pr, nil
+}
+
+func (m *MockRequestStorage) ReassignReviewer(ctx context.Context, pullRequestID string, oldReviewerID string, teamID int, pick storage.ReviewerPicker, reason string) (models.PullRequest, string, error) {
+	if m.ReassignReviewerFunc != nil {
+		return m.ReassignReviewerFunc(ctx, pullRequestID, oldReviewerID, teamID, pick, reason)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pr, exists := m.PullRequests[pullRequestID]
+	if !exists {
+		return models.PullRequest{}, "", errors.New("NOT_FOUND")
+	}
+
+	if pr.Status != models.StatusOpen {
+		return models.PullRequest{}, "", errors.New(models.StatusError(pr.Status))
 	}
 
-	found := false
+	oldIndex := -1
+	excludeMap := map[string]bool{pr.AuthorID: true}
+	for _, id := range pr.ExcludedReviewers {
+		excludeMap[id] = true
+	}
 	for i, id := range pr.AssignedReviewers {
+		excludeMap[id] = true
 		if id == oldReviewerID {
-			pr.AssignedReviewers[i] = newReviewerID
-			found = true
-			break
+			oldIndex = i
 		}
 	}
 
-	if !found {
-		return models.PullRequest{}, errors.New("NOT_ASSIGNED")
+	if oldIndex == -1 {
+		return models.PullRequest{}, "", errors.New("NOT_ASSIGNED")
 	}
 
+	var candidates []models.ReviewerCandidate
+	for _, candidate := range m.TeamCandidates[teamID] {
+		if !excludeMap[candidate.UserID] && !candidate.AtCapacity() {
+			candidate.MatchingTags = m.matchingTags(candidate.UserID, pr.Labels)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	picked := pick(candidates)
+	if len(picked) == 0 {
+		return models.PullRequest{}, "", errors.New("NO_CANDIDATE")
+	}
+
+	reviewers := append([]string(nil), pr.AssignedReviewers...)
+	reviewers[oldIndex] = picked[0]
+	pr.AssignedReviewers = reviewers
 	m.PullRequests[pullRequestID] = pr
-	return pr, nil
+	return pr, picked[0], nil
+}
+
+func (m *MockRequestStorage) ListUnansweredAssignments(ctx context.Context) ([]models.SLAAssignment, time.Time, error) {
+	if m.ListUnansweredAssignmentsFunc != nil {
+		return m.ListUnansweredAssignmentsFunc(ctx)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return append([]models.SLAAssignment{}, m.Overdue...), now, nil
+}
+
+// ReassignOverdueReviewer refuses assignments not in Overdue and otherwise
+// reassigns like ReassignReviewer, keeping the reviewer when nobody is picked.
+func (m *MockRequestStorage) ReassignOverdueReviewer(ctx context.Context, assignment models.SLAAssignment, teamID int, pick storage.ReviewerPicker) (models.PullRequest, string, error) {
+	if m.ReassignOverdueReviewerFunc != nil {
+		return m.ReassignOverdueReviewerFunc(ctx, assignment, teamID, pick)
+	}
+	m.mu.Lock()
+	index := slices.IndexFunc(m.Overdue, func(a models.SLAAssignment) bool {
+		return a.PullRequestID == assignment.PullRequestID && a.ReviewerID == assignment.ReviewerID
+	})
+	if index == -1 {
+		m.mu.Unlock()
+		return models.PullRequest{}, "", errors.New("SLA_NOT_BREACHED")
+	}
+	m.Overdue = slices.Delete(m.Overdue, index, index+1)
+	m.Events = append(m.Events, models.PullRequestEvent{
+		PullRequestID: assignment.PullRequestID,
+		Type:          models.EventSLABreached,
+		ReviewerID:    assignment.ReviewerID,
+	})
+	m.mu.Unlock()
+
+	pr, newReviewerID, err := m.ReassignReviewer(ctx, assignment.PullRequestID, assignment.ReviewerID, teamID, pick, models.EventReasonSLABreach)
+	if err != nil {
+		if err.Error() != "NO_CANDIDATE" {
+			return models.PullRequest{}, "", err
+		}
+		pr, err = m.GetPullRequest(ctx, assignment.PullRequestID)
+		return pr, "", err
+	}
+	return pr, newReviewerID, nil
+}
+
+// AddReviewer treats TeamCandidates of teamID as the allowed reviewers.
+func (m *MockRequestStorage) AddReviewer(ctx context.Context, change models.ReviewerChange, teamID int) (models.PullRequest, models.ReviewerChange, error) {
+	if m.AddReviewerFunc != nil {
+		return m.AddReviewerFunc(ctx, change, teamID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pr, exists := m.PullRequests[change.PullRequestID]
+	if !exists {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_FOUND")
+	}
+	if pr.Status != models.StatusOpen {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New(models.StatusError(pr.Status))
+	}
+	if change.ReviewerID == pr.AuthorID {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_IS_AUTHOR")
+	}
+	if m.InactiveUsers[change.ReviewerID] {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_INACTIVE")
+	}
+	if m.AbsentUsers[change.ReviewerID] {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_UNAVAILABLE")
+	}
+	if !slices.ContainsFunc(m.TeamCandidates[teamID], func(c models.ReviewerCandidate) bool {
+		return c.UserID == change.ReviewerID
+	}) {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_TEAM_MEMBER")
+	}
+	if slices.Contains(pr.AssignedReviewers, change.ReviewerID) {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("ALREADY_ASSIGNED")
+	}
+
+	pr.AssignedReviewers = append(slices.Clone(pr.AssignedReviewers), change.ReviewerID)
+	pr.MissingReviewers = max(pr.MissingReviewers-1, 0)
+	m.PullRequests[pr.ID] = pr
+	m.PRReviewers[pr.ID] = pr.AssignedReviewers
+	return pr, m.recordReviewerChange(change, models.ReviewerAdded), nil
+}
+
+func (m *MockRequestStorage) RemoveReviewer(ctx context.Context, change models.ReviewerChange) (models.PullRequest, models.ReviewerChange, error) {
+	if m.RemoveReviewerFunc != nil {
+		return m.RemoveReviewerFunc(ctx, change)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pr, exists := m.PullRequests[change.PullRequestID]
+	if !exists {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_FOUND")
+	}
+	if pr.Status != models.StatusOpen {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New(models.StatusError(pr.Status))
+	}
+	index := slices.Index(pr.AssignedReviewers, change.ReviewerID)
+	if index == -1 {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_ASSIGNED")
+	}
+
+	pr.AssignedReviewers = slices.Delete(slices.Clone(pr.AssignedReviewers), index, index+1)
+	pr.FallbackReviewers = slices.DeleteFunc(slices.Clone(pr.FallbackReviewers), func(id string) bool {
+		return id == change.ReviewerID
+	})
+	m.PullRequests[pr.ID] = pr
+	m.PRReviewers[pr.ID] = pr.AssignedReviewers
+	return pr, m.recordReviewerChange(change, models.ReviewerRemoved), nil
+}
+
+func (m *MockRequestStorage) UpdatePullRequest(ctx context.Context, update models.PullRequestUpdate, teamID int, pick storage.ReviewerPicker) (models.PullRequestUpdateResult, error) {
+	if m.UpdatePullRequestFunc != nil {
+		return m.UpdatePullRequestFunc(ctx, update, teamID, pick)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pr, exists := m.PullRequests[update.PullRequestID]
+	if !exists {
+		return models.PullRequestUpdateResult{}, errors.New("NOT_FOUND")
+	}
+	if pr.Status == models.StatusMerged {
+		return models.PullRequestUpdateResult{}, errors.New(models.StatusError(pr.Status))
+	}
+
+	var result models.PullRequestUpdateResult
+	change := func(field string, value *string, newValue *string) {
+		if newValue == nil || *newValue == *value {
+			return
+		}
+		m.Changes = append(m.Changes, models.PullRequestChange{
+			ID:            len(m.Changes) + 1,
+			PullRequestID: pr.ID,
+			Field:         field,
+			OldValue:      *value,
+			NewValue:      *newValue,
+			ChangedBy:     update.ChangedBy,
+			ChangedAt:     time.Now(),
+		})
+		result.Changes = append(result.Changes, m.Changes[len(m.Changes)-1])
+		*value = *newValue
+	}
+	change(models.FieldName, &pr.Name, update.Name)
+	change(models.FieldAuthorID, &pr.AuthorID, update.AuthorID)
+
+	if index := slices.Index(pr.AssignedReviewers, pr.AuthorID); index != -1 {
+		result.RemovedReviewer = pr.AuthorID
+		reviewers := slices.Delete(slices.Clone(pr.AssignedReviewers), index, index+1)
+
+		if pr.Status == models.StatusOpen {
+			var candidates []models.ReviewerCandidate
+			for _, candidate := range m.TeamCandidates[teamID] {
+				if !slices.Contains(pr.AssignedReviewers, candidate.UserID) && !slices.Contains(pr.ExcludedReviewers, candidate.UserID) && !candidate.AtCapacity() {
+					candidate.MatchingTags = m.matchingTags(candidate.UserID, pr.Labels)
+					candidates = append(candidates, candidate)
+				}
+			}
+
+			if picked := pick(candidates); len(picked) > 0 {
+				result.ReplacedBy = picked[0]
+				reviewers = append(reviewers, picked[0])
+			}
+		}
+		pr.AssignedReviewers = reviewers
+		m.PRReviewers[pr.ID] = reviewers
+	}
+
+	m.PullRequests[pr.ID] = pr
+	result.PullRequest = pr
+	return result, nil
+}
+
+func (m *MockRequestStorage) GetPullRequestChanges(ctx context.Context, pullRequestID string) ([]models.PullRequestChange, error) {
+	if m.GetPullRequestChangesFunc != nil {
+		return m.GetPullRequestChangesFunc(ctx, pullRequestID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.PullRequests[pullRequestID]; !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	changes := []models.PullRequestChange{}
+	for _, change := range m.Changes {
+		if change.PullRequestID == pullRequestID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (m *MockRequestStorage) GetPullRequestEvents(ctx context.Context, pullRequestID string) ([]models.PullRequestEvent, error) {
+	if m.GetPullRequestEventsFunc != nil {
+		return m.GetPullRequestEventsFunc(ctx, pullRequestID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.PullRequests[pullRequestID]; !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	events := []models.PullRequestEvent{}
+	for _, event := range m.Events {
+		if event.PullRequestID == pullRequestID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *MockRequestStorage) recordReviewerChange(change models.ReviewerChange, action string) models.ReviewerChange {
+	change.ID = len(m.ReviewerChanges) + 1
+	change.Action = action
+	change.CreatedAt = time.Now()
+	m.ReviewerChanges = append(m.ReviewerChanges, change)
+	return change
+}
+
+func (m *MockRequestStorage) matchingTags(userID string, labels []string) int {
+	matching := 0
+	for _, tag := range m.UserTags[userID] {
+		for _, label := range labels {
+			if tag == label {
+				matching++
+			}
+		}
+	}
+	return matching
+}
+
+type MockCodeOwnerStorage struct {
+	mu                        sync.RWMutex
+	Rules                     []models.CodeOwnerRule
+	AddCodeOwnerRuleFunc      func(ctx context.Context, rule models.CodeOwnerRule) (models.CodeOwnerRule, error)
+	ReplaceCodeOwnerRulesFunc func(ctx context.Context, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error)
+	GetCodeOwnerRulesFunc     func(ctx context.Context) ([]models.CodeOwnerRule, error)
+}
+
+func NewMockCodeOwnerStorage() *MockCodeOwnerStorage {
+	return &MockCodeOwnerStorage{
+		Rules: []models.CodeOwnerRule{},
+	}
+}
+
+func (m *MockCodeOwnerStorage) AddCodeOwnerRule(ctx context.Context, rule models.CodeOwnerRule) (models.CodeOwnerRule, error) {
+	if m.AddCodeOwnerRuleFunc != nil {
+		return m.AddCodeOwnerRuleFunc(ctx, rule)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rule.ID = len(m.Rules) + 1
+	m.Rules = append(m.Rules, rule)
+	return rule, nil
+}
+
+func (m *MockCodeOwnerStorage) ReplaceCodeOwnerRules(ctx context.Context, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error) {
+	if m.ReplaceCodeOwnerRulesFunc != nil {
+		return m.ReplaceCodeOwnerRulesFunc(ctx, rules)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Rules = make([]models.CodeOwnerRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.ID = i + 1
+		m.Rules = append(m.Rules, rule)
+	}
+	return m.Rules, nil
+}
+
+func (m *MockCodeOwnerStorage) GetCodeOwnerRules(ctx context.Context) ([]models.CodeOwnerRule, error) {
+	if m.GetCodeOwnerRulesFunc != nil {
+		return m.GetCodeOwnerRulesFunc(ctx)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.Rules, nil
+}
+
+type MockStatsStorage struct {
+	mu                      sync.RWMutex
+	Reviewers               []models.ReviewerStats
+	PullRequests            []models.PullRequestStats
+	GetReviewerStatsFunc    func(ctx context.Context, filter models.StatsFilter) ([]models.ReviewerStats, error)
+	GetPullRequestStatsFunc func(ctx context.Context, filter models.StatsFilter) ([]models.PullRequestStats, error)
+}
+
+func NewMockStatsStorage() *MockStatsStorage {
+	return &MockStatsStorage{
+		Reviewers:    []models.ReviewerStats{},
+		PullRequests: []models.PullRequestStats{},
+	}
+}
+
+func (m *MockStatsStorage) GetReviewerStats(ctx context.Context, filter models.StatsFilter) ([]models.ReviewerStats, error) {
+	if m.GetReviewerStatsFunc != nil {
+		return m.GetReviewerStatsFunc(ctx, filter)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.Reviewers, nil
+}
+
+func (m *MockStatsStorage) GetPullRequestStats(ctx context.Context, filter models.StatsFilter) ([]models.PullRequestStats, error) {
+	if m.GetPullRequestStatsFunc != nil {
+		return m.GetPullRequestStatsFunc(ctx, filter)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.PullRequests, nil
+}
+
+// MockReminderStorage hands out Pending reminders to reviewers not in
+// Reminded, the way a repeat interval that has not yet elapsed would.
+type MockReminderStorage struct {
+	mu                  sync.Mutex
+	Pending             []models.ReviewReminder
+	Reminded            map[string]time.Time
+	ClaimRemindersFunc  func(ctx context.Context, policy models.ReminderPolicy) ([]models.ReviewReminder, error)
+	ReleaseReminderFunc func(ctx context.Context, reminder models.ReviewReminder) error
+}
+
+func NewMockReminderStorage() *MockReminderStorage {
+	return &MockReminderStorage{
+		Reminded: make(map[string]time.Time),
+	}
+}
+
+func (m *MockReminderStorage) ClaimReminders(ctx context.Context, policy models.ReminderPolicy) ([]models.ReviewReminder, error) {
+	if m.ClaimRemindersFunc != nil {
+		return m.ClaimRemindersFunc(ctx, policy)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claimed := []models.ReviewReminder{}
+	now := time.Now()
+	for _, reminder := range m.Pending {
+		if _, reminded := m.Reminded[reminder.ReviewerID]; reminded {
+			continue
+		}
+		reminder.RemindedAt = now
+		m.Reminded[reminder.ReviewerID] = now
+		claimed = append(claimed, reminder)
+	}
+	return claimed, nil
+}
+
+func (m *MockReminderStorage) ReleaseReminder(ctx context.Context, reminder models.ReviewReminder) error {
+	if m.ReleaseReminderFunc != nil {
+		return m.ReleaseReminderFunc(ctx, reminder)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Reminded[reminder.ReviewerID].Equal(reminder.RemindedAt) {
+		delete(m.Reminded, reminder.ReviewerID)
+	}
+	return nil
 }
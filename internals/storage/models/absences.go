@@ -0,0 +1,31 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Absence is an out-of-office window. Reviewer selection and replacement
+// pass over the user from StartsAt until EndsAt, whatever their is_active.
+type Absence struct {
+	ID       int64     `json:"absence_id" db:"id"`
+	UserID   string    `json:"user_id" db:"user_id"`
+	StartsAt time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt   time.Time `json:"ends_at" db:"ends_at"`
+	Reason   string    `json:"reason" db:"reason"`
+	// ReassignReviews hands the OPEN reviews of the user over to others once
+	// the absence starts, and ReviewsReassignedAt records when it was done.
+	// ReviewsClaimedAt is when a run last took the reassignment on.
+	ReassignReviews     bool         `json:"reassign_reviews" db:"reassign_reviews"`
+	ReviewsReassignedAt sql.NullTime `json:"reviews_reassigned_at,omitempty" db:"reviews_reassigned_at"`
+	ReviewsClaimedAt    sql.NullTime `json:"-" db:"reviews_claimed_at"`
+	CreatedAt           time.Time    `json:"created_at" db:"created_at"`
+	// PullRequestIDs are the OPEN pull requests the user reviewed when the
+	// absence was claimed for reassignment.
+	PullRequestIDs []string `json:"-" db:"-"`
+}
+
+// Active reports whether the absence is in progress at t.
+func (a Absence) Active(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
@@ -0,0 +1,10 @@
+package models
+
+// CodeOwnerRule assigns owners to the paths matching a CODEOWNERS-style glob
+// pattern. As in CODEOWNERS, the last matching rule wins.
+type CodeOwnerRule struct {
+	ID      int      `json:"id" db:"id"`
+	Pattern string   `json:"pattern" db:"pattern"`
+	Users   []string `json:"users" db:"-"`
+	Teams   []string `json:"teams" db:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Types of pull request events.
+const (
+	EventCreated            = "CREATED"
+	EventReviewerAssigned   = "REVIEWER_ASSIGNED"
+	EventReviewerReassigned = "REVIEWER_REASSIGNED"
+	EventReviewerRemoved    = "REVIEWER_REMOVED"
+	EventStatusChanged      = "STATUS_CHANGED"
+	EventMerged             = "MERGED"
+	// EventSLABreached marks a reviewer who missed the response deadline.
+	EventSLABreached = "SLA_BREACHED"
+)
+
+// Reasons of reviewer events.
+const (
+	// EventReasonSelection marks reviewers chosen by the team strategy when a
+	// pull request is created or opened.
+	EventReasonSelection     = "SELECTION"
+	EventReasonManual        = "MANUAL"
+	EventReasonReassign      = "REASSIGN"
+	EventReasonDeactivation  = "DEACTIVATION"
+	EventReasonAuthorChanged = "AUTHOR_CHANGED"
+	EventReasonSLABreach     = "SLA_BREACH"
+	EventReasonAbsence       = "ABSENCE"
+)
+
+// PullRequestEvent is one entry of a pull request timeline. Fields that do
+// not apply to the event type are empty. ActorID is set when the request
+// names who made the change.
+type PullRequestEvent struct {
+	ID            int64     `json:"event_id" db:"id"`
+	PullRequestID string    `json:"pull_request_id" db:"pull_request_id"`
+	Type          string    `json:"type" db:"event_type"`
+	ActorID       string    `json:"actor_id,omitempty" db:"actor_id"`
+	ReviewerID    string    `json:"reviewer_id,omitempty" db:"reviewer_id"`
+	OldReviewerID string    `json:"old_reviewer_id,omitempty" db:"old_reviewer_id"`
+	FromStatus    string    `json:"from_status,omitempty" db:"from_status"`
+	ToStatus      string    `json:"to_status,omitempty" db:"to_status"`
+	Reason        string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ActivityFilter selects the events of a user's activity feed, newest first.
+// BeforeID continues a feed behind the last event of a page.
+type ActivityFilter struct {
+	UserID   string
+	BeforeID int64
+	Limit    int
+}
@@ -108,3 +108,39 @@ func TestUser_InactiveUser(t *testing.T) {
 		t.Error("Expected IsActive to be false")
 	}
 }
+
+func TestNormalizeTags(t *testing.T) {
+	tags := NormalizeTags([]string{" Go", "postgres", "go", "", "Frontend "})
+
+	want := []string{"go", "postgres", "frontend"}
+	if len(tags) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, tags)
+	}
+	for i := range want {
+		if tags[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, tags)
+		}
+	}
+}
+
+func TestPullRequestTransition_Allowed(t *testing.T) {
+	tests := []struct {
+		transition PullRequestTransition
+		status     string
+		want       bool
+	}{
+		{TransitionReady, StatusDraft, true},
+		{TransitionReady, StatusOpen, false},
+		{TransitionClose, StatusDraft, true},
+		{TransitionClose, StatusOpen, true},
+		{TransitionClose, StatusMerged, false},
+		{TransitionReopen, StatusClosed, true},
+		{TransitionReopen, StatusMerged, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.transition.Allowed(tt.status); got != tt.want {
+			t.Errorf("%s from %s: expected %v, got %v", tt.transition.To, tt.status, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// MergePolicy is a team's rules for merging pull requests of its members. A
+// zero value disables a rule.
+type MergePolicy struct {
+	MinApprovals int `json:"min_approvals,omitempty"`
+	// SeniorTag requires an approval from a reviewer tagged with it.
+	SeniorTag string `json:"senior_tag,omitempty"`
+	// BlockInactiveReviewers refuses merges while an assigned reviewer is
+	// inactive.
+	BlockInactiveReviewers bool `json:"block_inactive_reviewers,omitempty"`
+	// ChangeRequestCooldownSeconds is how long merges wait after the last
+	// REQUEST_CHANGES.
+	ChangeRequestCooldownSeconds int64 `json:"change_request_cooldown_seconds,omitempty"`
+}
+
+// Scan reads the policy from a JSONB column.
+func (p *MergePolicy) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*p = MergePolicy{}
+		return nil
+	default:
+		return fmt.Errorf("scan merge policy from %T", src)
+	}
+	*p = MergePolicy{}
+	return json.Unmarshal(data, p)
+}
+
+// Value writes the policy to a JSONB column.
+func (p MergePolicy) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -2,15 +2,154 @@ package models
 
 import (
 	"database/sql"
+	"slices"
 	"time"
 )
 
+// Pull request statuses. A pull request starts as DRAFT or OPEN, only OPEN
+// ones have reviewers assigned and can be merged, and MERGED is final.
+const (
+	StatusDraft  = "DRAFT"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// PullRequestTransition moves a pull request to To from any status in From.
+type PullRequestTransition struct {
+	From []string
+	To   string
+}
+
+var (
+	// TransitionReady takes a draft out for review.
+	TransitionReady = PullRequestTransition{From: []string{StatusDraft}, To: StatusOpen}
+	// TransitionClose closes a pull request without merging it.
+	TransitionClose = PullRequestTransition{From: []string{StatusDraft, StatusOpen}, To: StatusClosed}
+	// TransitionReopen brings a closed pull request back to review.
+	TransitionReopen = PullRequestTransition{From: []string{StatusClosed}, To: StatusOpen}
+)
+
+// Allowed reports whether the transition may start from status.
+func (t PullRequestTransition) Allowed(status string) bool {
+	return slices.Contains(t.From, status)
+}
+
+// StatusError is the error code of an operation refused because the pull
+// request is in status, e.g. PR_MERGED.
+func StatusError(status string) string {
+	return "PR_" + status
+}
+
 type PullRequest struct {
-	ID                string       `json:"pull_request_id" db:"pull_request_id"`
-	Name              string       `json:"pull_request_name" db:"name"`
-	AuthorID          string       `json:"author_id" db:"author_id"`
-	Status            string       `json:"status" db:"status"`
-	AssignedReviewers []string     `json:"assigned_reviewers" db:"-"`
-	MergedAt          sql.NullTime `json:"merged_at,omitempty" db:"merged_at"`
-	CreatedAt         time.Time    `json:"created_at" db:"created_at"`
+	ID                string   `json:"pull_request_id" db:"pull_request_id"`
+	Name              string   `json:"pull_request_name" db:"name"`
+	AuthorID          string   `json:"author_id" db:"author_id"`
+	Status            string   `json:"status" db:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers" db:"-"`
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty" db:"-"`
+	Labels            []string `json:"labels,omitempty" db:"-"`
+	// PreferredReviewers and ExcludedReviewers are the author's reviewer
+	// preferences given at creation. Exclusions hold for every assignment;
+	// preferences are applied when reviewers are selected for the first time.
+	PreferredReviewers []string `json:"preferred_reviewers,omitempty" db:"-"`
+	ExcludedReviewers  []string `json:"excluded_reviewers,omitempty" db:"-"`
+	// TeamID is the team reviewers are drawn from; zero means the author's
+	// primary team.
+	TeamID   int    `json:"-" db:"team_id"`
+	TeamName string `json:"team_name,omitempty" db:"team_name"`
+	// SelectionSeed seeded the reviewer selection, so it can be replayed.
+	SelectionSeed string `json:"selection_seed,omitempty" db:"selection_seed"`
+	// MissingReviewers flags a pull request assigned fewer reviewers than it
+	// needs because the other candidates were at capacity. Adding a reviewer
+	// by hand fills one of the slots.
+	MissingReviewers int          `json:"missing_reviewers,omitempty" db:"missing_reviewers"`
+	MergedAt         sql.NullTime `json:"merged_at,omitempty" db:"merged_at"`
+	ClosedAt         sql.NullTime `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+}
+
+// ReviewerReassignment reports what happened to one reviewer slot when its
+// reviewer was taken off a pull request.
+type ReviewerReassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+	Status        string `json:"status"`
+}
+
+// Sort fields of a pull request listing.
+const (
+	SortByCreatedAt = "created_at"
+	SortByMergedAt  = "merged_at"
+	SortByName      = "name"
+	SortByID        = "pull_request_id"
+)
+
+// PullRequestFilter narrows and orders a pull request listing. Zero values
+// disable a filter. Date ranges include From and exclude To.
+type PullRequestFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamID      int
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	MergedFrom  sql.NullTime
+	MergedTo    sql.NullTime
+	SortBy      string
+	Descending  bool
+	Limit       int
+	// After continues the listing behind the last pull request of a page.
+	After *PullRequestCursor
+	// Understaffed keeps pull requests with MissingReviewers only.
+	Understaffed bool
+}
+
+// PullRequestCursor is the sort key of the last pull request of a page.
+type PullRequestCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+type PullRequestPage struct {
+	PullRequests []PullRequest
+	// Next is nil on the last page.
+	Next *PullRequestCursor
+}
+
+// Fields of a pull request recorded in its change history.
+const (
+	FieldName     = "pull_request_name"
+	FieldAuthorID = "author_id"
+)
+
+// PullRequestUpdate changes the fields that are not nil.
+type PullRequestUpdate struct {
+	PullRequestID string
+	Name          *string
+	AuthorID      *string
+	ChangedBy     string
+}
+
+// PullRequestChange is one field change of a pull request. Replaying the
+// changes over the current values gives the pull request at any moment.
+type PullRequestChange struct {
+	ID            int       `json:"change_id" db:"id"`
+	PullRequestID string    `json:"pull_request_id" db:"pull_request_id"`
+	Field         string    `json:"field" db:"field"`
+	OldValue      string    `json:"old_value" db:"old_value"`
+	NewValue      string    `json:"new_value" db:"new_value"`
+	ChangedBy     string    `json:"changed_by" db:"changed_by"`
+	ChangedAt     time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// PullRequestUpdateResult is a pull request after an update with the changes
+// it made. When the new author was a reviewer, RemovedReviewer is the author
+// and ReplacedBy the reviewer who took their place, if any.
+type PullRequestUpdateResult struct {
+	PullRequest     PullRequest
+	Changes         []PullRequestChange
+	RemovedReviewer string
+	ReplacedBy      string
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ReviewReminder tells a reviewer about the reviews they have left pending
+// for too long.
+type ReviewReminder struct {
+	ReviewerID       string    `json:"reviewer_id" db:"reviewer_id"`
+	PendingCount     int       `json:"pending_count" db:"pending_count"`
+	PullRequestIDs   []string  `json:"pull_request_ids" db:"-"`
+	OldestAssignedAt time.Time `json:"oldest_assigned_at" db:"oldest_assigned_at"`
+	RemindedAt       time.Time `json:"reminded_at" db:"reminded_at"`
+	Message          string    `json:"message" db:"-"`
+}
+
+// ReminderPolicy decides which reviews are stale and how often a reviewer is
+// reminded about them.
+type ReminderPolicy struct {
+	StaleAfter  time.Duration
+	RepeatAfter time.Duration
+}
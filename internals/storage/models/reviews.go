@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Review decisions. COMMENT leaves the reviewer's previous verdict in place.
+const (
+	DecisionApprove        = "APPROVE"
+	DecisionRequestChanges = "REQUEST_CHANGES"
+	DecisionComment        = "COMMENT"
+)
+
+type Review struct {
+	ID            int       `json:"review_id" db:"id"`
+	PullRequestID string    `json:"pull_request_id" db:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id" db:"reviewer_id"`
+	Decision      string    `json:"decision" db:"decision"`
+	Comment       string    `json:"comment,omitempty" db:"comment"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Manual reviewer change actions.
+const (
+	ReviewerAdded   = "ADD"
+	ReviewerRemoved = "REMOVE"
+)
+
+// ReviewerChange records a reviewer added to or removed from a pull request
+// by hand, and who did it.
+type ReviewerChange struct {
+	ID            int       `json:"change_id" db:"id"`
+	PullRequestID string    `json:"pull_request_id" db:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id" db:"reviewer_id"`
+	Action        string    `json:"action" db:"action"`
+	PerformedBy   string    `json:"performed_by" db:"performed_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// MergeState is what a merge is checked against: the pull request, its
+// assigned reviewers and their reviews, oldest first.
+type MergeState struct {
+	PullRequest PullRequest
+	Reviewers   []User
+	Reviews     []Review
+	// Now is the database clock, which review times are comparable with.
+	Now time.Time
+}
+
+// Verdicts returns the latest APPROVE or REQUEST_CHANGES of each reviewer.
+func (s MergeState) Verdicts() map[string]string {
+	verdicts := make(map[string]string)
+	for _, review := range s.Reviews {
+		if review.Decision != DecisionComment {
+			verdicts[review.ReviewerID] = review.Decision
+		}
+	}
+	return verdicts
+}
+
+// Approvals returns the assigned reviewers whose verdict is APPROVE.
+func (s MergeState) Approvals() []string {
+	return s.reviewersWithVerdict(DecisionApprove)
+}
+
+// ChangesRequested returns the assigned reviewers whose verdict is
+// REQUEST_CHANGES.
+func (s MergeState) ChangesRequested() []string {
+	return s.reviewersWithVerdict(DecisionRequestChanges)
+}
+
+func (s MergeState) reviewersWithVerdict(decision string) []string {
+	verdicts := s.Verdicts()
+	reviewers := []string{}
+	for _, reviewerID := range s.PullRequest.AssignedReviewers {
+		if verdicts[reviewerID] == decision {
+			reviewers = append(reviewers, reviewerID)
+		}
+	}
+	return reviewers
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReviewSLA is how soon reviewers of a team's pull requests must respond.
+// ResponseHours of zero disables the SLA.
+type ReviewSLA struct {
+	ResponseHours int `json:"response_hours,omitempty"`
+	// WorkingHoursOnly counts only WorkdayStart..WorkdayEnd on weekdays in
+	// Timezone towards ResponseHours.
+	WorkingHoursOnly bool   `json:"working_hours_only,omitempty"`
+	WorkdayStart     int    `json:"workday_start,omitempty"`
+	WorkdayEnd       int    `json:"workday_end,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+}
+
+// Scan reads the SLA from a JSONB column.
+func (s *ReviewSLA) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*s = ReviewSLA{}
+		return nil
+	default:
+		return fmt.Errorf("scan review SLA from %T", src)
+	}
+	*s = ReviewSLA{}
+	return json.Unmarshal(data, s)
+}
+
+// Value writes the SLA to a JSONB column.
+func (s ReviewSLA) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// SLAAssignment is a reviewer of an OPEN pull request who has not responded
+// yet, with the SLA of the author's team.
+type SLAAssignment struct {
+	PullRequestID string    `db:"pull_request_id"`
+	ReviewerID    string    `db:"reviewer_id"`
+	AssignedAt    time.Time `db:"assigned_at"`
+	SLA           ReviewSLA `db:"review_sla"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StatsFilter narrows statistics to one team and a time window. A zero TeamID
+// means all teams; From is inclusive and To is exclusive.
+type StatsFilter struct {
+	TeamID int
+	From   sql.NullTime
+	To     sql.NullTime
+}
+
+// ReviewerStats counts the review assignments of one user made within the
+// filtered window.
+type ReviewerStats struct {
+	UserID           string `json:"user_id" db:"user_id"`
+	Username         string `json:"username" db:"username"`
+	TotalAssignments int    `json:"total_assignments" db:"total_assignments"`
+	OpenAssignments  int    `json:"open_assignments" db:"open_assignments"`
+	MergedReviews    int    `json:"merged_reviews" db:"merged_reviews"`
+}
+
+type PullRequestStats struct {
+	ID             string       `json:"pull_request_id" db:"pull_request_id"`
+	Name           string       `json:"pull_request_name" db:"name"`
+	AuthorID       string       `json:"author_id" db:"author_id"`
+	Status         string       `json:"status" db:"status"`
+	ReviewersCount int          `json:"reviewers_count" db:"reviewers_count"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	MergedAt       sql.NullTime `json:"merged_at,omitempty" db:"merged_at"`
+	// TimeToMergeSeconds is nil until the pull request is merged.
+	TimeToMergeSeconds *int64 `json:"time_to_merge_seconds,omitempty" db:"-"`
+}
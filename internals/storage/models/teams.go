@@ -1,7 +1,38 @@
 package models
 
 type Team struct {
-	ID      int    `json:"id" db:"id"`
-	Name    string `json:"name" db:"name"`
-	Members []User `json:"members" db:"-"`
+	ID       int          `json:"id" db:"id"`
+	Name     string       `json:"name" db:"name"`
+	Members  []User       `json:"members" db:"-"`
+	Settings TeamSettings `json:"settings" db:"-"`
+}
+
+type TeamSettings struct {
+	ReviewerStrategy  string      `json:"reviewer_strategy" db:"reviewer_strategy"`
+	RequiredReviewers int         `json:"required_reviewers" db:"required_reviewers"`
+	MinReviewers      int         `json:"min_reviewers" db:"min_reviewers"`
+	MaxReviewers      int         `json:"max_reviewers" db:"max_reviewers"`
+	MergePolicy       MergePolicy `json:"merge_policy" db:"merge_policy"`
+	ReviewSLA         ReviewSLA   `json:"review_sla" db:"review_sla"`
+	// MaxOpenReviews is the review capacity of members whose primary team
+	// this is and who have no limit of their own; 0 means no limit.
+	MaxOpenReviews int `json:"max_open_reviews" db:"max_open_reviews"`
+	// FallbackTeams are names of teams that top up reviewers, in priority order.
+	FallbackTeams []string `json:"fallback_teams" db:"-"`
+}
+
+// DefaultTeamSettings mirrors the column defaults of the teams table.
+func DefaultTeamSettings() TeamSettings {
+	return TeamSettings{
+		ReviewerStrategy:  "least_loaded",
+		RequiredReviewers: 2,
+		MinReviewers:      1,
+		MaxReviewers:      5,
+		FallbackTeams:     []string{},
+	}
+}
+
+// ReviewersCountAllowed reports whether count fits the team's reviewer bounds.
+func (s TeamSettings) ReviewersCountAllowed(count int) bool {
+	return count >= s.MinReviewers && count <= s.MaxReviewers
 }
@@ -1,7 +1,102 @@
 package models
 
+import (
+	"database/sql"
+	"strings"
+)
+
 type User struct {
 	ID       string `json:"user_id" db:"user_id"`
 	Username string `json:"username" db:"username"`
 	IsActive bool   `json:"is_active" db:"is_active"`
+	// Tags are skills such as go or frontend, matched against PR labels.
+	Tags []string `json:"tags,omitempty" db:"-"`
+}
+
+// TeamMembership is one of the teams a user belongs to.
+type TeamMembership struct {
+	TeamName  string `json:"team_name" db:"team_name"`
+	IsPrimary bool   `json:"is_primary" db:"is_primary"`
+}
+
+// UserProfile is a user with all their teams, TeamName being the primary one.
+type UserProfile struct {
+	User
+	TeamName       string           `json:"team_name" db:"-"`
+	Teams          []TeamMembership `json:"teams" db:"-"`
+	ReviewCapacity ReviewCapacity   `json:"review_capacity" db:"-"`
+}
+
+// ReviewCapacity is how many OPEN pull requests a user reviews and how many
+// they may review at once.
+type ReviewCapacity struct {
+	// MaxOpenReviews is the user's own limit; nil falls back to the default
+	// of their primary team.
+	MaxOpenReviews *int `json:"max_open_reviews" db:"max_open_reviews"`
+	// Limit is the limit in effect, 0 meaning none.
+	Limit       int `json:"limit" db:"review_limit"`
+	OpenReviews int `json:"open_reviews" db:"open_reviews"`
+}
+
+// Full reports whether the user may not take another review.
+func (c ReviewCapacity) Full() bool {
+	return c.Limit > 0 && c.OpenReviews >= c.Limit
+}
+
+type UserFilter struct {
+	// Search matches usernames containing it, ignoring case.
+	Search   string
+	TeamID   int
+	IsActive *bool
+	Limit    int
+	// After continues the listing behind the user with this ID.
+	After string
+}
+
+type UserPage struct {
+	Users []UserProfile
+	// NextAfter is empty on the last page.
+	NextAfter string
+}
+
+// ReviewerCandidate is an active team member together with the review load
+// that selection strategies rank by.
+type ReviewerCandidate struct {
+	UserID         string       `json:"user_id" db:"user_id"`
+	OpenReviews    int          `json:"open_reviews" db:"open_reviews"`
+	LastAssignedAt sql.NullTime `json:"last_assigned_at" db:"last_assigned_at"`
+	// Tier is 0 for members of the author's team and the fallback position
+	// for members of fallback teams. Preferred reviewers, such as code owners
+	// of the changed paths, have PreferredTier.
+	Tier int `json:"tier" db:"tier"`
+	// MatchingTags is the number of the candidate's tags found among the
+	// labels of the pull request.
+	MatchingTags int `json:"matching_tags" db:"matching_tags"`
+	// ReviewLimit is the candidate's review capacity, 0 meaning no limit.
+	ReviewLimit int `json:"review_limit" db:"review_limit"`
+}
+
+// AtCapacity reports whether the candidate may not take another review.
+// Such candidates are never picked.
+func (c ReviewerCandidate) AtCapacity() bool {
+	return c.ReviewLimit > 0 && c.OpenReviews >= c.ReviewLimit
+}
+
+// PreferredTier ranks preferred reviewers ahead of the author's team.
+const PreferredTier = -1
+
+// NormalizeTags lowercases and trims tags and labels, dropping empty and
+// duplicate ones.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
 }
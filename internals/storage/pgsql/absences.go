@@ -0,0 +1,189 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+const absenceColumns = `id, user_id, starts_at::TIMESTAMPTZ AS starts_at, ends_at::TIMESTAMPTZ AS ends_at, reason,
+		reassign_reviews, reviews_reassigned_at::TIMESTAMPTZ AS reviews_reassigned_at,
+		reviews_claimed_at::TIMESTAMPTZ AS reviews_claimed_at, created_at::TIMESTAMPTZ AS created_at`
+
+// absenceClaimTimeout is how long a claimed absence waits for its claimer to
+// finish before another run may claim it again.
+const absenceClaimTimeout = `INTERVAL '15 minutes'`
+
+func (p *PGUserStorage) AddAbsence(ctx context.Context, absence models.Absence) (models.Absence, error) {
+	slog.Debug("Adding absence in PG", "userID", absence.UserID, "startsAt", absence.StartsAt, "endsAt", absence.EndsAt)
+
+	var created models.Absence
+	err := p.DB.GetContext(ctx, &created, `
+		INSERT INTO user_absences (user_id, starts_at, ends_at, reason, reassign_reviews)
+		SELECT user_id, $2, $3, $4, $5 FROM users WHERE user_id = $1
+		RETURNING `+absenceColumns,
+		absence.UserID, absence.StartsAt.UTC(), absence.EndsAt.UTC(), absence.Reason, absence.ReassignReviews)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Absence{}, errors.New("NOT_FOUND")
+		}
+		slog.Error("SQL add absence error", "err", err)
+		return models.Absence{}, fmt.Errorf("failed to add absence: %w", err)
+	}
+	return created, nil
+}
+
+func (p *PGUserStorage) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	slog.Debug("Listing absences in PG", "userID", userID)
+
+	var exists bool
+	err := p.DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", userID)
+	if err != nil {
+		return nil, fmt.Errorf("check user exists: %w", err)
+	}
+	if !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	absences := []models.Absence{}
+	err = p.DB.SelectContext(ctx, &absences, `
+		SELECT `+absenceColumns+`
+		FROM user_absences
+		WHERE user_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at, id
+	`, userID)
+	if err != nil {
+		slog.Error("SQL list absences error", "err", err)
+		return nil, fmt.Errorf("failed to list absences: %w", err)
+	}
+	return absences, nil
+}
+
+func (p *PGUserStorage) DeleteAbsence(ctx context.Context, userID string, absenceID int64) error {
+	slog.Debug("Deleting absence in PG", "userID", userID, "absenceID", absenceID)
+
+	result, err := p.DB.ExecContext(ctx, "DELETE FROM user_absences WHERE id = $1 AND user_id = $2", absenceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.New("NOT_FOUND")
+	}
+	return nil
+}
+
+func (p *PGUserStorage) IsAbsent(ctx context.Context, userID string) (bool, error) {
+	var absent bool
+	err := p.DB.GetContext(ctx, &absent, `
+		SELECT EXISTS(
+			SELECT 1 FROM user_absences
+			WHERE user_id = $1 AND starts_at <= NOW() AND ends_at > NOW())
+	`, userID)
+	if err != nil {
+		return false, fmt.Errorf("check absence: %w", err)
+	}
+	return absent, nil
+}
+
+// ClaimStartedAbsences marks the absences it returns as claimed in the same
+// statement that selects them; SKIP LOCKED keeps replicas claiming at the
+// same time from waiting on each other.
+func (p *PGUserStorage) ClaimStartedAbsences(ctx context.Context) ([]models.Absence, error) {
+	slog.Debug("Claiming started absences in PG")
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	absences := []models.Absence{}
+	err = tx.SelectContext(ctx, &absences, `
+		UPDATE user_absences SET reviews_claimed_at = NOW()
+		WHERE id IN (
+			SELECT id FROM user_absences
+			WHERE reassign_reviews
+			  AND reviews_reassigned_at IS NULL
+			  AND (reviews_claimed_at IS NULL OR reviews_claimed_at < NOW() - `+absenceClaimTimeout+`)
+			  AND starts_at <= NOW() AND ends_at > NOW()
+			FOR UPDATE SKIP LOCKED)
+		RETURNING `+absenceColumns)
+	if err != nil {
+		slog.Error("SQL claim absences error", "err", err)
+		return nil, fmt.Errorf("failed to claim absences: %w", err)
+	}
+	if len(absences) == 0 {
+		return absences, nil
+	}
+
+	userIDs := make([]string, 0, len(absences))
+	for _, absence := range absences {
+		userIDs = append(userIDs, absence.UserID)
+	}
+
+	var rows []struct {
+		ReviewerID    string `db:"reviewer_id"`
+		PullRequestID string `db:"pull_request_id"`
+	}
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT prr.reviewer_id, pr.pull_request_id
+		FROM pull_request_reviewers prr
+		INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		WHERE prr.reviewer_id = ANY($1) AND pr.status = 'OPEN'
+		ORDER BY pr.created_at, pr.pull_request_id
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get open reviews: %w", err)
+	}
+
+	pullRequestIDs := make(map[string][]string, len(absences))
+	for _, row := range rows {
+		pullRequestIDs[row.ReviewerID] = append(pullRequestIDs[row.ReviewerID], row.PullRequestID)
+	}
+	for i := range absences {
+		absences[i].PullRequestIDs = pullRequestIDs[absences[i].UserID]
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return absences, nil
+}
+
+// CompleteAbsence leaves the absence alone when a later run claimed it in
+// the meantime.
+func (p *PGUserStorage) CompleteAbsence(ctx context.Context, absence models.Absence) error {
+	slog.Debug("Completing absence in PG", "absenceID", absence.ID)
+
+	_, err := p.DB.ExecContext(ctx, `
+		UPDATE user_absences SET reviews_reassigned_at = NOW()
+		WHERE id = $1 AND reviews_claimed_at::TIMESTAMPTZ = $2
+	`, absence.ID, absence.ReviewsClaimedAt)
+	if err != nil {
+		return fmt.Errorf("complete absence: %w", err)
+	}
+	return nil
+}
+
+// ReleaseAbsence leaves later claims of the absence in place.
+func (p *PGUserStorage) ReleaseAbsence(ctx context.Context, absence models.Absence) error {
+	slog.Debug("Releasing absence in PG", "absenceID", absence.ID)
+
+	_, err := p.DB.ExecContext(ctx, `
+		UPDATE user_absences SET reviews_claimed_at = NULL
+		WHERE id = $1 AND reviews_claimed_at::TIMESTAMPTZ = $2
+	`, absence.ID, absence.ReviewsClaimedAt)
+	if err != nil {
+		return fmt.Errorf("release absence: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type PGCodeOwnerStorage struct {
+	DB *sqlx.DB
+}
+
+func (p *PGCodeOwnerStorage) AddCodeOwnerRule(ctx context.Context, rule models.CodeOwnerRule) (models.CodeOwnerRule, error) {
+	slog.Debug("Adding code owner rule in PG", "pattern", rule.Pattern)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.CodeOwnerRule{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rule, err = insertCodeOwnerRule(ctx, tx, rule)
+	if err != nil {
+		return models.CodeOwnerRule{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.CodeOwnerRule{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (p *PGCodeOwnerStorage) ReplaceCodeOwnerRules(ctx context.Context, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error) {
+	slog.Debug("Replacing code owner rules in PG", "count", len(rules))
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM code_owner_rules"); err != nil {
+		return nil, fmt.Errorf("delete code owner rules: %w", err)
+	}
+
+	inserted := make([]models.CodeOwnerRule, 0, len(rules))
+	for _, rule := range rules {
+		rule, err = insertCodeOwnerRule(ctx, tx, rule)
+		if err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, rule)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return inserted, nil
+}
+
+func (p *PGCodeOwnerStorage) GetCodeOwnerRules(ctx context.Context) ([]models.CodeOwnerRule, error) {
+	slog.Debug("Getting code owner rules in PG")
+
+	rules := []models.CodeOwnerRule{}
+	err := p.DB.SelectContext(ctx, &rules, "SELECT id, pattern FROM code_owner_rules ORDER BY id")
+	if err != nil {
+		slog.Error("SQL get code owner rules error", "err", err)
+		return nil, fmt.Errorf("failed to get code owner rules: %w", err)
+	}
+
+	var owners []struct {
+		RuleID   int     `db:"rule_id"`
+		UserID   *string `db:"user_id"`
+		TeamName *string `db:"team_name"`
+	}
+	err = p.DB.SelectContext(ctx, &owners, `
+		SELECT o.rule_id, o.user_id, t.name AS team_name
+		FROM code_owner_rule_owners o
+		LEFT JOIN teams t ON t.id = o.team_id
+		ORDER BY o.rule_id, o.user_id, t.name
+	`)
+	if err != nil {
+		slog.Error("SQL get code owners error", "err", err)
+		return nil, fmt.Errorf("failed to get code owners: %w", err)
+	}
+
+	byID := make(map[int]*models.CodeOwnerRule, len(rules))
+	for i := range rules {
+		rules[i].Users = []string{}
+		rules[i].Teams = []string{}
+		byID[rules[i].ID] = &rules[i]
+	}
+	for _, owner := range owners {
+		rule := byID[owner.RuleID]
+		if owner.UserID != nil {
+			rule.Users = append(rule.Users, *owner.UserID)
+		} else if owner.TeamName != nil {
+			rule.Teams = append(rule.Teams, *owner.TeamName)
+		}
+	}
+
+	return rules, nil
+}
+
+// insertCodeOwnerRule appends rule after the existing ones. Unknown users or
+// teams fail with OWNER_NOT_FOUND.
+func insertCodeOwnerRule(ctx context.Context, tx *sqlx.Tx, rule models.CodeOwnerRule) (models.CodeOwnerRule, error) {
+	err := tx.GetContext(ctx, &rule.ID, "INSERT INTO code_owner_rules (pattern) VALUES ($1) RETURNING id", rule.Pattern)
+	if err != nil {
+		return models.CodeOwnerRule{}, fmt.Errorf("insert code owner rule: %w", err)
+	}
+
+	for _, userID := range rule.Users {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO code_owner_rule_owners (rule_id, user_id)
+			SELECT $1, user_id FROM users WHERE user_id = $2
+		`, rule.ID, userID)
+		if err != nil {
+			return models.CodeOwnerRule{}, fmt.Errorf("insert code owner %s: %w", userID, err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return models.CodeOwnerRule{}, errors.New("OWNER_NOT_FOUND")
+		}
+	}
+
+	for _, teamName := range rule.Teams {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO code_owner_rule_owners (rule_id, team_id)
+			SELECT $1, id FROM teams WHERE name = $2
+		`, rule.ID, teamName)
+		if err != nil {
+			return models.CodeOwnerRule{}, fmt.Errorf("insert code owner team %s: %w", teamName, err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return models.CodeOwnerRule{}, errors.New("OWNER_NOT_FOUND")
+		}
+	}
+
+	return rule, nil
+}
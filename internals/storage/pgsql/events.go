@@ -0,0 +1,128 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// pullRequestEvent is an event about to be written for the pull request
+// with the database id PRDBID.
+type pullRequestEvent struct {
+	PRDBID int
+	models.PullRequestEvent
+}
+
+const pullRequestEventColumns = `e.id, pr.pull_request_id, e.event_type,
+			COALESCE(e.actor_id, '') AS actor_id,
+			COALESCE(e.reviewer_id, '') AS reviewer_id,
+			COALESCE(e.old_reviewer_id, '') AS old_reviewer_id,
+			COALESCE(e.from_status, '') AS from_status,
+			COALESCE(e.to_status, '') AS to_status,
+			COALESCE(e.reason, '') AS reason,
+			e.created_at`
+
+// insertEvents writes events with one query, so callers can record them in
+// the transaction that made the changes.
+func insertEvents(ctx context.Context, tx *sqlx.Tx, events []pullRequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	columns := make([][]string, 7)
+	prDBIDs := make([]int, 0, len(events))
+	for _, e := range events {
+		prDBIDs = append(prDBIDs, e.PRDBID)
+		for i, value := range []string{e.Type, e.ActorID, e.ReviewerID, e.OldReviewerID, e.FromStatus, e.ToStatus, e.Reason} {
+			columns[i] = append(columns[i], value)
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO pull_request_events
+			(pull_request_id, event_type, actor_id, reviewer_id, old_reviewer_id, from_status, to_status, reason)
+		SELECT e.pull_request_id, e.event_type, NULLIF(e.actor_id, ''), NULLIF(e.reviewer_id, ''),
+			NULLIF(e.old_reviewer_id, ''), NULLIF(e.from_status, ''), NULLIF(e.to_status, ''), NULLIF(e.reason, '')
+		FROM unnest($1::INTEGER[], $2::VARCHAR[], $3::VARCHAR[], $4::VARCHAR[], $5::VARCHAR[], $6::VARCHAR[], $7::VARCHAR[], $8::VARCHAR[])
+			AS e(pull_request_id, event_type, actor_id, reviewer_id, old_reviewer_id, from_status, to_status, reason)
+	`, prDBIDs, columns[0], columns[1], columns[2], columns[3], columns[4], columns[5], columns[6])
+	if err != nil {
+		return fmt.Errorf("insert pull request events: %w", err)
+	}
+	return nil
+}
+
+// assignedEvents describes reviewers assigned to a pull request for reason.
+func assignedEvents(prDBID int, reviewerIDs []string, actorID, reason string) []pullRequestEvent {
+	events := make([]pullRequestEvent, 0, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		events = append(events, pullRequestEvent{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+			Type:       models.EventReviewerAssigned,
+			ActorID:    actorID,
+			ReviewerID: reviewerID,
+			Reason:     reason,
+		}})
+	}
+	return events
+}
+
+func (p *PGPullRequestStorage) GetPullRequestEvents(ctx context.Context, pullRequestID string) ([]models.PullRequestEvent, error) {
+	slog.Debug("Getting pull request events in PG", "prID", pullRequestID)
+
+	var prDBID int
+	err := p.DB.GetContext(ctx, &prDBID, "SELECT id FROM pull_requests WHERE pull_request_id = $1", pullRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("NOT_FOUND")
+		}
+		return nil, fmt.Errorf("get pull request: %w", err)
+	}
+
+	events := []models.PullRequestEvent{}
+	err = p.DB.SelectContext(ctx, &events, `
+		SELECT `+pullRequestEventColumns+`
+		FROM pull_request_events e
+		INNER JOIN pull_requests pr ON pr.id = e.pull_request_id
+		WHERE e.pull_request_id = $1
+		ORDER BY e.id
+	`, prDBID)
+	if err != nil {
+		return nil, fmt.Errorf("get pull request events: %w", err)
+	}
+	return events, nil
+}
+
+// GetUserActivity returns the events a user made, or was assigned or
+// replaced in, newest first.
+func (p *PGUserStorage) GetUserActivity(ctx context.Context, filter models.ActivityFilter) ([]models.PullRequestEvent, error) {
+	slog.Debug("Getting user activity in PG", "userID", filter.UserID, "beforeID", filter.BeforeID)
+
+	var exists bool
+	err := p.DB.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("check user exists: %w", err)
+	}
+	if !exists {
+		return nil, errors.New("NOT_FOUND")
+	}
+
+	events := []models.PullRequestEvent{}
+	err = p.DB.SelectContext(ctx, &events, `
+		SELECT `+pullRequestEventColumns+`
+		FROM pull_request_events e
+		INNER JOIN pull_requests pr ON pr.id = e.pull_request_id
+		WHERE (e.actor_id = $1 OR e.reviewer_id = $1 OR e.old_reviewer_id = $1)
+			AND ($2::BIGINT = 0 OR e.id < $2::BIGINT)
+		ORDER BY e.id DESC
+		LIMIT $3
+	`, filter.UserID, filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("get user activity: %w", err)
+	}
+	return events, nil
+}
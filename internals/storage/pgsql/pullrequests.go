@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
 
@@ -16,8 +19,8 @@ type PGPullRequestStorage struct {
 	DB *sqlx.DB
 }
 
-func (p *PGPullRequestStorage) CreatePullRequest(ctx context.Context, pr models.PullRequest, reviewerIDs []string) (models.PullRequest, error) {
-	slog.Debug("Creating pull request in PG", "prID", pr.ID, "authorID", pr.AuthorID, "reviewers", reviewerIDs)
+func (p *PGPullRequestStorage) CreatePullRequest(ctx context.Context, pr models.PullRequest, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error) {
+	slog.Debug("Creating pull request in PG", "prID", pr.ID, "authorID", pr.AuthorID, "teamID", teamID)
 
 	tx, err := p.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -34,37 +37,237 @@ func (p *PGPullRequestStorage) CreatePullRequest(ctx context.Context, pr models.
 		return models.PullRequest{}, errors.New("PR_EXISTS")
 	}
 
+	if pr.Status == "" {
+		pr.Status = models.StatusOpen
+	}
+
 	var prDBID int
 	err = tx.QueryRowContext(ctx, `
-  INSERT INTO pull_requests (pull_request_id, name, author_id, status)
-  VALUES ($1, $2, $3, 'OPEN')
-  RETURNING id
- `, pr.ID, pr.Name, pr.AuthorID).Scan(&prDBID)
+  INSERT INTO pull_requests (pull_request_id, name, author_id, status, selection_seed, team_id)
+  VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))
+  RETURNING id, COALESCE((SELECT name FROM teams WHERE id = $6), '')
+ `, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.SelectionSeed, teamID).Scan(&prDBID, &pr.TeamName)
 	if err != nil {
 		return models.PullRequest{}, fmt.Errorf("insert pull request: %w", err)
 	}
 
-	for _, reviewerID := range reviewerIDs {
-		_, err = tx.ExecContext(ctx, `
-   INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id)
-   VALUES ($1, $2)
-  `, prDBID, reviewerID)
+	_, err = tx.ExecContext(ctx, `
+  INSERT INTO pull_request_labels (pull_request_id, label)
+  SELECT $1, unnest($2::VARCHAR[])
+ `, prDBID, nonNil(pr.Labels))
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("insert labels: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pull_request_reviewer_preferences (pull_request_id, user_id, kind, position)
+		SELECT $1::INTEGER, p.user_id, 'PREFERRED', p.position FROM unnest($2::VARCHAR[]) WITH ORDINALITY AS p(user_id, position)
+		UNION ALL
+		SELECT $1::INTEGER, e.user_id, 'EXCLUDED', e.position FROM unnest($3::VARCHAR[]) WITH ORDINALITY AS e(user_id, position)
+		ON CONFLICT DO NOTHING
+	`, prDBID, nonNil(pr.PreferredReviewers), nonNil(pr.ExcludedReviewers))
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("insert reviewer preferences: %w", err)
+	}
+
+	pr.AssignedReviewers = []string{}
+	if pr.Status == models.StatusOpen {
+		pool := candidatePool{
+			TeamID:           teamID,
+			WithFallbacks:    true,
+			PreferredUserIDs: preferredUserIDs,
+			ExcludeUserIDs:   append([]string{pr.AuthorID}, pr.ExcludedReviewers...),
+			Labels:           pr.Labels,
+		}
+		pr.AssignedReviewers, pr.FallbackReviewers, pr.MissingReviewers, err = assignReviewers(ctx, tx, prDBID, pool, reviewersCount, pick)
 		if err != nil {
-			return models.PullRequest{}, fmt.Errorf("assign reviewer %s: %w", reviewerID, err)
+			return models.PullRequest{}, err
 		}
 	}
 
+	events := append([]pullRequestEvent{{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+		Type:     models.EventCreated,
+		ActorID:  pr.AuthorID,
+		ToStatus: pr.Status,
+	}}}, assignedEvents(prDBID, pr.AssignedReviewers, "", models.EventReasonSelection)...)
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return models.PullRequest{}, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return models.PullRequest{}, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	pr.Status = "OPEN"
-	pr.AssignedReviewers = reviewerIDs
+	pr.TeamID = teamID
 	pr.MergedAt = sql.NullTime{}
 	return pr, nil
 }
 
-func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequestID string) (models.PullRequest, error) {
+// assignReviewers picks reviewers out of pool and assigns them to the pull
+// request. When fewer than reviewersCount are picked while candidates at
+// capacity could have made up the difference, the slots they left empty are
+// added to the missing reviewers of the pull request and returned. The pull
+// request row must already be locked by tx.
+func assignReviewers(ctx context.Context, tx *sqlx.Tx, prDBID int, pool candidatePool, reviewersCount int, pick storage.ReviewerPicker) (reviewerIDs, fallbackIDs []string, missing int, err error) {
+	if err = lockTeamCandidates(ctx, tx, pool); err != nil {
+		return nil, nil, 0, err
+	}
+
+	pool.WithAtCapacity = true
+	candidates, err := selectReviewerCandidates(ctx, tx, pool)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	atCapacity := 0
+	candidates = slices.DeleteFunc(candidates, func(c models.ReviewerCandidate) bool {
+		if c.AtCapacity() {
+			atCapacity++
+			return true
+		}
+		return false
+	})
+	reviewerIDs = pick(candidates)
+	missing = max(min(reviewersCount-len(reviewerIDs), atCapacity), 0)
+
+	if missing > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pull_requests SET missing_reviewers = missing_reviewers + $1 WHERE id = $2
+		`, missing, prDBID)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("record missing reviewers: %w", err)
+		}
+	}
+
+	tiers := make(map[string]int, len(candidates))
+	for _, c := range candidates {
+		tiers[c.UserID] = c.Tier
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		isFallback := tiers[reviewerID] > 0
+		_, err = tx.ExecContext(ctx, `
+   INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id, is_fallback)
+   VALUES ($1, $2, $3)
+  `, prDBID, reviewerID, isFallback)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("assign reviewer %s: %w", reviewerID, err)
+		}
+		if isFallback {
+			fallbackIDs = append(fallbackIDs, reviewerID)
+		}
+	}
+
+	return nonNil(reviewerIDs), fallbackIDs, missing, nil
+}
+
+func (p *PGPullRequestStorage) TransitionPullRequest(ctx context.Context, pullRequestID string, transition models.PullRequestTransition, teamID int, preferredUserIDs []string, reviewersCount int, pick storage.ReviewerPicker) (models.PullRequest, error) {
+	slog.Debug("Transitioning pull request in PG", "prID", pullRequestID, "to", transition.To, "teamID", teamID)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rows []pullRequestRow
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = $1
+		FOR UPDATE
+	`, pullRequestID)
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("get pull request: %w", err)
+	}
+	if len(rows) == 0 {
+		return models.PullRequest{}, errors.New("NOT_FOUND")
+	}
+	row := &rows[0]
+
+	if !transition.Allowed(row.Status) {
+		return models.PullRequest{}, errors.New(models.StatusError(row.Status))
+	}
+
+	events := []pullRequestEvent{{PRDBID: row.DBID, PullRequestEvent: models.PullRequestEvent{
+		Type:       models.EventStatusChanged,
+		FromStatus: row.Status,
+		ToStatus:   transition.To,
+	}}}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE pull_requests
+		SET status = $1,
+		    closed_at = CASE WHEN $1 = 'CLOSED' THEN NOW() END
+		WHERE id = $2
+		RETURNING status, closed_at
+	`, transition.To, row.DBID).Scan(&row.Status, &row.ClosedAt)
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("update PR status: %w", err)
+	}
+
+	if transition.To == models.StatusOpen {
+		var hasReviewers bool
+		err = tx.GetContext(ctx, &hasReviewers, `
+			SELECT EXISTS(SELECT 1 FROM pull_request_reviewers WHERE pull_request_id = $1)
+		`, row.DBID)
+		if err != nil {
+			return models.PullRequest{}, fmt.Errorf("check reviewers: %w", err)
+		}
+
+		if !hasReviewers {
+			if pick == nil {
+				return models.PullRequest{}, errors.New("PICKER_REQUIRED")
+			}
+
+			// Slots missing from an earlier assignment are assigned afresh.
+			if row.MissingReviewers > 0 {
+				_, err = tx.ExecContext(ctx, "UPDATE pull_requests SET missing_reviewers = 0 WHERE id = $1", row.DBID)
+				if err != nil {
+					return models.PullRequest{}, fmt.Errorf("reset missing reviewers: %w", err)
+				}
+			}
+
+			labels, err := selectLabels(ctx, tx, row.DBID)
+			if err != nil {
+				return models.PullRequest{}, err
+			}
+			excluded, err := selectExcludedReviewers(ctx, tx, row.DBID)
+			if err != nil {
+				return models.PullRequest{}, err
+			}
+			pool := candidatePool{
+				TeamID:           teamID,
+				WithFallbacks:    true,
+				PreferredUserIDs: preferredUserIDs,
+				ExcludeUserIDs:   append([]string{row.AuthorID}, excluded...),
+				Labels:           labels,
+			}
+			reviewerIDs, _, missing, err := assignReviewers(ctx, tx, row.DBID, pool, reviewersCount, pick)
+			if err != nil {
+				return models.PullRequest{}, err
+			}
+			row.MissingReviewers = missing
+			events = append(events, assignedEvents(row.DBID, reviewerIDs, "", models.EventReasonSelection)...)
+		}
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return models.PullRequest{}, err
+	}
+
+	pullRequests, err := loadPullRequestDetails(ctx, tx, rows)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.PullRequest{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return pullRequests[0], nil
+}
+
+func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequestID string, check storage.MergeCheck) (models.PullRequest, error) {
 	slog.Debug("Merging pull request in PG", "prID", pullRequestID)
 
 	tx, err := p.DB.BeginTxx(ctx, nil)
@@ -76,10 +279,14 @@ func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequest
 	var prDBID int
 	var pr models.PullRequest
 	err = tx.QueryRowContext(ctx, `
-  SELECT id, pull_request_id, name, author_id, status, merged_at
+  SELECT id, pull_request_id, name, author_id, status, merged_at, COALESCE(selection_seed, ''),
+         COALESCE(team_id, 0), COALESCE((SELECT t.name FROM teams t WHERE t.id = pull_requests.team_id), ''),
+         missing_reviewers
   FROM pull_requests
   WHERE pull_request_id = $1
- `, pullRequestID).Scan(&prDBID, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt)
+  FOR UPDATE
+ `, pullRequestID).Scan(&prDBID, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt, &pr.SelectionSeed, &pr.TeamID, &pr.TeamName,
+		&pr.MissingReviewers)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.PullRequest{}, errors.New("NOT_FOUND")
@@ -87,7 +294,31 @@ func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequest
 		return models.PullRequest{}, fmt.Errorf("get pull request: %w", err)
 	}
 
-	if pr.Status != "MERGED" {
+	if pr.Status == models.StatusDraft || pr.Status == models.StatusClosed {
+		return models.PullRequest{}, errors.New(models.StatusError(pr.Status))
+	}
+
+	err = tx.SelectContext(ctx, &pr.AssignedReviewers, `
+  SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
+ `, prDBID)
+	if err != nil {
+		return models.PullRequest{}, fmt.Errorf("get reviewers: %w", err)
+	}
+
+	pr.Labels, err = selectLabels(ctx, tx, prDBID)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+
+	if pr.Status != models.StatusMerged {
+		state, err := loadMergeState(ctx, tx, prDBID, pr)
+		if err != nil {
+			return models.PullRequest{}, err
+		}
+		if err = check(state); err != nil {
+			return models.PullRequest{}, err
+		}
+
 		_, err = tx.ExecContext(ctx, `
    UPDATE pull_requests
    SET status = 'MERGED', merged_at = NOW()
@@ -96,15 +327,17 @@ func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequest
 		if err != nil {
 			return models.PullRequest{}, fmt.Errorf("update PR status: %w", err)
 		}
-		pr.Status = "MERGED"
-		pr.MergedAt = sql.NullTime{Time: time.Now(), Valid: true}
-	}
 
-	err = tx.SelectContext(ctx, &pr.AssignedReviewers, `
-  SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
- `, prDBID)
-	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("get reviewers: %w", err)
+		err = insertEvents(ctx, tx, []pullRequestEvent{{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+			Type:       models.EventMerged,
+			FromStatus: pr.Status,
+			ToStatus:   models.StatusMerged,
+		}}})
+		if err != nil {
+			return models.PullRequest{}, err
+		}
+		pr.Status = models.StatusMerged
+		pr.MergedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -114,71 +347,914 @@ func (p *PGPullRequestStorage) MergePullRequest(ctx context.Context, pullRequest
 	return pr, nil
 }
 
-func (p *PGPullRequestStorage) ReassignReviewer(ctx context.Context, pullRequestID string, oldReviewerID string, newReviewerID string) (models.PullRequest, error) {
-	slog.Debug("Reassigning reviewer in PG", "prID", pullRequestID, "oldID", oldReviewerID, "newID", newReviewerID)
+func (p *PGPullRequestStorage) GetMergeState(ctx context.Context, pullRequestID string) (models.MergeState, error) {
+	slog.Debug("Getting merge state in PG", "prID", pullRequestID)
+
+	var rows []pullRequestRow
+	err := p.DB.SelectContext(ctx, &rows, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = $1
+	`, pullRequestID)
+	if err != nil {
+		return models.MergeState{}, fmt.Errorf("get pull request: %w", err)
+	}
+	if len(rows) == 0 {
+		return models.MergeState{}, errors.New("NOT_FOUND")
+	}
+
+	pullRequests, err := loadPullRequestDetails(ctx, p.DB, rows)
+	if err != nil {
+		return models.MergeState{}, err
+	}
+
+	return loadMergeState(ctx, p.DB, rows[0].DBID, pullRequests[0])
+}
+
+// loadMergeState loads the assigned reviewers of a pull request with their
+// tags and the reviews they submitted. Reviews of removed or replaced
+// reviewers are left out, so their change requests no longer block a merge.
+func loadMergeState(ctx context.Context, q sqlx.QueryerContext, prDBID int, pr models.PullRequest) (models.MergeState, error) {
+	state := models.MergeState{PullRequest: pr, Reviewers: []models.User{}}
+
+	err := sqlx.SelectContext(ctx, q, &state.Reviewers, `
+		SELECT u.user_id, u.username, u.is_active
+		FROM pull_request_reviewers prr
+		INNER JOIN users u ON u.user_id = prr.reviewer_id
+		WHERE prr.pull_request_id = $1
+		ORDER BY prr.assigned_at, u.user_id
+	`, prDBID)
+	if err != nil {
+		return models.MergeState{}, fmt.Errorf("get reviewers: %w", err)
+	}
+
+	var tags []struct {
+		UserID string `db:"user_id"`
+		Tag    string `db:"tag"`
+	}
+	err = sqlx.SelectContext(ctx, q, &tags, `
+		SELECT ut.user_id, ut.tag
+		FROM user_tags ut
+		INNER JOIN pull_request_reviewers prr ON prr.reviewer_id = ut.user_id
+		WHERE prr.pull_request_id = $1
+		ORDER BY ut.tag
+	`, prDBID)
+	if err != nil {
+		return models.MergeState{}, fmt.Errorf("get reviewer tags: %w", err)
+	}
+	for i := range state.Reviewers {
+		for _, t := range tags {
+			if t.UserID == state.Reviewers[i].ID {
+				state.Reviewers[i].Tags = append(state.Reviewers[i].Tags, t.Tag)
+			}
+		}
+	}
+
+	state.Reviews, err = selectReviews(ctx, q, prDBID, true)
+	if err != nil {
+		return models.MergeState{}, err
+	}
+
+	err = sqlx.GetContext(ctx, q, &state.Now, "SELECT LOCALTIMESTAMP")
+	if err != nil {
+		return models.MergeState{}, fmt.Errorf("get database time: %w", err)
+	}
+
+	return state, nil
+}
+
+func (p *PGPullRequestStorage) AddReview(ctx context.Context, review models.Review) (models.Review, error) {
+	slog.Debug("Adding review in PG", "prID", review.PullRequestID, "reviewerID", review.ReviewerID, "decision", review.Decision)
 
 	tx, err := p.DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("begin transaction: %w", err)
+		return models.Review{}, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// FOR SHARE keeps a merge from deciding on the reviews while one is added.
 	var prDBID int
-	var pr models.PullRequest
+	var status string
 	err = tx.QueryRowContext(ctx, `
-  SELECT id, pull_request_id, name, author_id, status, merged_at
-  FROM pull_requests
-  WHERE pull_request_id = $1
- `, pullRequestID).Scan(&prDBID, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt)
+		SELECT id, status FROM pull_requests WHERE pull_request_id = $1 FOR SHARE
+	`, review.PullRequestID).Scan(&prDBID, &status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.PullRequest{}, errors.New("NOT_FOUND")
+			return models.Review{}, errors.New("NOT_FOUND")
 		}
-		return models.PullRequest{}, fmt.Errorf("get pull request: %w", err)
+		return models.Review{}, fmt.Errorf("get pull request: %w", err)
 	}
 
-	if pr.Status == "MERGED" {
-		return models.PullRequest{}, errors.New("PR_MERGED")
+	if status != models.StatusOpen {
+		return models.Review{}, errors.New(models.StatusError(status))
 	}
 
 	var isAssigned bool
 	err = tx.GetContext(ctx, &isAssigned, `
-  SELECT EXISTS(SELECT 1 FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2)
- `, prDBID, oldReviewerID)
+		SELECT EXISTS(SELECT 1 FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2)
+	`, prDBID, review.ReviewerID)
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("check assignment: %w", err)
+		return models.Review{}, fmt.Errorf("check reviewer assigned: %w", err)
 	}
 	if !isAssigned {
-		return models.PullRequest{}, errors.New("NOT_ASSIGNED")
+		return models.Review{}, errors.New("NOT_ASSIGNED")
 	}
 
-	_, err = tx.ExecContext(ctx, `
-  DELETE FROM pull_request_reviewers
-  WHERE pull_request_id = $1 AND reviewer_id = $2
- `, prDBID, oldReviewerID)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO pull_request_reviews (pull_request_id, reviewer_id, decision, comment)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, prDBID, review.ReviewerID, review.Decision, review.Comment).Scan(&review.ID, &review.CreatedAt)
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("delete old reviewer: %w", err)
+		return models.Review{}, fmt.Errorf("insert review: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx, `
-  INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id)
-  VALUES ($1, $2)
-  ON CONFLICT (pull_request_id, reviewer_id) DO NOTHING
- `, prDBID, newReviewerID)
+	if err = tx.Commit(); err != nil {
+		return models.Review{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return review, nil
+}
+
+func (p *PGPullRequestStorage) GetReviews(ctx context.Context, pullRequestID string) ([]models.Review, error) {
+	slog.Debug("Getting reviews in PG", "prID", pullRequestID)
+
+	var prDBID int
+	err := p.DB.GetContext(ctx, &prDBID, "SELECT id FROM pull_requests WHERE pull_request_id = $1", pullRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("NOT_FOUND")
+		}
+		return nil, fmt.Errorf("get pull request: %w", err)
+	}
+
+	return selectReviews(ctx, p.DB, prDBID, false)
+}
+
+// selectReviews returns the reviews of a pull request, oldest first. With
+// assignedOnly, reviews of reviewers no longer assigned are left out.
+func selectReviews(ctx context.Context, q sqlx.QueryerContext, prDBID int, assignedOnly bool) ([]models.Review, error) {
+	reviews := []models.Review{}
+	err := sqlx.SelectContext(ctx, q, &reviews, `
+		SELECT r.id, pr.pull_request_id, r.reviewer_id, r.decision, r.comment, r.created_at
+		FROM pull_request_reviews r
+		INNER JOIN pull_requests pr ON pr.id = r.pull_request_id
+		WHERE r.pull_request_id = $1
+		  AND (NOT $2 OR EXISTS (
+			SELECT 1 FROM pull_request_reviewers prr
+			WHERE prr.pull_request_id = r.pull_request_id AND prr.reviewer_id = r.reviewer_id))
+		ORDER BY r.id
+	`, prDBID, assignedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("get reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (p *PGPullRequestStorage) ReassignReviewer(ctx context.Context, pullRequestID string, oldReviewerID string, teamID int, pick storage.ReviewerPicker, reason string) (models.PullRequest, string, error) {
+	slog.Debug("Reassigning reviewer in PG", "prID", pullRequestID, "oldID", oldReviewerID, "teamID", teamID, "reason", reason)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequest{}, "", fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var prDBID int
+	var pr models.PullRequest
+	err = tx.QueryRowContext(ctx, `
+  SELECT id, pull_request_id, name, author_id, status, merged_at, COALESCE(selection_seed, ''),
+         COALESCE(team_id, 0), COALESCE((SELECT t.name FROM teams t WHERE t.id = pull_requests.team_id), ''),
+         missing_reviewers
+  FROM pull_requests
+  WHERE pull_request_id = $1
+  FOR UPDATE
+ `, pullRequestID).Scan(&prDBID, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt, &pr.SelectionSeed, &pr.TeamID, &pr.TeamName,
+		&pr.MissingReviewers)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PullRequest{}, "", errors.New("NOT_FOUND")
+		}
+		return models.PullRequest{}, "", fmt.Errorf("get pull request: %w", err)
+	}
+
+	if pr.Status != models.StatusOpen {
+		return models.PullRequest{}, "", errors.New(models.StatusError(pr.Status))
+	}
+
+	pr.Labels, err = selectLabels(ctx, tx, prDBID)
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("insert new reviewer: %w", err)
+		return models.PullRequest{}, "", err
+	}
+
+	newReviewerID, err := replaceReviewer(ctx, tx, prDBID, pr, oldReviewerID, teamID, pick)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	err = insertEvents(ctx, tx, []pullRequestEvent{{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+		Type:          models.EventReviewerReassigned,
+		ReviewerID:    newReviewerID,
+		OldReviewerID: oldReviewerID,
+		Reason:        reason,
+	}}})
+	if err != nil {
+		return models.PullRequest{}, "", err
 	}
 
 	err = tx.SelectContext(ctx, &pr.AssignedReviewers, `
   SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
  `, prDBID)
 	if err != nil {
-		return models.PullRequest{}, fmt.Errorf("get reviewers: %w", err)
+		return models.PullRequest{}, "", fmt.Errorf("get reviewers: %w", err)
 	}
 
 	if err = tx.Commit(); err != nil {
-		return models.PullRequest{}, fmt.Errorf("commit: %w", err)
+		return models.PullRequest{}, "", fmt.Errorf("commit: %w", err)
 	}
 
-	return pr, nil
+	return pr, newReviewerID, nil
+}
+
+// replaceReviewer swaps oldReviewerID on a pull request already locked by tx
+// for a reviewer picked among the active members of teamID, the reviewers
+// excluded by the author aside. pr must carry the author and labels.
+func replaceReviewer(ctx context.Context, tx *sqlx.Tx, prDBID int, pr models.PullRequest, oldReviewerID string, teamID int, pick storage.ReviewerPicker) (string, error) {
+	var currentReviewers []string
+	err := tx.SelectContext(ctx, &currentReviewers, `
+		SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
+	`, prDBID)
+	if err != nil {
+		return "", fmt.Errorf("get reviewers: %w", err)
+	}
+	if !slices.Contains(currentReviewers, oldReviewerID) {
+		return "", errors.New("NOT_ASSIGNED")
+	}
+
+	excluded, err := selectExcludedReviewers(ctx, tx, prDBID)
+	if err != nil {
+		return "", err
+	}
+
+	pool := candidatePool{
+		TeamID:         teamID,
+		ExcludeUserIDs: slices.Concat([]string{pr.AuthorID}, currentReviewers, excluded),
+		Labels:         pr.Labels,
+	}
+	if err = lockTeamCandidates(ctx, tx, pool); err != nil {
+		return "", err
+	}
+
+	candidates, err := selectReviewerCandidates(ctx, tx, pool)
+	if err != nil {
+		return "", err
+	}
+
+	picked := pick(candidates)
+	if len(picked) == 0 {
+		return "", errors.New("NO_CANDIDATE")
+	}
+	newReviewerID := picked[0]
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM pull_request_reviewers
+		WHERE pull_request_id = $1 AND reviewer_id = $2
+	`, prDBID, oldReviewerID)
+	if err != nil {
+		return "", fmt.Errorf("delete old reviewer: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id)
+		VALUES ($1, $2)
+	`, prDBID, newReviewerID)
+	if err != nil {
+		return "", fmt.Errorf("insert new reviewer: %w", err)
+	}
+
+	return newReviewerID, nil
+}
+
+func selectLabels(ctx context.Context, q sqlx.QueryerContext, prDBID int) ([]string, error) {
+	var labels []string
+	err := sqlx.SelectContext(ctx, q, &labels, `
+		SELECT label FROM pull_request_labels WHERE pull_request_id = $1 ORDER BY label
+	`, prDBID)
+	if err != nil {
+		return nil, fmt.Errorf("get labels: %w", err)
+	}
+	return labels, nil
+}
+
+// selectExcludedReviewers returns the users the author excluded from
+// reviewing the pull request.
+func selectExcludedReviewers(ctx context.Context, q sqlx.QueryerContext, prDBID int) ([]string, error) {
+	var excluded []string
+	err := sqlx.SelectContext(ctx, q, &excluded, `
+		SELECT user_id FROM pull_request_reviewer_preferences
+		WHERE pull_request_id = $1 AND kind = 'EXCLUDED'
+		ORDER BY position
+	`, prDBID)
+	if err != nil {
+		return nil, fmt.Errorf("get excluded reviewers: %w", err)
+	}
+	return excluded, nil
+}
+
+func (p *PGPullRequestStorage) GetPullRequest(ctx context.Context, pullRequestID string) (models.PullRequest, error) {
+	slog.Debug("Getting pull request in PG", "prID", pullRequestID)
+
+	var rows []pullRequestRow
+	err := p.DB.SelectContext(ctx, &rows, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = $1
+	`, pullRequestID)
+	if err != nil {
+		slog.Error("SQL get pull request error", "err", err)
+		return models.PullRequest{}, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	if len(rows) == 0 {
+		return models.PullRequest{}, errors.New("NOT_FOUND")
+	}
+
+	pullRequests, err := loadPullRequestDetails(ctx, p.DB, rows)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+
+	return pullRequests[0], nil
+}
+
+func (p *PGPullRequestStorage) ListPullRequests(ctx context.Context, filter models.PullRequestFilter) (models.PullRequestPage, error) {
+	slog.Debug("Listing pull requests in PG", "filter", filter)
+
+	sortKey, ok := pullRequestSortKeys[filter.SortBy]
+	if !ok {
+		filter.SortBy = models.SortByCreatedAt
+		sortKey = pullRequestSortKeys[filter.SortBy]
+	}
+
+	var conditions []string
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "pr.status = "+arg(filter.Status))
+	}
+	if filter.AuthorID != "" {
+		conditions = append(conditions, "pr.author_id = "+arg(filter.AuthorID))
+	}
+	if filter.ReviewerID != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM pull_request_reviewers prr
+			WHERE prr.pull_request_id = pr.id AND prr.reviewer_id = `+arg(filter.ReviewerID)+`)`)
+	}
+	if filter.TeamID != 0 {
+		conditions = append(conditions, pullRequestTeam+" = "+arg(filter.TeamID))
+	}
+	if filter.Understaffed {
+		conditions = append(conditions, "pr.missing_reviewers > 0")
+	}
+	if filter.CreatedFrom.Valid {
+		conditions = append(conditions, "pr.created_at >= "+arg(filter.CreatedFrom.Time))
+	}
+	if filter.CreatedTo.Valid {
+		conditions = append(conditions, "pr.created_at < "+arg(filter.CreatedTo.Time))
+	}
+	if filter.MergedFrom.Valid {
+		conditions = append(conditions, "pr.merged_at >= "+arg(filter.MergedFrom.Time))
+	}
+	if filter.MergedTo.Valid {
+		conditions = append(conditions, "pr.merged_at < "+arg(filter.MergedTo.Time))
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, pr.pull_request_id) %s (%s::%s, %s)",
+			sortKey.expr, comparison, arg(filter.After.Value), sortKey.cast, arg(filter.After.ID)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var rows []pullRequestRow
+	err := p.DB.SelectContext(ctx, &rows, fmt.Sprintf(`
+		SELECT %s
+		FROM pull_requests pr
+		%s
+		ORDER BY %s %s, pr.pull_request_id %s
+		LIMIT %s
+	`, pullRequestColumns, where, sortKey.expr, direction, direction, arg(filter.Limit+1)), args...)
+	if err != nil {
+		slog.Error("SQL list pull requests error", "err", err)
+		return models.PullRequestPage{}, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var page models.PullRequestPage
+	if len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+		last := rows[len(rows)-1].PullRequest
+		page.Next = &models.PullRequestCursor{
+			Value: sortValue(last, filter.SortBy),
+			ID:    last.ID,
+		}
+	}
+
+	page.PullRequests, err = loadPullRequestDetails(ctx, p.DB, rows)
+	if err != nil {
+		return models.PullRequestPage{}, err
+	}
+
+	return page, nil
+}
+
+const pullRequestColumns = `pr.id, pr.pull_request_id, pr.name, pr.author_id, pr.status, pr.merged_at, pr.closed_at, pr.created_at,
+			COALESCE(pr.selection_seed, '') AS selection_seed, COALESCE(pr.team_id, 0) AS team_id,
+			COALESCE((SELECT t.name FROM teams t WHERE t.id = pr.team_id), '') AS team_name, pr.missing_reviewers`
+
+// pullRequestTeam is the team of the pull request pr: the one stored with it,
+// or else the author's primary team for pull requests created before teams
+// were recorded.
+const pullRequestTeam = `COALESCE(pr.team_id, (
+				SELECT tm.team_id FROM team_members tm
+				WHERE tm.user_id = pr.author_id
+				ORDER BY tm.is_primary DESC, tm.joined_at, tm.team_id
+				LIMIT 1))`
+
+// pgTimestampLayout renders TIMESTAMP values without losing microseconds.
+const pgTimestampLayout = "2006-01-02 15:04:05.999999"
+
+// pullRequestSortKeys maps sort fields to their SQL expression and the type
+// the cursor value is cast to. Unmerged pull requests sort after merged ones.
+var pullRequestSortKeys = map[string]struct {
+	expr string
+	cast string
+}{
+	models.SortByCreatedAt: {"pr.created_at", "TIMESTAMP"},
+	models.SortByMergedAt:  {"COALESCE(pr.merged_at, 'infinity'::TIMESTAMP)", "TIMESTAMP"},
+	models.SortByName:      {"pr.name", "VARCHAR"},
+	models.SortByID:        {"pr.pull_request_id", "VARCHAR"},
+}
+
+func sortValue(pr models.PullRequest, sortBy string) string {
+	switch sortBy {
+	case models.SortByMergedAt:
+		if !pr.MergedAt.Valid {
+			return "infinity"
+		}
+		return pr.MergedAt.Time.Format(pgTimestampLayout)
+	case models.SortByName:
+		return pr.Name
+	case models.SortByID:
+		return pr.ID
+	default:
+		return pr.CreatedAt.Format(pgTimestampLayout)
+	}
+}
+
+// pullRequestRow is a pull request together with its surrogate key.
+type pullRequestRow struct {
+	DBID int `db:"id"`
+	models.PullRequest
+}
+
+// loadPullRequestDetails fills reviewers, labels and reviewer preferences of
+// rows with one query each, keeping the order of rows.
+func loadPullRequestDetails(ctx context.Context, q sqlx.QueryerContext, rows []pullRequestRow) ([]models.PullRequest, error) {
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.DBID)
+	}
+
+	var reviewers []struct {
+		PRDBID     int    `db:"pull_request_id"`
+		ReviewerID string `db:"reviewer_id"`
+		IsFallback bool   `db:"is_fallback"`
+	}
+	err := sqlx.SelectContext(ctx, q, &reviewers, `
+		SELECT pull_request_id, reviewer_id, is_fallback
+		FROM pull_request_reviewers
+		WHERE pull_request_id = ANY($1)
+		ORDER BY assigned_at, reviewer_id
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get reviewers: %w", err)
+	}
+
+	var labels []struct {
+		PRDBID int    `db:"pull_request_id"`
+		Label  string `db:"label"`
+	}
+	err = sqlx.SelectContext(ctx, q, &labels, `
+		SELECT pull_request_id, label
+		FROM pull_request_labels
+		WHERE pull_request_id = ANY($1)
+		ORDER BY label
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get labels: %w", err)
+	}
+
+	var preferences []struct {
+		PRDBID int    `db:"pull_request_id"`
+		UserID string `db:"user_id"`
+		Kind   string `db:"kind"`
+	}
+	err = sqlx.SelectContext(ctx, q, &preferences, `
+		SELECT pull_request_id, user_id, kind
+		FROM pull_request_reviewer_preferences
+		WHERE pull_request_id = ANY($1)
+		ORDER BY position
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get reviewer preferences: %w", err)
+	}
+
+	byID := make(map[int]*models.PullRequest, len(rows))
+	pullRequests := make([]models.PullRequest, len(rows))
+	for i, row := range rows {
+		pullRequests[i] = row.PullRequest
+		pullRequests[i].AssignedReviewers = []string{}
+		byID[row.DBID] = &pullRequests[i]
+	}
+	for _, r := range reviewers {
+		pr := byID[r.PRDBID]
+		pr.AssignedReviewers = append(pr.AssignedReviewers, r.ReviewerID)
+		if r.IsFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, r.ReviewerID)
+		}
+	}
+	for _, l := range labels {
+		pr := byID[l.PRDBID]
+		pr.Labels = append(pr.Labels, l.Label)
+	}
+	for _, p := range preferences {
+		pr := byID[p.PRDBID]
+		if p.Kind == "EXCLUDED" {
+			pr.ExcludedReviewers = append(pr.ExcludedReviewers, p.UserID)
+		} else {
+			pr.PreferredReviewers = append(pr.PreferredReviewers, p.UserID)
+		}
+	}
+
+	return pullRequests, nil
+}
+
+func (p *PGPullRequestStorage) AddReviewer(ctx context.Context, change models.ReviewerChange, teamID int) (models.PullRequest, models.ReviewerChange, error) {
+	slog.Debug("Adding reviewer in PG", "prID", change.PullRequestID, "reviewerID", change.ReviewerID, "by", change.PerformedBy)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	row, err := lockOpenPullRequest(ctx, tx, change.PullRequestID)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, err
+	}
+
+	if change.ReviewerID == row.AuthorID {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_IS_AUTHOR")
+	}
+
+	var isActive, isAvailable bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.is_active, `+availableUser+`
+		FROM users u
+		WHERE u.user_id = $1
+		FOR SHARE OF u
+	`, change.ReviewerID).Scan(&isActive, &isAvailable)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_NOT_FOUND")
+		}
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("get reviewer: %w", err)
+	}
+	if !isActive {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_INACTIVE")
+	}
+	if !isAvailable {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("REVIEWER_UNAVAILABLE")
+	}
+
+	var inTeam, inFallbackTeam bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2),
+			EXISTS(
+				SELECT 1
+				FROM team_members tm
+				INNER JOIN team_fallbacks tf ON tf.fallback_team_id = tm.team_id
+				WHERE tf.team_id = $1 AND tm.user_id = $2
+			)
+	`, teamID, change.ReviewerID).Scan(&inTeam, &inFallbackTeam)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("check reviewer team: %w", err)
+	}
+	if !inTeam && !inFallbackTeam {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_TEAM_MEMBER")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id, is_fallback)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, row.DBID, change.ReviewerID, !inTeam)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("assign reviewer: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("ALREADY_ASSIGNED")
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE pull_requests SET missing_reviewers = GREATEST(missing_reviewers - 1, 0)
+		WHERE id = $1
+		RETURNING missing_reviewers
+	`, row.DBID).Scan(&row.MissingReviewers)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("update missing reviewers: %w", err)
+	}
+
+	return p.recordReviewerChange(ctx, tx, row, change, models.ReviewerAdded)
+}
+
+func (p *PGPullRequestStorage) RemoveReviewer(ctx context.Context, change models.ReviewerChange) (models.PullRequest, models.ReviewerChange, error) {
+	slog.Debug("Removing reviewer in PG", "prID", change.PullRequestID, "reviewerID", change.ReviewerID, "by", change.PerformedBy)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	row, err := lockOpenPullRequest(ctx, tx, change.PullRequestID)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2
+	`, row.DBID, change.ReviewerID)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("delete reviewer: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return models.PullRequest{}, models.ReviewerChange{}, errors.New("NOT_ASSIGNED")
+	}
+
+	return p.recordReviewerChange(ctx, tx, row, change, models.ReviewerRemoved)
+}
+
+// lockOpenPullRequest locks the pull request row until the end of tx and
+// fails unless the pull request is OPEN.
+func lockOpenPullRequest(ctx context.Context, tx *sqlx.Tx, pullRequestID string) (pullRequestRow, error) {
+	var rows []pullRequestRow
+	err := tx.SelectContext(ctx, &rows, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = $1
+		FOR UPDATE
+	`, pullRequestID)
+	if err != nil {
+		return pullRequestRow{}, fmt.Errorf("get pull request: %w", err)
+	}
+	if len(rows) == 0 {
+		return pullRequestRow{}, errors.New("NOT_FOUND")
+	}
+	if rows[0].Status != models.StatusOpen {
+		return pullRequestRow{}, errors.New(models.StatusError(rows[0].Status))
+	}
+	return rows[0], nil
+}
+
+// recordReviewerChange stores change with action, commits tx and returns the
+// updated pull request.
+func (p *PGPullRequestStorage) recordReviewerChange(ctx context.Context, tx *sqlx.Tx, row pullRequestRow, change models.ReviewerChange, action string) (models.PullRequest, models.ReviewerChange, error) {
+	change.Action = action
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO pull_request_reviewer_changes (pull_request_id, reviewer_id, action, performed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, row.DBID, change.ReviewerID, change.Action, change.PerformedBy).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("record reviewer change: %w", err)
+	}
+
+	eventType := models.EventReviewerAssigned
+	if action == models.ReviewerRemoved {
+		eventType = models.EventReviewerRemoved
+	}
+	err = insertEvents(ctx, tx, []pullRequestEvent{{PRDBID: row.DBID, PullRequestEvent: models.PullRequestEvent{
+		Type:       eventType,
+		ActorID:    change.PerformedBy,
+		ReviewerID: change.ReviewerID,
+		Reason:     models.EventReasonManual,
+	}}})
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, err
+	}
+
+	pullRequests, err := loadPullRequestDetails(ctx, tx, []pullRequestRow{row})
+	if err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.PullRequest{}, models.ReviewerChange{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return pullRequests[0], change, nil
+}
+
+func (p *PGPullRequestStorage) UpdatePullRequest(ctx context.Context, update models.PullRequestUpdate, teamID int, pick storage.ReviewerPicker) (models.PullRequestUpdateResult, error) {
+	slog.Debug("Updating pull request in PG", "prID", update.PullRequestID, "by", update.ChangedBy)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequestUpdateResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rows []pullRequestRow
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests pr
+		WHERE pr.pull_request_id = $1
+		FOR UPDATE
+	`, update.PullRequestID)
+	if err != nil {
+		return models.PullRequestUpdateResult{}, fmt.Errorf("get pull request: %w", err)
+	}
+	if len(rows) == 0 {
+		return models.PullRequestUpdateResult{}, errors.New("NOT_FOUND")
+	}
+	row := rows[0]
+
+	if row.Status == models.StatusMerged {
+		return models.PullRequestUpdateResult{}, errors.New(models.StatusError(row.Status))
+	}
+
+	var result models.PullRequestUpdateResult
+	addChange := func(field, oldValue string, newValue *string) {
+		if newValue != nil && *newValue != oldValue {
+			result.Changes = append(result.Changes, models.PullRequestChange{
+				PullRequestID: row.ID,
+				Field:         field,
+				OldValue:      oldValue,
+				NewValue:      *newValue,
+				ChangedBy:     update.ChangedBy,
+			})
+		}
+	}
+	addChange(models.FieldName, row.Name, update.Name)
+	addChange(models.FieldAuthorID, row.AuthorID, update.AuthorID)
+
+	if update.AuthorID != nil && *update.AuthorID != row.AuthorID {
+		var authorID string
+		err = tx.GetContext(ctx, &authorID, "SELECT user_id FROM users WHERE user_id = $1 FOR SHARE", *update.AuthorID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.PullRequestUpdateResult{}, errors.New("AUTHOR_NOT_FOUND")
+			}
+			return models.PullRequestUpdateResult{}, fmt.Errorf("get author: %w", err)
+		}
+	}
+
+	for i, change := range result.Changes {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO pull_request_changes (pull_request_id, field, old_value, new_value, changed_by)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, changed_at
+		`, row.DBID, change.Field, change.OldValue, change.NewValue, change.ChangedBy).Scan(&result.Changes[i].ID, &result.Changes[i].ChangedAt)
+		if err != nil {
+			return models.PullRequestUpdateResult{}, fmt.Errorf("record pull request change: %w", err)
+		}
+
+		switch change.Field {
+		case models.FieldName:
+			row.Name = change.NewValue
+		case models.FieldAuthorID:
+			row.AuthorID = change.NewValue
+		}
+	}
+
+	if len(result.Changes) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pull_requests SET name = $2, author_id = $3 WHERE id = $1
+		`, row.DBID, row.Name, row.AuthorID)
+		if err != nil {
+			return models.PullRequestUpdateResult{}, fmt.Errorf("update pull request: %w", err)
+		}
+	}
+
+	var currentReviewers []string
+	err = tx.SelectContext(ctx, &currentReviewers, `
+		SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
+	`, row.DBID)
+	if err != nil {
+		return models.PullRequestUpdateResult{}, fmt.Errorf("get reviewers: %w", err)
+	}
+
+	if slices.Contains(currentReviewers, row.AuthorID) {
+		result.RemovedReviewer = row.AuthorID
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2
+		`, row.DBID, row.AuthorID)
+		if err != nil {
+			return models.PullRequestUpdateResult{}, fmt.Errorf("delete author reviewer: %w", err)
+		}
+
+		// Only OPEN pull requests get a replacement; a closed one is staffed
+		// again when it is reopened.
+		if row.Status == models.StatusOpen {
+			labels, err := selectLabels(ctx, tx, row.DBID)
+			if err != nil {
+				return models.PullRequestUpdateResult{}, err
+			}
+			excluded, err := selectExcludedReviewers(ctx, tx, row.DBID)
+			if err != nil {
+				return models.PullRequestUpdateResult{}, err
+			}
+
+			pool := candidatePool{
+				TeamID:         teamID,
+				WithFallbacks:  true,
+				ExcludeUserIDs: append(currentReviewers, excluded...),
+				Labels:         labels,
+			}
+			replacements, _, missing, err := assignReviewers(ctx, tx, row.DBID, pool, 1, pick)
+			if err != nil {
+				return models.PullRequestUpdateResult{}, err
+			}
+			row.MissingReviewers += missing
+			if len(replacements) > 0 {
+				result.ReplacedBy = replacements[0]
+			}
+		}
+
+		event := models.PullRequestEvent{
+			Type:       models.EventReviewerRemoved,
+			ActorID:    update.ChangedBy,
+			ReviewerID: result.RemovedReviewer,
+			Reason:     models.EventReasonAuthorChanged,
+		}
+		if result.ReplacedBy != "" {
+			event.Type = models.EventReviewerReassigned
+			event.ReviewerID = result.ReplacedBy
+			event.OldReviewerID = result.RemovedReviewer
+		}
+		if err = insertEvents(ctx, tx, []pullRequestEvent{{PRDBID: row.DBID, PullRequestEvent: event}}); err != nil {
+			return models.PullRequestUpdateResult{}, err
+		}
+	}
+
+	pullRequests, err := loadPullRequestDetails(ctx, tx, []pullRequestRow{row})
+	if err != nil {
+		return models.PullRequestUpdateResult{}, err
+	}
+	result.PullRequest = pullRequests[0]
+
+	if err = tx.Commit(); err != nil {
+		return models.PullRequestUpdateResult{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (p *PGPullRequestStorage) GetPullRequestChanges(ctx context.Context, pullRequestID string) ([]models.PullRequestChange, error) {
+	slog.Debug("Getting pull request changes in PG", "prID", pullRequestID)
+
+	var prDBID int
+	err := p.DB.GetContext(ctx, &prDBID, "SELECT id FROM pull_requests WHERE pull_request_id = $1", pullRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("NOT_FOUND")
+		}
+		return nil, fmt.Errorf("get pull request: %w", err)
+	}
+
+	changes := []models.PullRequestChange{}
+	err = p.DB.SelectContext(ctx, &changes, `
+		SELECT c.id, pr.pull_request_id, c.field, c.old_value, c.new_value, c.changed_by, c.changed_at
+		FROM pull_request_changes c
+		INNER JOIN pull_requests pr ON pr.id = c.pull_request_id
+		WHERE c.pull_request_id = $1
+		ORDER BY c.id
+	`, prDBID)
+	if err != nil {
+		return nil, fmt.Errorf("get pull request changes: %w", err)
+	}
+	return changes, nil
 }
@@ -0,0 +1,120 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type PGReminderStorage struct {
+	DB *sqlx.DB
+}
+
+// ClaimReminders relies on the upsert into review_reminders: of two replicas
+// claiming the same reviewer, the second one sees the fresh reminded_at and
+// skips them.
+func (p *PGReminderStorage) ClaimReminders(ctx context.Context, policy models.ReminderPolicy) ([]models.ReviewReminder, error) {
+	slog.Debug("Claiming review reminders in PG", "staleAfter", policy.StaleAfter, "repeatAfter", policy.RepeatAfter)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reminders := []models.ReviewReminder{}
+	err = tx.SelectContext(ctx, &reminders, `
+		WITH pending AS (
+			SELECT prr.reviewer_id, COUNT(*) AS pending_count, MIN(prr.assigned_at) AS oldest_assigned_at
+			FROM pull_request_reviewers prr
+			INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+			INNER JOIN users u ON u.user_id = prr.reviewer_id
+			WHERE pr.status = 'OPEN'
+			  AND `+availableUser+`
+			  AND prr.assigned_at < NOW() - $1::BIGINT * INTERVAL '1 microsecond'
+			  AND NOT EXISTS (
+				SELECT 1 FROM pull_request_reviews r
+				WHERE r.pull_request_id = prr.pull_request_id
+				  AND r.reviewer_id = prr.reviewer_id
+				  AND r.created_at >= prr.assigned_at)
+			GROUP BY prr.reviewer_id
+		), claimed AS (
+			INSERT INTO review_reminders (reviewer_id, reminded_at, pending_count)
+			SELECT reviewer_id, NOW(), pending_count FROM pending
+			ON CONFLICT (reviewer_id) DO UPDATE
+				SET reminded_at = EXCLUDED.reminded_at, pending_count = EXCLUDED.pending_count
+				WHERE review_reminders.reminded_at < NOW() - $2::BIGINT * INTERVAL '1 microsecond'
+			RETURNING reviewer_id, reminded_at
+		)
+		SELECT p.reviewer_id, p.pending_count,
+		       p.oldest_assigned_at::TIMESTAMPTZ AS oldest_assigned_at,
+		       c.reminded_at::TIMESTAMPTZ AS reminded_at
+		FROM pending p
+		INNER JOIN claimed c ON c.reviewer_id = p.reviewer_id
+		ORDER BY p.reviewer_id
+	`, policy.StaleAfter.Microseconds(), policy.RepeatAfter.Microseconds())
+	if err != nil {
+		slog.Error("SQL claim review reminders error", "err", err)
+		return nil, fmt.Errorf("failed to claim review reminders: %w", err)
+	}
+	if len(reminders) == 0 {
+		return reminders, nil
+	}
+
+	reviewerIDs := make([]string, 0, len(reminders))
+	for _, reminder := range reminders {
+		reviewerIDs = append(reviewerIDs, reminder.ReviewerID)
+	}
+
+	var rows []struct {
+		ReviewerID    string `db:"reviewer_id"`
+		PullRequestID string `db:"pull_request_id"`
+	}
+	err = tx.SelectContext(ctx, &rows, `
+		SELECT prr.reviewer_id, pr.pull_request_id
+		FROM pull_request_reviewers prr
+		INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		WHERE prr.reviewer_id = ANY($1)
+		  AND pr.status = 'OPEN'
+		  AND prr.assigned_at < NOW() - $2::BIGINT * INTERVAL '1 microsecond'
+		  AND NOT EXISTS (
+			SELECT 1 FROM pull_request_reviews r
+			WHERE r.pull_request_id = prr.pull_request_id
+			  AND r.reviewer_id = prr.reviewer_id
+			  AND r.created_at >= prr.assigned_at)
+		ORDER BY prr.assigned_at, pr.pull_request_id
+	`, reviewerIDs, policy.StaleAfter.Microseconds())
+	if err != nil {
+		return nil, fmt.Errorf("get pending pull requests: %w", err)
+	}
+
+	pullRequestIDs := make(map[string][]string, len(reminders))
+	for _, row := range rows {
+		pullRequestIDs[row.ReviewerID] = append(pullRequestIDs[row.ReviewerID], row.PullRequestID)
+	}
+	for i := range reminders {
+		reminders[i].PullRequestIDs = pullRequestIDs[reminders[i].ReviewerID]
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return reminders, nil
+}
+
+// ReleaseReminder leaves later claims of the reviewer in place.
+func (p *PGReminderStorage) ReleaseReminder(ctx context.Context, reminder models.ReviewReminder) error {
+	slog.Debug("Releasing review reminder in PG", "reviewerID", reminder.ReviewerID)
+
+	_, err := p.DB.ExecContext(ctx, `
+		DELETE FROM review_reminders
+		WHERE reviewer_id = $1 AND reminded_at::TIMESTAMPTZ = $2
+	`, reminder.ReviewerID, reminder.RemindedAt)
+	if err != nil {
+		return fmt.Errorf("release review reminder: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sssciel/avito-backend-intership/internals/storage"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+// ListUnansweredAssignments returns reviewers of OPEN pull requests who have
+// not reviewed since they were assigned and have been waiting longer than
+// the response hours of the pull request's team in wall-clock time, along
+// with the database clock. Callers decide on the exact deadline, which may
+// count working hours only and so comes later.
+func (p *PGPullRequestStorage) ListUnansweredAssignments(ctx context.Context) ([]models.SLAAssignment, time.Time, error) {
+	slog.Debug("Listing unanswered assignments in PG")
+
+	var now time.Time
+	if err := p.DB.GetContext(ctx, &now, "SELECT NOW()"); err != nil {
+		return nil, time.Time{}, fmt.Errorf("get database time: %w", err)
+	}
+
+	assignments := []models.SLAAssignment{}
+	err := p.DB.SelectContext(ctx, &assignments, `
+		SELECT pr.pull_request_id, prr.reviewer_id, prr.assigned_at::TIMESTAMPTZ AS assigned_at, t.review_sla
+		FROM pull_request_reviewers prr
+		INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		INNER JOIN teams t ON t.id = `+pullRequestTeam+`
+		WHERE pr.status = 'OPEN'
+		  AND prr.sla_breached_at IS NULL
+		  AND COALESCE((t.review_sla->>'response_hours')::INTEGER, 0) > 0
+		  AND prr.assigned_at::TIMESTAMPTZ < $1::TIMESTAMPTZ
+			- make_interval(hours => (t.review_sla->>'response_hours')::INTEGER)
+		  AND NOT EXISTS (
+			SELECT 1 FROM pull_request_reviews r
+			WHERE r.pull_request_id = prr.pull_request_id
+			  AND r.reviewer_id = prr.reviewer_id
+			  AND r.created_at >= prr.assigned_at)
+		ORDER BY prr.assigned_at, pr.pull_request_id, prr.reviewer_id
+	`, now)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("list unanswered assignments: %w", err)
+	}
+	return assignments, now, nil
+}
+
+// ReassignOverdueReviewer replaces a reviewer who missed the SLA and records
+// the breach. Pull requests locked by another replica are skipped, and
+// assignments answered, changed or already handled since they were listed
+// are refused with SLA_NOT_BREACHED. When pick finds nobody the reviewer
+// stays, the breach is still recorded and the returned reviewer ID is empty.
+func (p *PGPullRequestStorage) ReassignOverdueReviewer(ctx context.Context, assignment models.SLAAssignment, teamID int, pick storage.ReviewerPicker) (models.PullRequest, string, error) {
+	slog.Debug("Reassigning overdue reviewer in PG", "prID", assignment.PullRequestID, "reviewerID", assignment.ReviewerID, "teamID", teamID)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PullRequest{}, "", fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets replicas running the same check pass over each other's
+	// pull requests instead of queueing behind them.
+	var prDBID int
+	var pr models.PullRequest
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, pull_request_id, name, author_id, status, merged_at, COALESCE(selection_seed, ''),
+		       COALESCE(team_id, 0), COALESCE((SELECT t.name FROM teams t WHERE t.id = pull_requests.team_id), ''),
+		       missing_reviewers
+		FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE SKIP LOCKED
+	`, assignment.PullRequestID).Scan(&prDBID, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt, &pr.SelectionSeed, &pr.TeamID, &pr.TeamName,
+		&pr.MissingReviewers)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PullRequest{}, "", errors.New("SLA_NOT_BREACHED")
+		}
+		return models.PullRequest{}, "", fmt.Errorf("get pull request: %w", err)
+	}
+
+	if pr.Status != models.StatusOpen {
+		return models.PullRequest{}, "", errors.New("SLA_NOT_BREACHED")
+	}
+
+	var stillOverdue bool
+	err = tx.GetContext(ctx, &stillOverdue, `
+		SELECT EXISTS(
+			SELECT 1 FROM pull_request_reviewers prr
+			WHERE prr.pull_request_id = $1
+			  AND prr.reviewer_id = $2
+			  AND prr.assigned_at::TIMESTAMPTZ = $3
+			  AND prr.sla_breached_at IS NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM pull_request_reviews r
+				WHERE r.pull_request_id = prr.pull_request_id
+				  AND r.reviewer_id = prr.reviewer_id
+				  AND r.created_at >= prr.assigned_at))
+	`, prDBID, assignment.ReviewerID, assignment.AssignedAt)
+	if err != nil {
+		return models.PullRequest{}, "", fmt.Errorf("check assignment overdue: %w", err)
+	}
+	if !stillOverdue {
+		return models.PullRequest{}, "", errors.New("SLA_NOT_BREACHED")
+	}
+
+	pr.Labels, err = selectLabels(ctx, tx, prDBID)
+	if err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	events := []pullRequestEvent{{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+		Type:       models.EventSLABreached,
+		ReviewerID: assignment.ReviewerID,
+	}}}
+
+	newReviewerID, err := replaceReviewer(ctx, tx, prDBID, pr, assignment.ReviewerID, teamID, pick)
+	switch {
+	case err == nil:
+		events = append(events, pullRequestEvent{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+			Type:          models.EventReviewerReassigned,
+			ReviewerID:    newReviewerID,
+			OldReviewerID: assignment.ReviewerID,
+			Reason:        models.EventReasonSLABreach,
+		}})
+	case err.Error() == "NO_CANDIDATE":
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pull_request_reviewers SET sla_breached_at = NOW()
+			WHERE pull_request_id = $1 AND reviewer_id = $2
+		`, prDBID, assignment.ReviewerID)
+		if err != nil {
+			return models.PullRequest{}, "", fmt.Errorf("mark SLA breached: %w", err)
+		}
+	default:
+		return models.PullRequest{}, "", err
+	}
+
+	if err = insertEvents(ctx, tx, events); err != nil {
+		return models.PullRequest{}, "", err
+	}
+
+	err = tx.SelectContext(ctx, &pr.AssignedReviewers, `
+		SELECT reviewer_id FROM pull_request_reviewers WHERE pull_request_id = $1
+	`, prDBID)
+	if err != nil {
+		return models.PullRequest{}, "", fmt.Errorf("get reviewers: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.PullRequest{}, "", fmt.Errorf("commit: %w", err)
+	}
+
+	return pr, newReviewerID, nil
+}
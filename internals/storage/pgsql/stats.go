@@ -0,0 +1,72 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
+)
+
+type PGStatsStorage struct {
+	DB *sqlx.DB
+}
+
+func (p *PGStatsStorage) GetReviewerStats(ctx context.Context, filter models.StatsFilter) ([]models.ReviewerStats, error) {
+	slog.Debug("Getting reviewer stats in PG", "teamID", filter.TeamID)
+
+	stats := []models.ReviewerStats{}
+	err := p.DB.SelectContext(ctx, &stats, `
+		SELECT u.user_id, u.username,
+		       COUNT(prr.reviewer_id) AS total_assignments,
+		       COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open_assignments,
+		       COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged_reviews
+		FROM users u
+		LEFT JOIN (pull_request_reviewers prr
+		           INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id)
+		       ON prr.reviewer_id = u.user_id
+		      AND ($2::TIMESTAMP IS NULL OR prr.assigned_at >= $2)
+		      AND ($3::TIMESTAMP IS NULL OR prr.assigned_at < $3)
+		      AND ($1 = 0 OR `+pullRequestTeam+` = $1)
+		WHERE $1 = 0 OR EXISTS (
+			SELECT 1 FROM team_members tm WHERE tm.user_id = u.user_id AND tm.team_id = $1
+		)
+		GROUP BY u.user_id, u.username
+		ORDER BY total_assignments DESC, u.user_id
+	`, filter.TeamID, filter.From, filter.To)
+	if err != nil {
+		slog.Error("SQL get reviewer stats error", "err", err)
+		return nil, fmt.Errorf("failed to get reviewer stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (p *PGStatsStorage) GetPullRequestStats(ctx context.Context, filter models.StatsFilter) ([]models.PullRequestStats, error) {
+	slog.Debug("Getting pull request stats in PG", "teamID", filter.TeamID)
+
+	stats := []models.PullRequestStats{}
+	err := p.DB.SelectContext(ctx, &stats, `
+		SELECT pr.pull_request_id, pr.name, pr.author_id, pr.status, pr.created_at, pr.merged_at,
+		       (SELECT COUNT(*) FROM pull_request_reviewers prr WHERE prr.pull_request_id = pr.id) AS reviewers_count
+		FROM pull_requests pr
+		WHERE ($1 = 0 OR `+pullRequestTeam+` = $1)
+		  AND ($2::TIMESTAMP IS NULL OR pr.created_at >= $2)
+		  AND ($3::TIMESTAMP IS NULL OR pr.created_at < $3)
+		ORDER BY pr.created_at DESC, pr.pull_request_id
+	`, filter.TeamID, filter.From, filter.To)
+	if err != nil {
+		slog.Error("SQL get pull request stats error", "err", err)
+		return nil, fmt.Errorf("failed to get pull request stats: %w", err)
+	}
+
+	for i := range stats {
+		if stats[i].MergedAt.Valid {
+			seconds := int64(stats[i].MergedAt.Time.Sub(stats[i].CreatedAt).Seconds())
+			stats[i].TimeToMergeSeconds = &seconds
+		}
+	}
+
+	return stats, nil
+}
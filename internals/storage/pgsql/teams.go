@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
 
@@ -34,37 +37,264 @@ func (p *PGTeamStorage) AddTeam(ctx context.Context, team models.Team) (models.T
 	}
 
 	var teamID int
-	err = tx.QueryRowContext(ctx, "INSERT INTO teams (name) VALUES ($1) RETURNING id", team.Name).Scan(&teamID)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO teams (name) VALUES ($1)
+		RETURNING id, reviewer_strategy, required_reviewers, min_reviewers, max_reviewers, merge_policy, review_sla,
+			max_open_reviews
+	`, team.Name).Scan(
+		&teamID,
+		&team.Settings.ReviewerStrategy,
+		&team.Settings.RequiredReviewers,
+		&team.Settings.MinReviewers,
+		&team.Settings.MaxReviewers,
+		&team.Settings.MergePolicy,
+		&team.Settings.ReviewSLA,
+		&team.Settings.MaxOpenReviews,
+	)
 	if err != nil {
 		return models.Team{}, fmt.Errorf("insert team: %w", err)
 	}
 
-	for _, member := range team.Members {
-		_, err = tx.ExecContext(ctx, `
+	if err = upsertMembers(ctx, tx, teamID, team.Members); err != nil {
+		return models.Team{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Team{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	team.ID = teamID
+	return team, nil
+}
+
+// upsertMembers adds members to a team, creating unknown users and updating
+// the name and activity of known ones. The first team a user joins becomes
+// their primary one.
+func upsertMembers(ctx context.Context, tx *sqlx.Tx, teamID int, members []models.User) error {
+	for _, member := range members {
+		_, err := tx.ExecContext(ctx, `
 			INSERT INTO users (user_id, username, is_active)
 			VALUES ($1, $2, $3)
 			ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, is_active = EXCLUDED.is_active
 		`, member.ID, member.Username, member.IsActive)
 		if err != nil {
-			return models.Team{}, fmt.Errorf("upsert user %s: %w", member.ID, err)
+			return fmt.Errorf("upsert user %s: %w", member.ID, err)
+		}
+
+		if member.Tags != nil {
+			if err = replaceUserTags(ctx, tx, member.ID, member.Tags); err != nil {
+				return err
+			}
 		}
 
+		// The upsert above locks the user, so no other membership of theirs can
+		// become primary concurrently.
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO team_members (team_id, user_id)
-			VALUES ($1, $2)
+			INSERT INTO team_members (team_id, user_id, is_primary)
+			VALUES ($1, $2, NOT EXISTS(SELECT 1 FROM team_members WHERE user_id = $2 AND is_primary))
 			ON CONFLICT DO NOTHING
 		`, teamID, member.ID)
 		if err != nil {
-			return models.Team{}, fmt.Errorf("add team member %s: %w", member.ID, err)
+			return fmt.Errorf("add team member %s: %w", member.ID, err)
 		}
 	}
+	return nil
+}
+
+// promotePrimaryTeams makes the earliest remaining team primary for those of
+// the users who have lost their primary membership.
+func promotePrimaryTeams(ctx context.Context, tx *sqlx.Tx, userIDs []string) error {
+	_, err := tx.ExecContext(ctx, `
+		SELECT user_id FROM users WHERE user_id = ANY($1) ORDER BY user_id FOR UPDATE
+	`, userIDs)
+	if err != nil {
+		return fmt.Errorf("lock users: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE team_members tm
+		SET is_primary = true
+		FROM (
+			SELECT DISTINCT ON (user_id) user_id, team_id
+			FROM team_members
+			WHERE user_id = ANY($1)
+			ORDER BY user_id, joined_at, team_id
+		) first
+		WHERE tm.user_id = first.user_id
+		  AND tm.team_id = first.team_id
+		  AND NOT EXISTS (SELECT 1 FROM team_members p WHERE p.user_id = tm.user_id AND p.is_primary)
+	`, userIDs)
+	if err != nil {
+		return fmt.Errorf("promote primary teams: %w", err)
+	}
+	return nil
+}
+
+func (p *PGTeamStorage) AddMembers(ctx context.Context, teamID int, members []models.User) error {
+	slog.Debug("Adding team members in PG", "teamID", teamID, "count", len(members))
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// FOR SHARE keeps the team from being deleted while members join it.
+	var exists bool
+	err = tx.GetContext(ctx, &exists, "SELECT true FROM teams WHERE id = $1 FOR SHARE", teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("NOT_FOUND")
+		}
+		return fmt.Errorf("get team: %w", err)
+	}
+
+	if err = upsertMembers(ctx, tx, teamID, members); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *PGTeamStorage) RemoveMembers(ctx context.Context, teamID int, userIDs []string) error {
+	slog.Debug("Removing team members in PG", "teamID", teamID, "userIDs", userIDs)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var removed []string
+	err = tx.SelectContext(ctx, &removed, `
+		DELETE FROM team_members WHERE team_id = $1 AND user_id = ANY($2)
+		RETURNING user_id
+	`, teamID, userIDs)
+	if err != nil {
+		return fmt.Errorf("remove team members: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if !slices.Contains(removed, userID) {
+			return errors.New("NOT_TEAM_MEMBER")
+		}
+	}
+
+	if err = promotePrimaryTeams(ctx, tx, removed); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *PGTeamStorage) RenameTeam(ctx context.Context, teamName, newName string) (models.Team, error) {
+	slog.Debug("Renaming team in PG", "teamName", teamName, "newName", newName)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID int
+	err = tx.GetContext(ctx, &teamID, "SELECT id FROM teams WHERE name = $1 FOR UPDATE", teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Team{}, errors.New("NOT_FOUND")
+		}
+		return models.Team{}, fmt.Errorf("get team: %w", err)
+	}
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)", newName)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("check team exists: %w", err)
+	}
+	if exists {
+		return models.Team{}, errors.New("TEAM_EXISTS")
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE teams SET name = $1 WHERE id = $2", newName, teamID); err != nil {
+		return models.Team{}, fmt.Errorf("rename team: %w", err)
+	}
 
 	if err = tx.Commit(); err != nil {
 		return models.Team{}, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	team.ID = teamID
-	return team, nil
+	return p.GetTeamByName(ctx, newName)
+}
+
+// DeleteTeam locks the members before checking their reviews, so no reviewer
+// can be assigned from the team while it is being deleted. Users stay, along
+// with their other memberships, the earliest of which becomes primary for
+// those whose primary team this was; teams falling back on this one lose it.
+func (p *PGTeamStorage) DeleteTeam(ctx context.Context, teamName string) error {
+	slog.Debug("Deleting team in PG", "teamName", teamName)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID int
+	err = tx.GetContext(ctx, &teamID, "SELECT id FROM teams WHERE name = $1 FOR UPDATE", teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("NOT_FOUND")
+		}
+		return fmt.Errorf("get team: %w", err)
+	}
+
+	var memberIDs []string
+	err = tx.SelectContext(ctx, &memberIDs, `
+		SELECT u.user_id
+		FROM users u
+		INNER JOIN team_members tm ON tm.user_id = u.user_id
+		WHERE tm.team_id = $1
+		ORDER BY u.user_id
+		FOR UPDATE OF u
+	`, teamID)
+	if err != nil {
+		return fmt.Errorf("lock team members: %w", err)
+	}
+
+	var reviewing bool
+	err = tx.GetContext(ctx, &reviewing, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM pull_request_reviewers prr
+			INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+			INNER JOIN team_members tm ON tm.user_id = prr.reviewer_id
+			WHERE tm.team_id = $1 AND pr.status = 'OPEN'
+		)
+	`, teamID)
+	if err != nil {
+		return fmt.Errorf("check open reviews: %w", err)
+	}
+	if reviewing {
+		return errors.New("TEAM_HAS_OPEN_REVIEWS")
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM teams WHERE id = $1", teamID); err != nil {
+		return fmt.Errorf("delete team: %w", err)
+	}
+
+	if err = promotePrimaryTeams(ctx, tx, memberIDs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
 }
 
 func (p *PGTeamStorage) GetTeamByName(ctx context.Context, name string) (models.Team, error) {
@@ -89,53 +319,490 @@ func (p *PGTeamStorage) GetTeamByName(ctx context.Context, name string) (models.
 		return models.Team{}, fmt.Errorf("get team members: %w", err)
 	}
 
+	var tags []struct {
+		UserID string `db:"user_id"`
+		Tag    string `db:"tag"`
+	}
+	err = p.DB.SelectContext(ctx, &tags, `
+		SELECT ut.user_id, ut.tag
+		FROM user_tags ut
+		INNER JOIN team_members tm ON ut.user_id = tm.user_id
+		WHERE tm.team_id = $1
+		ORDER BY ut.tag
+	`, team.ID)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("get member tags: %w", err)
+	}
+
+	tagsByUser := make(map[string][]string)
+	for _, t := range tags {
+		tagsByUser[t.UserID] = append(tagsByUser[t.UserID], t.Tag)
+	}
+	for i := range team.Members {
+		team.Members[i].Tags = tagsByUser[team.Members[i].ID]
+	}
+
+	team.Settings, err = p.GetTeamSettings(ctx, team.ID)
+	if err != nil {
+		return models.Team{}, err
+	}
+
 	return team, nil
 }
 
-func (p *PGTeamStorage) GetRandomReviewers(ctx context.Context, teamID int, authorID string, limit int) ([]string, error) {
-	slog.Debug("Getting random reviewers in PG", "teamID", teamID, "authorID", authorID, "limit", limit)
+func (p *PGTeamStorage) GetTeamSettings(ctx context.Context, teamID int) (models.TeamSettings, error) {
+	slog.Debug("Getting team settings in PG", "teamID", teamID)
 
-	var reviewers []string
-	err := p.DB.SelectContext(ctx, &reviewers, `
-  SELECT u.user_id
-  FROM users u
-  INNER JOIN team_members tm ON u.user_id = tm.user_id
-  WHERE tm.team_id = $1
-   AND u.is_active = true
-   AND u.user_id != $2
-  ORDER BY RANDOM()
-  LIMIT $3
- `, teamID, authorID, limit)
+	var settings models.TeamSettings
+	err := p.DB.GetContext(ctx, &settings, `
+		SELECT reviewer_strategy, required_reviewers, min_reviewers, max_reviewers, merge_policy, review_sla,
+			max_open_reviews
+		FROM teams
+		WHERE id = $1
+	`, teamID)
 	if err != nil {
-		slog.Error("SQL get random reviewers error", "err", err)
-		return nil, fmt.Errorf("failed to get random reviewers: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TeamSettings{}, errors.New("NOT_FOUND")
+		}
+		return models.TeamSettings{}, fmt.Errorf("get team settings: %w", err)
+	}
+
+	settings.FallbackTeams = []string{}
+	err = p.DB.SelectContext(ctx, &settings.FallbackTeams, `
+		SELECT t.name
+		FROM team_fallbacks tf
+		INNER JOIN teams t ON t.id = tf.fallback_team_id
+		WHERE tf.team_id = $1
+		ORDER BY tf.position
+	`, teamID)
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("get fallback teams: %w", err)
 	}
 
-	return reviewers, nil
+	return settings, nil
 }
 
-func (p *PGTeamStorage) GetReplacementCandidate(ctx context.Context, teamID int, excludeUserIDs []string) (string, error) {
-	slog.Debug("Getting replacement candidate in PG", "teamID", teamID, "excludeIDs", excludeUserIDs)
+func (p *PGTeamStorage) UpdateTeamSettings(ctx context.Context, teamName string, settings models.TeamSettings) (models.Team, error) {
+	slog.Debug("Updating team settings in PG", "teamName", teamName, "settings", settings)
 
-	query := `
-  SELECT u.user_id
-  FROM users u
-  INNER JOIN team_members tm ON u.user_id = tm.user_id
-  WHERE tm.team_id = $1
-   AND u.is_active = true
-   AND u.user_id != ALL($2)
-  ORDER BY RANDOM()
-  LIMIT 1
- `
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	var candidateID string
-	err := p.DB.GetContext(ctx, &candidateID, query, teamID, excludeUserIDs)
+	var teamID int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE teams
+		SET reviewer_strategy = $1, required_reviewers = $2, min_reviewers = $3, max_reviewers = $4,
+		    merge_policy = $5, review_sla = $6, max_open_reviews = $7
+		WHERE name = $8
+		RETURNING id
+	`, settings.ReviewerStrategy, settings.RequiredReviewers, settings.MinReviewers, settings.MaxReviewers,
+		settings.MergePolicy, settings.ReviewSLA, settings.MaxOpenReviews, teamName).Scan(&teamID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", errors.New("NO_CANDIDATE")
+			return models.Team{}, errors.New("NOT_FOUND")
+		}
+		return models.Team{}, fmt.Errorf("update team settings: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM team_fallbacks WHERE team_id = $1", teamID)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("clear fallback teams: %w", err)
+	}
+
+	for i, fallbackName := range settings.FallbackTeams {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO team_fallbacks (team_id, fallback_team_id, position)
+			SELECT $1, id, $2 FROM teams WHERE name = $3
+		`, teamID, i+1, fallbackName)
+		if err != nil {
+			return models.Team{}, fmt.Errorf("add fallback team %s: %w", fallbackName, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return models.Team{}, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return models.Team{}, errors.New("FALLBACK_NOT_FOUND")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Team{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return p.GetTeamByName(ctx, teamName)
+}
+
+func (p *PGTeamStorage) DeactivateMembers(ctx context.Context, teamID int, userIDs []string, pickFor storage.TeamReviewerPicker) ([]models.ReviewerReassignment, error) {
+	slog.Debug("Deactivating team members in PG", "teamID", teamID, "userIDs", userIDs)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Pull requests are locked before reviewers, in the same order as
+	// ReassignReviewer does, so the two cannot deadlock.
+	var assignments []struct {
+		PRDBID        int    `db:"id"`
+		PullRequestID string `db:"pull_request_id"`
+		AuthorID      string `db:"author_id"`
+		TeamID        int    `db:"team_id"`
+		ReviewerID    string `db:"reviewer_id"`
+	}
+	err = tx.SelectContext(ctx, &assignments, `
+		SELECT pr.id, pr.pull_request_id, pr.author_id, COALESCE(`+pullRequestTeam+`, 0) AS team_id, prr.reviewer_id
+		FROM pull_request_reviewers prr
+		INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+		WHERE pr.status = 'OPEN'
+			AND prr.reviewer_id = ANY($1)
+		ORDER BY pr.created_at, pr.id, prr.reviewer_id
+		FOR UPDATE OF pr
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get open assignments: %w", err)
+	}
+
+	prDBIDs := make([]int, 0, len(assignments))
+	var teamIDs []int
+	for _, a := range assignments {
+		prDBIDs = append(prDBIDs, a.PRDBID)
+		if a.TeamID != 0 && !slices.Contains(teamIDs, a.TeamID) {
+			teamIDs = append(teamIDs, a.TeamID)
+		}
+	}
+
+	if err = lockCandidates(ctx, tx, teamIDs, true, nil); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET is_active = false WHERE user_id = ANY($1)", userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("deactivate users: %w", err)
+	}
+
+	var reviewerRows []struct {
+		PRDBID     int    `db:"pull_request_id"`
+		ReviewerID string `db:"reviewer_id"`
+	}
+	err = tx.SelectContext(ctx, &reviewerRows, `
+		SELECT pull_request_id, reviewer_id
+		FROM pull_request_reviewers
+		WHERE pull_request_id = ANY($1)
+	`, prDBIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get current reviewers: %w", err)
+	}
+
+	reviewersByPR := make(map[int]map[string]bool)
+	for _, row := range reviewerRows {
+		if reviewersByPR[row.PRDBID] == nil {
+			reviewersByPR[row.PRDBID] = make(map[string]bool)
+		}
+		reviewersByPR[row.PRDBID][row.ReviewerID] = true
+	}
+
+	// Tags matching the labels of each pull request are counted for all of
+	// them at once instead of selecting candidates per pull request.
+	var matchRows []struct {
+		PRDBID       int    `db:"pull_request_id"`
+		UserID       string `db:"user_id"`
+		MatchingTags int    `db:"matching_tags"`
+	}
+	err = tx.SelectContext(ctx, &matchRows, `
+		SELECT l.pull_request_id, ut.user_id, COUNT(*) AS matching_tags
+		FROM pull_request_labels l
+		INNER JOIN user_tags ut ON ut.tag = l.label
+		WHERE l.pull_request_id = ANY($1)
+		GROUP BY l.pull_request_id, ut.user_id
+	`, prDBIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get matching tags: %w", err)
+	}
+
+	matchingTags := make(map[int]map[string]int)
+	for _, row := range matchRows {
+		if matchingTags[row.PRDBID] == nil {
+			matchingTags[row.PRDBID] = make(map[string]int)
+		}
+		matchingTags[row.PRDBID][row.UserID] = row.MatchingTags
+	}
+
+	var excludedRows []struct {
+		PRDBID int    `db:"pull_request_id"`
+		UserID string `db:"user_id"`
+	}
+	err = tx.SelectContext(ctx, &excludedRows, `
+		SELECT pull_request_id, user_id
+		FROM pull_request_reviewer_preferences
+		WHERE pull_request_id = ANY($1) AND kind = 'EXCLUDED'
+	`, prDBIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get excluded reviewers: %w", err)
+	}
+
+	excluded := make(map[int]map[string]bool)
+	for _, row := range excludedRows {
+		if excluded[row.PRDBID] == nil {
+			excluded[row.PRDBID] = make(map[string]bool)
+		}
+		excluded[row.PRDBID][row.UserID] = true
+	}
+
+	candidatesByTeam := make(map[int][]models.ReviewerCandidate, len(teamIDs))
+	picks := make(map[int]storage.ReviewerPicker, len(teamIDs))
+	for _, id := range teamIDs {
+		candidatesByTeam[id], err = selectReviewerCandidates(ctx, tx, candidatePool{
+			TeamID:         id,
+			WithFallbacks:  true,
+			ExcludeUserIDs: userIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if picks[id], err = pickFor(id); err != nil {
+			return nil, fmt.Errorf("get reviewer picker for team %d: %w", id, err)
+		}
+	}
+
+	var removedPRs, addedPRs []int
+	var removedReviewers, addedReviewers []string
+	var addedFallbacks []bool
+	outcomes := make([]models.ReviewerReassignment, 0, len(assignments))
+	// Reviews handed out below count towards the load of their reviewers
+	// for the pull requests that follow, whichever team they come from.
+	assigned := make(map[string]int)
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+
+	for _, a := range assignments {
+		current := reviewersByPR[a.PRDBID]
+
+		var eligible []models.ReviewerCandidate
+		for _, c := range candidatesByTeam[a.TeamID] {
+			if n := assigned[c.UserID]; n > 0 {
+				c.OpenReviews += n
+				c.LastAssignedAt = now
+			}
+			c.MatchingTags = matchingTags[a.PRDBID][c.UserID]
+			if c.UserID != a.AuthorID && !current[c.UserID] && !excluded[a.PRDBID][c.UserID] && !c.AtCapacity() {
+				eligible = append(eligible, c)
+			}
+		}
+
+		outcome := models.ReviewerReassignment{
+			PullRequestID: a.PullRequestID,
+			OldReviewerID: a.ReviewerID,
+		}
+
+		var picked []string
+		if len(eligible) > 0 {
+			picked = picks[a.TeamID](eligible)
+		}
+		if len(picked) == 0 {
+			outcome.Status = "NO_CANDIDATE"
+			outcomes = append(outcomes, outcome)
+			continue
+		}
+
+		newReviewerID := picked[0]
+		outcome.NewReviewerID = newReviewerID
+		outcome.Status = "REPLACED"
+		outcomes = append(outcomes, outcome)
+
+		isFallback := false
+		for _, c := range eligible {
+			if c.UserID == newReviewerID {
+				isFallback = c.Tier > 0
+			}
+		}
+
+		removedPRs = append(removedPRs, a.PRDBID)
+		removedReviewers = append(removedReviewers, a.ReviewerID)
+		addedPRs = append(addedPRs, a.PRDBID)
+		addedReviewers = append(addedReviewers, newReviewerID)
+		addedFallbacks = append(addedFallbacks, isFallback)
+
+		delete(current, a.ReviewerID)
+		current[newReviewerID] = true
+		assigned[newReviewerID]++
+	}
+
+	if len(removedPRs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM pull_request_reviewers prr
+			USING unnest($1::INTEGER[], $2::VARCHAR[]) AS d(pull_request_id, reviewer_id)
+			WHERE prr.pull_request_id = d.pull_request_id
+				AND prr.reviewer_id = d.reviewer_id
+		`, removedPRs, removedReviewers)
+		if err != nil {
+			return nil, fmt.Errorf("delete replaced reviewers: %w", err)
 		}
-		return "", fmt.Errorf("failed to get replacement candidate: %w", err)
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id, is_fallback)
+			SELECT * FROM unnest($1::INTEGER[], $2::VARCHAR[], $3::BOOLEAN[])
+		`, addedPRs, addedReviewers, addedFallbacks)
+		if err != nil {
+			return nil, fmt.Errorf("insert replacement reviewers: %w", err)
+		}
+
+		events := make([]pullRequestEvent, 0, len(addedPRs))
+		for i, prDBID := range addedPRs {
+			events = append(events, pullRequestEvent{PRDBID: prDBID, PullRequestEvent: models.PullRequestEvent{
+				Type:          models.EventReviewerReassigned,
+				ReviewerID:    addedReviewers[i],
+				OldReviewerID: removedReviewers[i],
+				Reason:        models.EventReasonDeactivation,
+			}})
+		}
+		if err = insertEvents(ctx, tx, events); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	return candidateID, nil
+	return outcomes, nil
+}
+
+// availableUser holds for the user u when they are active and not in the
+// middle of an absence.
+const availableUser = `u.is_active = true
+			AND NOT EXISTS (
+				SELECT 1 FROM user_absences ua
+				WHERE ua.user_id = u.user_id AND ua.starts_at <= NOW() AND ua.ends_at > NOW()
+			)`
+
+// reviewLimit is the max_open_reviews in effect for the user u: their own or
+// else the default of their primary team, 0 meaning no limit.
+const reviewLimit = `COALESCE(u.max_open_reviews, (
+				SELECT t.max_open_reviews
+				FROM team_members tm
+				INNER JOIN teams t ON t.id = tm.team_id
+				WHERE tm.user_id = u.user_id AND tm.is_primary
+			), 0)`
+
+// candidatePool describes who may be picked as a reviewer: the available
+// members of a team, optionally of its fallback teams, and the preferred
+// users.
+type candidatePool struct {
+	TeamID           int
+	WithFallbacks    bool
+	PreferredUserIDs []string
+	ExcludeUserIDs   []string
+	// Labels of the pull request, counted against candidate tags.
+	Labels []string
+	// WithAtCapacity also returns the users who reached their review limit.
+	// They are left out otherwise.
+	WithAtCapacity bool
+}
+
+// lockTeamCandidates locks the users of pool until the end of tx, so
+// concurrent assignments see each other's reviewer load.
+func lockTeamCandidates(ctx context.Context, tx *sqlx.Tx, pool candidatePool) error {
+	return lockCandidates(ctx, tx, []int{pool.TeamID}, pool.WithFallbacks, pool.PreferredUserIDs)
+}
+
+// lockCandidates locks the candidates of several teams in one statement, so
+// the locks are always taken in user_id order.
+func lockCandidates(ctx context.Context, tx *sqlx.Tx, teamIDs []int, withFallbacks bool, preferredUserIDs []string) error {
+	if teamIDs == nil {
+		teamIDs = []int{}
+	}
+	_, err := tx.ExecContext(ctx, `
+		SELECT u.user_id
+		FROM users u
+		WHERE `+availableUser+`
+			AND (
+				u.user_id = ANY($3)
+				OR EXISTS (
+					SELECT 1
+					FROM team_members tm
+					WHERE tm.user_id = u.user_id
+						AND (
+							tm.team_id = ANY($1)
+							OR ($2 AND tm.team_id IN (SELECT fallback_team_id FROM team_fallbacks WHERE team_id = ANY($1)))
+						)
+				)
+			)
+		ORDER BY u.user_id
+		FOR UPDATE OF u
+	`, teamIDs, withFallbacks, nonNil(preferredUserIDs))
+	if err != nil {
+		return fmt.Errorf("lock team candidates: %w", err)
+	}
+
+	return nil
+}
+
+// selectReviewerCandidates returns the available users of pool together with
+// the number of OPEN pull requests they review, their latest assignment time
+// and how many of their tags match the labels. Fallback team members carry
+// their tier and preferred users carry models.PreferredTier whatever their
+// team. Capacity is checked here rather than in lockTeamCandidates: only once
+// the candidates are locked does a new statement see the reviews that
+// concurrent assignments gave them.
+func selectReviewerCandidates(ctx context.Context, q sqlx.QueryerContext, pool candidatePool) ([]models.ReviewerCandidate, error) {
+	var candidates []models.ReviewerCandidate
+	err := sqlx.SelectContext(ctx, q, &candidates, `
+		WITH pools AS (
+			SELECT $1::INTEGER AS team_id, 0 AS tier
+			UNION ALL
+			SELECT fallback_team_id, position
+			FROM team_fallbacks
+			WHERE team_id = $1 AND $3
+		),
+		pool_users AS (
+			SELECT tm.user_id, pools.tier
+			FROM pools
+			INNER JOIN team_members tm ON tm.team_id = pools.team_id
+			UNION ALL
+			SELECT preferred.user_id, $5::INTEGER
+			FROM unnest($4::VARCHAR[]) AS preferred(user_id)
+		)
+		SELECT DISTINCT ON (u.user_id)
+			u.user_id,
+			COALESCE(load.open_reviews, 0) AS open_reviews,
+			load.last_assigned_at,
+			pool_users.tier,
+			(SELECT COUNT(*) FROM user_tags ut WHERE ut.user_id = u.user_id AND ut.tag = ANY($6)) AS matching_tags,
+			`+reviewLimit+` AS review_limit
+		FROM pool_users
+		INNER JOIN users u ON u.user_id = pool_users.user_id
+		LEFT JOIN (
+			SELECT prr.reviewer_id,
+				COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open_reviews,
+				MAX(prr.assigned_at) AS last_assigned_at
+			FROM pull_request_reviewers prr
+			INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+			GROUP BY prr.reviewer_id
+		) load ON load.reviewer_id = u.user_id
+		WHERE `+availableUser+`
+			AND u.user_id != ALL($2)
+			AND ($7 OR (COALESCE(load.open_reviews, 0) < NULLIF(`+reviewLimit+`, 0)) IS NOT FALSE)
+		ORDER BY u.user_id, pool_users.tier
+	`, pool.TeamID, nonNil(pool.ExcludeUserIDs), pool.WithFallbacks, nonNil(pool.PreferredUserIDs), models.PreferredTier, nonNil(pool.Labels),
+		pool.WithAtCapacity)
+	if err != nil {
+		slog.Error("SQL get reviewer candidates error", "err", err)
+		return nil, fmt.Errorf("select reviewer candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// nonNil turns a nil slice into an empty one, which pgx encodes as an empty
+// array rather than NULL.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
 }
@@ -36,6 +36,35 @@ func (p *PGUserStorage) SetIsActive(ctx context.Context, userID string, isActive
 	return nil
 }
 
+func (p *PGUserStorage) SetTags(ctx context.Context, userID string, tags []string) error {
+	slog.Debug("Setting user tags in PG", "userID", userID, "tags", tags)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", userID)
+	if err != nil {
+		return fmt.Errorf("check user exists: %w", err)
+	}
+	if !exists {
+		return errors.New("NOT_FOUND")
+	}
+
+	if err = replaceUserTags(ctx, tx, userID, tags); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (p *PGUserStorage) GetIsActive(ctx context.Context, userID string) (bool, error) {
 	slog.Debug("Getting user active status in PG", "userID", userID)
 
@@ -90,9 +119,10 @@ func (p *PGUserStorage) GetUserTeamID(ctx context.Context, userID string) (int,
 
 	var teamID int
 	err := p.DB.GetContext(ctx, &teamID, `
-		SELECT team_id 
-		FROM team_members 
-		WHERE user_id = $1 
+		SELECT team_id
+		FROM team_members
+		WHERE user_id = $1
+		ORDER BY is_primary DESC, joined_at, team_id
 		LIMIT 1
 	`, userID)
 	if err != nil {
@@ -105,3 +135,253 @@ func (p *PGUserStorage) GetUserTeamID(ctx context.Context, userID string) (int,
 
 	return teamID, nil
 }
+
+func (p *PGUserStorage) SetPrimaryTeam(ctx context.Context, userID string, teamID int) error {
+	slog.Debug("Setting user primary team in PG", "userID", userID, "teamID", teamID)
+
+	tx, err := p.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the user serialises this with membership changes of the same
+	// user, which may promote another team.
+	var lockedID string
+	err = tx.GetContext(ctx, &lockedID, "SELECT user_id FROM users WHERE user_id = $1 FOR UPDATE", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("NOT_FOUND")
+		}
+		return fmt.Errorf("lock user: %w", err)
+	}
+
+	var member bool
+	err = tx.GetContext(ctx, &member, `
+		SELECT EXISTS(SELECT 1 FROM team_members WHERE user_id = $1 AND team_id = $2)
+	`, userID, teamID)
+	if err != nil {
+		return fmt.Errorf("check team membership: %w", err)
+	}
+	if !member {
+		return errors.New("NOT_TEAM_MEMBER")
+	}
+
+	// Two statements keep idx_team_members_primary satisfied at every step.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE team_members SET is_primary = false
+		WHERE user_id = $1 AND is_primary AND team_id <> $2
+	`, userID, teamID)
+	if err != nil {
+		return fmt.Errorf("clear primary team: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE team_members SET is_primary = true
+		WHERE user_id = $1 AND team_id = $2
+	`, userID, teamID)
+	if err != nil {
+		return fmt.Errorf("set primary team: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *PGUserStorage) GetUser(ctx context.Context, userID string) (models.UserProfile, error) {
+	slog.Debug("Getting user in PG", "userID", userID)
+
+	var user models.UserProfile
+	err := p.DB.GetContext(ctx, &user, "SELECT user_id, username, is_active FROM users WHERE user_id = $1", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserProfile{}, errors.New("NOT_FOUND")
+		}
+		return models.UserProfile{}, fmt.Errorf("get user: %w", err)
+	}
+
+	users := []models.UserProfile{user}
+	if err = p.loadUserProfiles(ctx, users); err != nil {
+		return models.UserProfile{}, err
+	}
+	return users[0], nil
+}
+
+func (p *PGUserStorage) ListUsers(ctx context.Context, filter models.UserFilter) (models.UserPage, error) {
+	slog.Debug("Listing users in PG", "search", filter.Search, "teamID", filter.TeamID, "after", filter.After)
+
+	var isActive sql.NullBool
+	if filter.IsActive != nil {
+		isActive = sql.NullBool{Bool: *filter.IsActive, Valid: true}
+	}
+
+	users := []models.UserProfile{}
+	err := p.DB.SelectContext(ctx, &users, `
+		SELECT u.user_id, u.username, u.is_active
+		FROM users u
+		WHERE ($1 = '' OR strpos(lower(u.username), lower($1)) > 0)
+		  AND ($2 = 0 OR EXISTS (
+			SELECT 1 FROM team_members tm WHERE tm.user_id = u.user_id AND tm.team_id = $2))
+		  AND ($3::BOOLEAN IS NULL OR u.is_active = $3)
+		  AND u.user_id > $4
+		ORDER BY u.user_id
+		LIMIT $5
+	`, filter.Search, filter.TeamID, isActive, filter.After, filter.Limit+1)
+	if err != nil {
+		slog.Error("SQL list users error", "err", err)
+		return models.UserPage{}, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	var page models.UserPage
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		page.NextAfter = users[len(users)-1].ID
+	}
+
+	if err = p.loadUserProfiles(ctx, users); err != nil {
+		return models.UserPage{}, err
+	}
+	page.Users = users
+	return page, nil
+}
+
+func (p *PGUserStorage) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) error {
+	slog.Debug("Setting user review capacity in PG", "userID", userID, "maxOpenReviews", maxOpenReviews)
+
+	result, err := p.DB.ExecContext(ctx, "UPDATE users SET max_open_reviews = $1 WHERE user_id = $2", maxOpenReviews, userID)
+	if err != nil {
+		slog.Error("SQL update user review capacity error", "err", err)
+		return fmt.Errorf("failed to update user review capacity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return errors.New("NOT_FOUND")
+	}
+	return nil
+}
+
+func (p *PGUserStorage) GetReviewCapacity(ctx context.Context, userID string) (models.ReviewCapacity, error) {
+	capacities, err := selectReviewCapacities(ctx, p.DB, []string{userID})
+	if err != nil {
+		return models.ReviewCapacity{}, err
+	}
+	capacity, ok := capacities[userID]
+	if !ok {
+		return models.ReviewCapacity{}, errors.New("NOT_FOUND")
+	}
+	return capacity, nil
+}
+
+// selectReviewCapacities returns the review capacity of the users found among
+// userIDs.
+func selectReviewCapacities(ctx context.Context, q sqlx.QueryerContext, userIDs []string) (map[string]models.ReviewCapacity, error) {
+	var rows []struct {
+		UserID string `db:"user_id"`
+		models.ReviewCapacity
+	}
+	err := sqlx.SelectContext(ctx, q, &rows, `
+		SELECT u.user_id, u.max_open_reviews, `+reviewLimit+` AS review_limit,
+			(SELECT COUNT(*)
+			 FROM pull_request_reviewers prr
+			 INNER JOIN pull_requests pr ON pr.id = prr.pull_request_id
+			 WHERE prr.reviewer_id = u.user_id AND pr.status = 'OPEN') AS open_reviews
+		FROM users u
+		WHERE u.user_id = ANY($1)
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get review capacity: %w", err)
+	}
+
+	capacities := make(map[string]models.ReviewCapacity, len(rows))
+	for _, row := range rows {
+		capacities[row.UserID] = row.ReviewCapacity
+	}
+	return capacities, nil
+}
+
+// loadUserProfiles fills tags, teams and review capacity of users with one
+// query each.
+func (p *PGUserStorage) loadUserProfiles(ctx context.Context, users []models.UserProfile) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	var tags []struct {
+		UserID string `db:"user_id"`
+		Tag    string `db:"tag"`
+	}
+	err := p.DB.SelectContext(ctx, &tags, `
+		SELECT user_id, tag FROM user_tags WHERE user_id = ANY($1) ORDER BY tag
+	`, userIDs)
+	if err != nil {
+		return fmt.Errorf("get user tags: %w", err)
+	}
+
+	var teams []struct {
+		UserID string `db:"user_id"`
+		models.TeamMembership
+	}
+	err = p.DB.SelectContext(ctx, &teams, `
+		SELECT tm.user_id, t.name AS team_name, tm.is_primary
+		FROM team_members tm
+		INNER JOIN teams t ON t.id = tm.team_id
+		WHERE tm.user_id = ANY($1)
+		ORDER BY tm.is_primary DESC, tm.joined_at, t.name
+	`, userIDs)
+	if err != nil {
+		return fmt.Errorf("get user teams: %w", err)
+	}
+
+	capacities, err := selectReviewCapacities(ctx, p.DB, userIDs)
+	if err != nil {
+		return err
+	}
+
+	tagsByUser := make(map[string][]string, len(users))
+	for _, t := range tags {
+		tagsByUser[t.UserID] = append(tagsByUser[t.UserID], t.Tag)
+	}
+	teamsByUser := make(map[string][]models.TeamMembership, len(users))
+	for _, t := range teams {
+		teamsByUser[t.UserID] = append(teamsByUser[t.UserID], t.TeamMembership)
+	}
+
+	for i := range users {
+		users[i].Tags = tagsByUser[users[i].ID]
+		users[i].Teams = teamsByUser[users[i].ID]
+		users[i].ReviewCapacity = capacities[users[i].ID]
+		if users[i].Teams == nil {
+			users[i].Teams = []models.TeamMembership{}
+		}
+		// Memberships come primary first.
+		if len(users[i].Teams) > 0 {
+			users[i].TeamName = users[i].Teams[0].TeamName
+		}
+	}
+	return nil
+}
+
+func replaceUserTags(ctx context.Context, tx *sqlx.Tx, userID string, tags []string) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM user_tags WHERE user_id = $1", userID)
+	if err != nil {
+		return fmt.Errorf("clear tags of %s: %w", userID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_tags (user_id, tag)
+		SELECT $1, unnest($2::VARCHAR[])
+	`, userID, nonNil(tags))
+	if err != nil {
+		return fmt.Errorf("insert tags of %s: %w", userID, err)
+	}
+
+	return nil
+}
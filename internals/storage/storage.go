@@ -2,25 +2,135 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
 
+// ReviewerPicker chooses reviewers out of the candidates loaded inside the
+// transaction that assigns them.
+type ReviewerPicker func(candidates []models.ReviewerCandidate) []string
+
+// TeamReviewerPicker returns the ReviewerPicker for pull requests of teamID.
+type TeamReviewerPicker func(teamID int) (ReviewerPicker, error)
+
+// MergeCheck refuses a merge by returning an error. It runs inside the
+// transaction that merges, so the state cannot change underneath it.
+type MergeCheck func(state models.MergeState) error
+
 type TeamStorage interface {
 	AddTeam(ctx context.Context, team models.Team) (models.Team, error)
 	GetTeamByName(ctx context.Context, name string) (models.Team, error)
-	GetRandomReviewers(ctx context.Context, teamID int, authorID string, limit int) ([]string, error)
-	GetReplacementCandidate(ctx context.Context, teamID int, excludeUserIDs []string) (string, error)
+	GetTeamSettings(ctx context.Context, teamID int) (models.TeamSettings, error)
+	UpdateTeamSettings(ctx context.Context, teamName string, settings models.TeamSettings) (models.Team, error)
+	// DeactivateMembers replaces the users on every OPEN pull request with a
+	// reviewer of the pull request's team or its fallback teams, picked by
+	// pickFor of that team.
+	DeactivateMembers(ctx context.Context, teamID int, userIDs []string, pickFor TeamReviewerPicker) ([]models.ReviewerReassignment, error)
+	// AddMembers creates unknown users and updates known ones like AddTeam.
+	AddMembers(ctx context.Context, teamID int, members []models.User) error
+	// RemoveMembers returns NOT_TEAM_MEMBER, removing nobody, unless all the
+	// users are members.
+	RemoveMembers(ctx context.Context, teamID int, userIDs []string) error
+	RenameTeam(ctx context.Context, teamName, newName string) (models.Team, error)
+	// DeleteTeam returns TEAM_HAS_OPEN_REVIEWS while any member reviews an
+	// OPEN pull request.
+	DeleteTeam(ctx context.Context, teamName string) error
 }
 
 type RequestStorage interface {
-	CreatePullRequest(ctx context.Context, pr models.PullRequest, reviewerIDs []string) (models.PullRequest, error)
-	MergePullRequest(ctx context.Context, pullrequestID string) (models.PullRequest, error)
-	ReassignReviewer(ctx context.Context, pullrequestID string, oldReviewerID string, newReviewerID string) (models.PullRequest, error)
+	// CreatePullRequest flags the pull request with MissingReviewers when
+	// candidates at capacity keep pick from filling reviewersCount slots.
+	CreatePullRequest(ctx context.Context, pr models.PullRequest, teamID int, preferredUserIDs []string, reviewersCount int, pick ReviewerPicker) (models.PullRequest, error)
+	GetPullRequest(ctx context.Context, pullrequestID string) (models.PullRequest, error)
+	ListPullRequests(ctx context.Context, filter models.PullRequestFilter) (models.PullRequestPage, error)
+	// MergePullRequest calls check unless the pull request is already merged.
+	MergePullRequest(ctx context.Context, pullrequestID string, check MergeCheck) (models.PullRequest, error)
+	// GetMergeState returns what a merge of the pull request would be checked
+	// against right now.
+	GetMergeState(ctx context.Context, pullrequestID string) (models.MergeState, error)
+	AddReview(ctx context.Context, review models.Review) (models.Review, error)
+	GetReviews(ctx context.Context, pullrequestID string) ([]models.Review, error)
+	// TransitionPullRequest assigns reviewers with pick when a pull request
+	// without any enters OPEN, like CreatePullRequest. It returns
+	// PICKER_REQUIRED, changing nothing, when that happens and pick is nil.
+	TransitionPullRequest(ctx context.Context, pullrequestID string, transition models.PullRequestTransition, teamID int, preferredUserIDs []string, reviewersCount int, pick ReviewerPicker) (models.PullRequest, error)
+	// ReassignReviewer records the replacement with reason, one of the
+	// models.EventReason values.
+	ReassignReviewer(ctx context.Context, pullrequestID string, oldReviewerID string, teamID int, pick ReviewerPicker, reason string) (models.PullRequest, string, error)
+	// AddReviewer accepts active members of the team and its fallback teams,
+	// whatever their review capacity, and fills one missing reviewer slot.
+	AddReviewer(ctx context.Context, change models.ReviewerChange, teamID int) (models.PullRequest, models.ReviewerChange, error)
+	RemoveReviewer(ctx context.Context, change models.ReviewerChange) (models.PullRequest, models.ReviewerChange, error)
+	// UpdatePullRequest records every changed field. When the new author is a
+	// reviewer, pick replaces them with a candidate of teamID.
+	UpdatePullRequest(ctx context.Context, update models.PullRequestUpdate, teamID int, pick ReviewerPicker) (models.PullRequestUpdateResult, error)
+	GetPullRequestChanges(ctx context.Context, pullrequestID string) ([]models.PullRequestChange, error)
+	// GetPullRequestEvents returns the timeline of a pull request, oldest
+	// first.
+	GetPullRequestEvents(ctx context.Context, pullrequestID string) ([]models.PullRequestEvent, error)
+	// ListUnansweredAssignments returns assignments that may be past their
+	// SLA deadline, with the storage clock to check the deadlines against.
+	ListUnansweredAssignments(ctx context.Context) ([]models.SLAAssignment, time.Time, error)
+	// ReassignOverdueReviewer returns SLA_NOT_BREACHED for assignments that
+	// are no longer overdue or are being handled elsewhere.
+	ReassignOverdueReviewer(ctx context.Context, assignment models.SLAAssignment, teamID int, pick ReviewerPicker) (models.PullRequest, string, error)
 }
 type UserStorage interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) error
+	SetTags(ctx context.Context, userID string, tags []string) error
 	GetIsActive(ctx context.Context, userID string) (bool, error)
 	GetUserReviews(ctx context.Context, userID string) ([]models.PullRequest, error)
 	GetUserTeamID(ctx context.Context, userID string) (int, error)
+	GetUserActivity(ctx context.Context, filter models.ActivityFilter) ([]models.PullRequestEvent, error)
+	// SetPrimaryTeam makes teamID the team reviewers of the user's pull
+	// requests are drawn from by default.
+	SetPrimaryTeam(ctx context.Context, userID string, teamID int) error
+	GetUser(ctx context.Context, userID string) (models.UserProfile, error)
+	// ListUsers orders users by ID.
+	ListUsers(ctx context.Context, filter models.UserFilter) (models.UserPage, error)
+	AddAbsence(ctx context.Context, absence models.Absence) (models.Absence, error)
+	// ListAbsences returns the absences of the user that have not ended yet,
+	// earliest first.
+	ListAbsences(ctx context.Context, userID string) ([]models.Absence, error)
+	DeleteAbsence(ctx context.Context, userID string, absenceID int64) error
+	// IsAbsent reports whether an absence of the user is in progress.
+	IsAbsent(ctx context.Context, userID string) (bool, error)
+	// SetMaxOpenReviews sets the review capacity of the user; nil falls back
+	// to the default of their primary team and 0 means no limit.
+	SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) error
+	GetReviewCapacity(ctx context.Context, userID string) (models.ReviewCapacity, error)
+}
+
+type AbsenceStorage interface {
+	// ClaimStartedAbsences returns the absences in progress whose reviews are
+	// to be reassigned and were not yet, with the OPEN pull requests the user
+	// reviews, and records them as claimed. An absence is claimed by one
+	// caller at a time; a claim neither completed nor released expires.
+	ClaimStartedAbsences(ctx context.Context) ([]models.Absence, error)
+	// CompleteAbsence records the reviews of a claimed absence as reassigned.
+	CompleteAbsence(ctx context.Context, absence models.Absence) error
+	// ReleaseAbsence undoes a claim whose reviews are to be retried.
+	ReleaseAbsence(ctx context.Context, absence models.Absence) error
+}
+
+type CodeOwnerStorage interface {
+	AddCodeOwnerRule(ctx context.Context, rule models.CodeOwnerRule) (models.CodeOwnerRule, error)
+	ReplaceCodeOwnerRules(ctx context.Context, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error)
+	GetCodeOwnerRules(ctx context.Context) ([]models.CodeOwnerRule, error)
+}
+
+type ReminderStorage interface {
+	// ClaimReminders returns the active reviewers, out-of-office ones aside,
+	// with reviews pending longer than StaleAfter who were not reminded within
+	// RepeatAfter, and records them as reminded now. A reviewer is claimed by
+	// one caller only.
+	ClaimReminders(ctx context.Context, policy models.ReminderPolicy) ([]models.ReviewReminder, error)
+	// ReleaseReminder undoes a claim that could not be delivered.
+	ReleaseReminder(ctx context.Context, reminder models.ReviewReminder) error
+}
+
+type StatsStorage interface {
+	GetReviewerStats(ctx context.Context, filter models.StatsFilter) ([]models.ReviewerStats, error)
+	GetPullRequestStats(ctx context.Context, filter models.StatsFilter) ([]models.PullRequestStats, error)
 }
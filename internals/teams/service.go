@@ -3,9 +3,15 @@ package teams
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/mergepolicy"
+	"github.com/sssciel/avito-backend-intership/internals/selection"
+	"github.com/sssciel/avito-backend-intership/internals/sla"
 	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
@@ -13,6 +19,8 @@ import (
 type TeamService struct {
 	TeamStorage storage.TeamStorage
 	UserStorage storage.UserStorage
+	// NewRandFn returns the random source reviewer selection uses for seed.
+	NewRandFn func(seed string) *rand.Rand
 }
 
 var teamsPrefix = "team"
@@ -21,6 +29,7 @@ func New(teamStorage storage.TeamStorage, userStorage storage.UserStorage) *Team
 	return &TeamService{
 		TeamStorage: teamStorage,
 		UserStorage: userStorage,
+		NewRandFn:   selection.NewRand,
 	}
 }
 
@@ -29,6 +38,12 @@ func (s *TeamService) RegisterRoutes(r *gin.RouterGroup) {
 
 	teamRouter.POST("/add", s.AddTeam)
 	teamRouter.GET("/get", s.GetTeam)
+	teamRouter.POST("/updateSettings", s.UpdateSettings)
+	teamRouter.POST("/deactivateUsers", s.DeactivateUsers)
+	teamRouter.POST("/addMembers", s.AddMembers)
+	teamRouter.POST("/removeMembers", s.RemoveMembers)
+	teamRouter.POST("/rename", s.RenameTeam)
+	teamRouter.POST("/delete", s.DeleteTeam)
 }
 
 type AddTeamRequest struct {
@@ -36,6 +51,55 @@ type AddTeamRequest struct {
 	Members  []models.User `json:"members" binding:"required,min=1"`
 }
 
+type UpdateSettingsRequest struct {
+	TeamName          string    `json:"team_name" binding:"required"`
+	ReviewerStrategy  *string   `json:"reviewer_strategy"`
+	RequiredReviewers *int      `json:"required_reviewers"`
+	MinReviewers      *int      `json:"min_reviewers"`
+	MaxReviewers      *int      `json:"max_reviewers"`
+	FallbackTeams     *[]string `json:"fallback_teams"`
+	// MergePolicy replaces the whole merge policy of the team.
+	MergePolicy *models.MergePolicy `json:"merge_policy"`
+	// ReviewSLA replaces the whole review SLA of the team.
+	ReviewSLA *models.ReviewSLA `json:"review_sla"`
+	// MaxOpenReviews is the default review capacity of the members.
+	MaxOpenReviews *int `json:"max_open_reviews"`
+}
+
+type DeactivateUsersRequest struct {
+	TeamName string   `json:"team_name" binding:"required"`
+	UserIDs  []string `json:"user_ids" binding:"required,min=1"`
+}
+
+type DeactivateUsersResponse struct {
+	TeamName      string                        `json:"team_name"`
+	Deactivated   []string                      `json:"deactivated_user_ids"`
+	Reassignments []models.ReviewerReassignment `json:"reassignments"`
+}
+
+type AddMembersRequest struct {
+	TeamName string        `json:"team_name" binding:"required"`
+	Members  []models.User `json:"members" binding:"required,min=1"`
+}
+
+type RemoveMembersRequest struct {
+	TeamName string   `json:"team_name" binding:"required"`
+	UserIDs  []string `json:"user_ids" binding:"required,min=1"`
+}
+
+type RenameTeamRequest struct {
+	TeamName    string `json:"team_name" binding:"required"`
+	NewTeamName string `json:"new_team_name" binding:"required"`
+}
+
+type DeleteTeamRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+type DeleteTeamResponse struct {
+	TeamName string `json:"team_name"`
+}
+
 type TeamResponse struct {
 	Team models.Team `json:"team"`
 }
@@ -53,6 +117,12 @@ func (s *TeamService) AddTeam(c *gin.Context) {
 		return
 	}
 
+	for i := range req.Members {
+		if req.Members[i].Tags != nil {
+			req.Members[i].Tags = models.NormalizeTags(req.Members[i].Tags)
+		}
+	}
+
 	team := models.Team{
 		Name:    req.TeamName,
 		Members: req.Members,
@@ -117,3 +187,462 @@ func (s *TeamService) GetTeam(c *gin.Context) {
 
 	c.JSON(http.StatusOK, TeamResponse{Team: team})
 }
+
+func (s *TeamService) UpdateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	team, err := s.TeamStorage.GetTeamByName(ctx, req.TeamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return
+	}
+
+	settings := team.Settings
+	if req.ReviewerStrategy != nil {
+		if !selection.IsKnown(*req.ReviewerStrategy) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "UNKNOWN_STRATEGY",
+					"message": "unknown reviewer_strategy",
+				},
+			})
+			return
+		}
+		settings.ReviewerStrategy = *req.ReviewerStrategy
+	}
+	if req.RequiredReviewers != nil {
+		settings.RequiredReviewers = *req.RequiredReviewers
+	}
+	if req.MinReviewers != nil {
+		settings.MinReviewers = *req.MinReviewers
+	}
+	if req.MaxReviewers != nil {
+		settings.MaxReviewers = *req.MaxReviewers
+	}
+	if req.MergePolicy != nil {
+		settings.MergePolicy = *req.MergePolicy
+		settings.MergePolicy.SeniorTag = strings.ToLower(strings.TrimSpace(settings.MergePolicy.SeniorTag))
+	}
+	if req.ReviewSLA != nil {
+		settings.ReviewSLA = sla.Normalize(*req.ReviewSLA)
+	}
+	if req.MaxOpenReviews != nil {
+		if *req.MaxOpenReviews < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_SETTINGS",
+					"message": "max_open_reviews must not be negative",
+				},
+			})
+			return
+		}
+		settings.MaxOpenReviews = *req.MaxOpenReviews
+	}
+	if req.FallbackTeams != nil {
+		if !validFallbackTeams(req.TeamName, *req.FallbackTeams) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_SETTINGS",
+					"message": "fallback_teams must be distinct and must not include the team itself",
+				},
+			})
+			return
+		}
+		settings.FallbackTeams = *req.FallbackTeams
+	}
+	if settings.MinReviewers < 0 || !settings.ReviewersCountAllowed(settings.RequiredReviewers) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_SETTINGS",
+				"message": "reviewer counts must satisfy 0 <= min_reviewers <= required_reviewers <= max_reviewers",
+			},
+		})
+		return
+	}
+	if err := mergepolicy.Validate(settings.MergePolicy, settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_SETTINGS",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err := sla.Validate(settings.ReviewSLA); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_SETTINGS",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	updatedTeam, err := s.TeamStorage.UpdateTeamSettings(ctx, req.TeamName, settings)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return
+		}
+		if err.Error() == "FALLBACK_NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "fallback team not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to update team settings", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to update team settings",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TeamResponse{Team: updatedTeam})
+}
+
+func (s *TeamService) DeactivateUsers(c *gin.Context) {
+	var req DeactivateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	team, err := s.TeamStorage.GetTeamByName(ctx, req.TeamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return
+	}
+
+	members := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		members[member.ID] = true
+	}
+	for _, userID := range req.UserIDs {
+		if !members[userID] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "NOT_TEAM_MEMBER",
+					"message": "user " + userID + " is not a member of the team",
+				},
+			})
+			return
+		}
+	}
+
+	seedIDs := slices.Clone(req.UserIDs)
+	slices.Sort(seedIDs)
+	rng := s.NewRandFn(team.Name + "/" + strings.Join(seedIDs, ","))
+
+	// Replacements follow the strategy of the team each pull request belongs
+	// to, which need not be the team being deactivated.
+	pickFor := func(teamID int) (storage.ReviewerPicker, error) {
+		settings, err := s.TeamStorage.GetTeamSettings(ctx, teamID)
+		if err != nil {
+			return nil, err
+		}
+		selector, err := selection.Get(settings.ReviewerStrategy)
+		if err != nil {
+			return nil, err
+		}
+		return func(candidates []models.ReviewerCandidate) []string {
+			return selection.SelectWithFallback(selector, rng, candidates, 1)
+		}, nil
+	}
+
+	reassignments, err := s.TeamStorage.DeactivateMembers(ctx, team.ID, req.UserIDs, pickFor)
+	if err != nil {
+		slog.Error("Failed to deactivate team members", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to deactivate users",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeactivateUsersResponse{
+		TeamName:      team.Name,
+		Deactivated:   req.UserIDs,
+		Reassignments: reassignments,
+	})
+}
+
+func (s *TeamService) AddMembers(c *gin.Context) {
+	var req AddMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	team, ok := s.lookupTeam(ctx, c, req.TeamName)
+	if !ok {
+		return
+	}
+
+	for i := range req.Members {
+		if req.Members[i].Tags != nil {
+			req.Members[i].Tags = models.NormalizeTags(req.Members[i].Tags)
+		}
+	}
+
+	if err := s.TeamStorage.AddMembers(ctx, team.ID, req.Members); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			writeTeamNotFound(c)
+			return
+		}
+		slog.Error("Failed to add team members", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to add team members",
+			},
+		})
+		return
+	}
+
+	s.writeTeam(ctx, c, req.TeamName)
+}
+
+func (s *TeamService) RemoveMembers(c *gin.Context) {
+	var req RemoveMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	team, ok := s.lookupTeam(ctx, c, req.TeamName)
+	if !ok {
+		return
+	}
+
+	if err := s.TeamStorage.RemoveMembers(ctx, team.ID, req.UserIDs); err != nil {
+		if err.Error() == "NOT_TEAM_MEMBER" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "NOT_TEAM_MEMBER",
+					"message": "all user_ids must be members of the team",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to remove team members", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to remove team members",
+			},
+		})
+		return
+	}
+
+	s.writeTeam(ctx, c, req.TeamName)
+}
+
+func (s *TeamService) RenameTeam(c *gin.Context) {
+	var req RenameTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	team, err := s.TeamStorage.RenameTeam(context.Background(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			writeTeamNotFound(c)
+			return
+		}
+		if err.Error() == "TEAM_EXISTS" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "TEAM_EXISTS",
+					"message": "new_team_name already exists",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to rename team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to rename team",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TeamResponse{Team: team})
+}
+
+func (s *TeamService) DeleteTeam(c *gin.Context) {
+	var req DeleteTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err := s.TeamStorage.DeleteTeam(context.Background(), req.TeamName); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			writeTeamNotFound(c)
+			return
+		}
+		if err.Error() == "TEAM_HAS_OPEN_REVIEWS" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    "TEAM_HAS_OPEN_REVIEWS",
+					"message": "team members still review OPEN PRs; reassign or deactivate them first",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to delete team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to delete team",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteTeamResponse{TeamName: req.TeamName})
+}
+
+// lookupTeam writes the error response itself when the team cannot be found.
+func (s *TeamService) lookupTeam(ctx context.Context, c *gin.Context, teamName string) (models.Team, bool) {
+	team, err := s.TeamStorage.GetTeamByName(ctx, teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			writeTeamNotFound(c)
+			return models.Team{}, false
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return models.Team{}, false
+	}
+	return team, true
+}
+
+// writeTeam responds with the current state of a team after a change.
+func (s *TeamService) writeTeam(ctx context.Context, c *gin.Context, teamName string) {
+	team, ok := s.lookupTeam(ctx, c, teamName)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, TeamResponse{Team: team})
+}
+
+func writeTeamNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": gin.H{
+			"code":    "NOT_FOUND",
+			"message": "team not found",
+		},
+	})
+}
+
+func validFallbackTeams(teamName string, fallbackTeams []string) bool {
+	seen := make(map[string]bool, len(fallbackTeams))
+	for _, name := range fallbackTeams {
+		if name == teamName || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return true
+}
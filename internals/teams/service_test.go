@@ -2,12 +2,14 @@ package teams
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sssciel/avito-backend-intership/internals/storage"
 	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
 	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 )
@@ -153,3 +155,609 @@ func TestGetTeam_MissingParameter(t *testing.T) {
 		t.Errorf("Expected status 400, got %d", w.Code)
 	}
 }
+
+func TestUpdateSettings_Success(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend"}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":         "Backend",
+		"reviewer_strategy": "round_robin",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response TeamResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Team.Settings.ReviewerStrategy != "round_robin" {
+		t.Errorf("Expected strategy 'round_robin', got %s", response.Team.Settings.ReviewerStrategy)
+	}
+	if teamStorage.TeamsByID[1].Settings.ReviewerStrategy != "round_robin" {
+		t.Error("Expected strategy to be persisted")
+	}
+}
+
+func TestUpdateSettings_UnknownStrategy(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend"}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":         "Backend",
+		"reviewer_strategy": "by_mood",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_TeamNotFound(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":         "Ghosts",
+		"reviewer_strategy": "random",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_RequiredReviewers(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":          "Backend",
+		"required_reviewers": 3,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	settings := teamStorage.TeamsByID[1].Settings
+	if settings.RequiredReviewers != 3 {
+		t.Errorf("Expected 3 required reviewers, got %d", settings.RequiredReviewers)
+	}
+	if settings.ReviewerStrategy != "least_loaded" {
+		t.Errorf("Expected strategy to be kept, got %s", settings.ReviewerStrategy)
+	}
+}
+
+func TestUpdateSettings_RequiredAboveMax(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":          "Backend",
+		"required_reviewers": 4,
+		"max_reviewers":      3,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_MaxOpenReviews(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	tests := []struct {
+		name           string
+		maxOpenReviews int
+		expectedCode   int
+	}{
+		{"limit", 3, http.StatusOK},
+		{"no limit", 0, http.StatusOK},
+		{"negative", -1, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]interface{}{
+				"team_name":        "Backend",
+				"max_open_reviews": tt.maxOpenReviews,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response TeamResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if response.Team.Settings.MaxOpenReviews != tt.maxOpenReviews {
+				t.Errorf("Expected max_open_reviews %d, got %d", tt.maxOpenReviews, response.Team.Settings.MaxOpenReviews)
+			}
+		})
+	}
+}
+
+func TestUpdateSettings_MinApprovalsAboveMax(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":    "Backend",
+		"merge_policy": map[string]interface{}{"min_approvals": 6},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_FallbackTeams(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+	teamStorage.Teams["Platform"] = models.Team{ID: 2, Name: "Platform"}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":      "Backend",
+		"fallback_teams": []string{"Platform"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	fallbacks := teamStorage.TeamsByID[1].Settings.FallbackTeams
+	if len(fallbacks) != 1 || fallbacks[0] != "Platform" {
+		t.Errorf("Expected fallback teams [Platform], got %v", fallbacks)
+	}
+}
+
+func TestUpdateSettings_FallbackToItself(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":      "Backend",
+		"fallback_teams": []string{"Backend"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_UnknownFallbackTeam(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":      "Backend",
+		"fallback_teams": []string{"Ghosts"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestUpdateSettings_ReviewSLA(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":  "Backend",
+		"review_sla": map[string]interface{}{"response_hours": 24, "working_hours_only": true},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	want := models.ReviewSLA{ResponseHours: 24, WorkingHoursOnly: true, WorkdayStart: 9, WorkdayEnd: 18, Timezone: "UTC"}
+	if got := teamStorage.TeamsByID[1].Settings.ReviewSLA; got != want {
+		t.Errorf("Expected SLA %+v to be persisted, got %+v", want, got)
+	}
+}
+
+func TestUpdateSettings_InvalidReviewSLA(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{ID: 1, Name: "Backend", Settings: models.DefaultTeamSettings()}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"team_name":  "Backend",
+		"review_sla": map[string]interface{}{"response_hours": 8, "working_hours_only": true, "timezone": "Mars/Olympus"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/updateSettings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("INVALID_SETTINGS")) {
+		t.Errorf("Expected INVALID_SETTINGS, got %s", w.Body.String())
+	}
+}
+
+func TestDeactivateUsers_Success(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	team := models.Team{
+		ID:   1,
+		Name: "Backend",
+		Members: []models.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+		},
+		Settings: models.DefaultTeamSettings(),
+	}
+	teamStorage.Teams["Backend"] = team
+	teamStorage.TeamsByID[1] = team
+
+	teamStorage.DeactivateMembersFunc = func(ctx context.Context, teamID int, userIDs []string, pickFor storage.TeamReviewerPicker) ([]models.ReviewerReassignment, error) {
+		pick, err := pickFor(teamID)
+		if err != nil {
+			return nil, err
+		}
+		// The team's own members go before fallback ones.
+		newReviewer := pick([]models.ReviewerCandidate{{UserID: "u4", Tier: 1}, {UserID: "u3"}})
+		return []models.ReviewerReassignment{
+			{PullRequestID: "pr-1", OldReviewerID: "u2", NewReviewerID: newReviewer[0], Status: "REPLACED"},
+			{PullRequestID: "pr-2", OldReviewerID: "u2", Status: "NO_CANDIDATE"},
+		}, nil
+	}
+
+	body, _ := json.Marshal(DeactivateUsersRequest{
+		TeamName: "Backend",
+		UserIDs:  []string{"u2"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/deactivateUsers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response DeactivateUsersResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.Reassignments) != 2 {
+		t.Fatalf("Expected 2 reassignments, got %d", len(response.Reassignments))
+	}
+	if response.Reassignments[0].NewReviewerID != "u3" {
+		t.Errorf("Expected replacement 'u3', got %s", response.Reassignments[0].NewReviewerID)
+	}
+	if response.Reassignments[1].Status != "NO_CANDIDATE" {
+		t.Errorf("Expected status 'NO_CANDIDATE', got %s", response.Reassignments[1].Status)
+	}
+}
+
+func TestDeactivateUsers_NotTeamMember(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{
+		ID:      1,
+		Name:    "Backend",
+		Members: []models.User{{ID: "u1", Username: "Alice", IsActive: true}},
+	}
+
+	body, _ := json.Marshal(DeactivateUsersRequest{
+		TeamName: "Backend",
+		UserIDs:  []string{"u1", "stranger"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/deactivateUsers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if !teamStorage.Teams["Backend"].Members[0].IsActive {
+		t.Error("Expected no user to be deactivated")
+	}
+}
+
+func TestDeactivateUsers_TeamNotFound(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(DeactivateUsersRequest{
+		TeamName: "Ghosts",
+		UserIDs:  []string{"u1"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/deactivateUsers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAddMembers_Success(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Members: []models.User{{ID: "u1", Username: "Alice", IsActive: true}}}
+
+	body, _ := json.Marshal(AddMembersRequest{
+		TeamName: "Backend",
+		Members:  []models.User{{ID: "u2", Username: "Bob", IsActive: true, Tags: []string{" Go "}}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/addMembers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response TeamResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if len(response.Team.Members) != 2 {
+		t.Fatalf("Expected 2 members, got %+v", response.Team.Members)
+	}
+	if tags := response.Team.Members[1].Tags; len(tags) != 1 || tags[0] != "go" {
+		t.Errorf("Expected normalized tags, got %v", tags)
+	}
+}
+
+func TestRemoveMembers_NotTeamMember(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Members: []models.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}}
+
+	body, _ := json.Marshal(RemoveMembersRequest{TeamName: "Backend", UserIDs: []string{"u2", "u9"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/removeMembers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if len(teamStorage.Teams["Backend"].Members) != 2 {
+		t.Error("Expected no member to be removed")
+	}
+}
+
+func TestRemoveMembers_Success(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Members: []models.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}}
+
+	body, _ := json.Marshal(RemoveMembersRequest{TeamName: "Backend", UserIDs: []string{"u2"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/removeMembers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if members := teamStorage.Teams["Backend"].Members; len(members) != 1 || members[0].ID != "u1" {
+		t.Errorf("Expected only u1 to remain, got %+v", members)
+	}
+}
+
+func TestRenameTeam(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend"}
+	teamStorage.Teams["Frontend"] = models.Team{ID: 2, Name: "Frontend"}
+
+	rename := func(newName string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(RenameTeamRequest{TeamName: "Backend", NewTeamName: newName})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/team/rename", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := rename("Frontend"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a taken name, got %d", w.Code)
+	}
+
+	w := rename("Platform")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if _, exists := teamStorage.Teams["Platform"]; !exists {
+		t.Error("Expected the team to be stored under the new name")
+	}
+	if _, exists := teamStorage.Teams["Backend"]; exists {
+		t.Error("Expected the old name to be free")
+	}
+}
+
+func TestDeleteTeam(t *testing.T) {
+	teamStorage := mocks.NewMockTeamStorage()
+	userStorage := mocks.NewMockUserStorage()
+	service := New(teamStorage, userStorage)
+	router := setupRouter(service)
+
+	teamStorage.Teams["Backend"] = models.Team{ID: 1, Name: "Backend", Members: []models.User{{ID: "u1", IsActive: true}}}
+	teamStorage.OpenReviewers["u1"] = true
+
+	deleteTeam := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(DeleteTeamRequest{TeamName: "Backend"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/team/delete", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := deleteTeam(); w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409 while u1 reviews, got %d", w.Code)
+	}
+
+	delete(teamStorage.OpenReviewers, "u1")
+	if w := deleteTeam(); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if _, exists := teamStorage.Teams["Backend"]; exists {
+		t.Error("Expected the team to be deleted")
+	}
+
+	if w := deleteTeam(); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a deleted team, got %d", w.Code)
+	}
+}
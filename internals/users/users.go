@@ -2,8 +2,12 @@ package users
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sssciel/avito-backend-intership/internals/storage"
@@ -28,23 +32,66 @@ func (s *UserService) RegisterRoutes(r *gin.RouterGroup) {
 	userRouter := r.Group("/" + usersPrefix)
 
 	userRouter.POST("/setIsActive", s.SetIsActive)
+	userRouter.POST("/setTags", s.SetTags)
+	userRouter.POST("/setPrimaryTeam", s.SetPrimaryTeam)
+	userRouter.POST("/setMaxOpenReviews", s.SetMaxOpenReviews)
+	userRouter.GET("/get", s.GetUser)
+	userRouter.GET("/list", s.ListUsers)
 	userRouter.GET("/getReview", s.GetUserReviews)
+	userRouter.GET("/activity", s.GetActivity)
+	userRouter.POST("/addAbsence", s.AddAbsence)
+	userRouter.GET("/absences", s.ListAbsences)
+	userRouter.POST("/deleteAbsence", s.DeleteAbsence)
 }
 
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+	defaultListLimit     = 20
+	maxListLimit         = 100
+)
+
 type SetIsActiveRequest struct {
 	UserID   string `json:"user_id" binding:"required"`
 	IsActive bool   `json:"is_active"`
 }
 
-type UserResponse struct {
-	User UserWithTeam `json:"user"`
+type SetTagsRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	Tags   []string `json:"tags" binding:"required"`
 }
 
-type UserWithTeam struct {
+type TagsResponse struct {
+	UserID string   `json:"user_id"`
+	Tags   []string `json:"tags"`
+}
+
+type SetPrimaryTeamRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+type PrimaryTeamResponse struct {
 	UserID   string `json:"user_id"`
-	Username string `json:"username"`
 	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+}
+
+// SetMaxOpenReviewsRequest clears the user's own limit when MaxOpenReviews
+// is null or missing, so the default of their primary team applies.
+type SetMaxOpenReviewsRequest struct {
+	UserID         string `json:"user_id" binding:"required"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type UserResponse struct {
+	User models.UserProfile `json:"user"`
+}
+
+type ListUsersResponse struct {
+	Users []models.UserProfile `json:"users"`
+	// NextAfter continues the listing when passed as after; it is omitted on
+	// the last page.
+	NextAfter string `json:"next_after,omitempty"`
 }
 
 type GetReviewsResponse struct {
@@ -86,7 +133,7 @@ func (s *UserService) SetIsActive(c *gin.Context) {
 		return
 	}
 
-	user, teamName, err := s.getUserWithTeam(req.UserID)
+	user, err := s.UserStorage.GetUser(context.Background(), req.UserID)
 	if err != nil {
 		slog.Error("Failed to get updated user data", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -98,13 +145,277 @@ func (s *UserService) SetIsActive(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, UserResponse{
-		User: UserWithTeam{
-			UserID:   user.ID,
-			Username: user.Username,
-			TeamName: teamName,
-			IsActive: req.IsActive,
-		},
+	c.JSON(http.StatusOK, UserResponse{User: user})
+}
+
+// SetMaxOpenReviews sets how many OPEN pull requests the user may review at
+// once; 0 lifts the limit.
+func (s *UserService) SetMaxOpenReviews(c *gin.Context) {
+	var req SetMaxOpenReviewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	if req.MaxOpenReviews != nil && *req.MaxOpenReviews < 0 {
+		invalidRequest(c, "max_open_reviews must not be negative")
+		return
+	}
+
+	err := s.UserStorage.SetMaxOpenReviews(context.Background(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to set user review capacity", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to update user",
+			},
+		})
+		return
+	}
+
+	user, err := s.UserStorage.GetUser(context.Background(), req.UserID)
+	if err != nil {
+		slog.Error("Failed to get updated user data", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get updated user data",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, UserResponse{User: user})
+}
+
+func (s *UserService) GetUser(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		invalidRequest(c, "user_id query parameter is required")
+		return
+	}
+
+	user, err := s.UserStorage.GetUser(context.Background(), userID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get user", "err", err, "userID", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get user",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, UserResponse{User: user})
+}
+
+// ListUsers pages through users ordered by ID, optionally searching
+// usernames and filtering by team and activity.
+func (s *UserService) ListUsers(c *gin.Context) {
+	ctx := context.Background()
+
+	filter := models.UserFilter{
+		Search: strings.TrimSpace(c.Query("search")),
+		After:  c.Query("after"),
+		Limit:  defaultListLimit,
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxListLimit {
+			invalidRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return
+		}
+		filter.Limit = n
+	}
+	if isActive := c.Query("is_active"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			invalidRequest(c, "is_active must be true or false")
+			return
+		}
+		filter.IsActive = &active
+	}
+
+	if teamName := c.Query("team_name"); teamName != "" {
+		team, err := s.TeamStorage.GetTeamByName(ctx, teamName)
+		if err != nil {
+			if err.Error() == "NOT_FOUND" {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"code":    "NOT_FOUND",
+						"message": "team not found",
+					},
+				})
+				return
+			}
+			slog.Error("Failed to get team", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "failed to list users",
+				},
+			})
+			return
+		}
+		filter.TeamID = team.ID
+	}
+
+	page, err := s.UserStorage.ListUsers(ctx, filter)
+	if err != nil {
+		slog.Error("Failed to list users", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to list users",
+			},
+		})
+		return
+	}
+
+	response := ListUsersResponse{Users: page.Users, NextAfter: page.NextAfter}
+	if response.Users == nil {
+		response.Users = []models.UserProfile{}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *UserService) SetTags(c *gin.Context) {
+	var req SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	tags := models.NormalizeTags(req.Tags)
+
+	err := s.UserStorage.SetTags(context.Background(), req.UserID, tags)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to set user tags", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to update user",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TagsResponse{
+		UserID: req.UserID,
+		Tags:   tags,
+	})
+}
+
+// SetPrimaryTeam picks which of the user's teams reviewers of their pull
+// requests are drawn from when a pull request names no team.
+func (s *UserService) SetPrimaryTeam(c *gin.Context) {
+	var req SetPrimaryTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	team, err := s.TeamStorage.GetTeamByName(ctx, req.TeamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get team", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get team",
+			},
+		})
+		return
+	}
+
+	err = s.UserStorage.SetPrimaryTeam(ctx, req.UserID, team.ID)
+	if err != nil {
+		switch err.Error() {
+		case "NOT_FOUND":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+		case "NOT_TEAM_MEMBER":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "NOT_TEAM_MEMBER",
+					"message": "user is not a member of the team",
+				},
+			})
+		default:
+			slog.Error("Failed to set user primary team", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "failed to update user",
+				},
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PrimaryTeamResponse{
+		UserID:   req.UserID,
+		TeamName: team.Name,
 	})
 }
 
@@ -138,6 +449,226 @@ func (s *UserService) GetUserReviews(c *gin.Context) {
 	})
 }
 
-func (s *UserService) getUserWithTeam(userID string) (models.User, string, error) {
-	return models.User{ID: userID}, "", nil
+type ActivityResponse struct {
+	UserID string                    `json:"user_id"`
+	Events []models.PullRequestEvent `json:"events"`
+	// NextBefore continues the feed when passed as before; it is omitted
+	// when the page is not full.
+	NextBefore int64 `json:"next_before,omitempty"`
+}
+
+// GetActivity returns pull request events the user made, or was assigned
+// or replaced in, newest first.
+func (s *UserService) GetActivity(c *gin.Context) {
+	filter := models.ActivityFilter{
+		UserID: c.Query("user_id"),
+		Limit:  defaultActivityLimit,
+	}
+	if filter.UserID == "" {
+		invalidRequest(c, "user_id query parameter is required")
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxActivityLimit {
+			invalidRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxActivityLimit))
+			return
+		}
+		filter.Limit = n
+	}
+	if before := c.Query("before"); before != "" {
+		n, err := strconv.ParseInt(before, 10, 64)
+		if err != nil || n < 1 {
+			invalidRequest(c, "before must be a positive event_id")
+			return
+		}
+		filter.BeforeID = n
+	}
+
+	events, err := s.UserStorage.GetUserActivity(context.Background(), filter)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to get user activity", "err", err, "userID", filter.UserID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to get user activity",
+			},
+		})
+		return
+	}
+
+	response := ActivityResponse{UserID: filter.UserID, Events: events}
+	if len(events) == filter.Limit {
+		response.NextBefore = events[len(events)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type AddAbsenceRequest struct {
+	UserID   string    `json:"user_id" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+	// ReassignReviews hands the user's OPEN reviews over to others when the
+	// absence starts.
+	ReassignReviews bool `json:"reassign_reviews"`
+}
+
+type DeleteAbsenceRequest struct {
+	UserID    string `json:"user_id" binding:"required"`
+	AbsenceID int64  `json:"absence_id" binding:"required"`
+}
+
+type AbsenceResponse struct {
+	Absence models.Absence `json:"absence"`
+}
+
+type AbsencesResponse struct {
+	UserID   string           `json:"user_id"`
+	Absences []models.Absence `json:"absences"`
+}
+
+type DeleteAbsenceResponse struct {
+	UserID    string `json:"user_id"`
+	AbsenceID int64  `json:"absence_id"`
+}
+
+// AddAbsence registers an out-of-office window. Reviewer selection passes
+// over the user while it lasts, with no need to toggle is_active.
+func (s *UserService) AddAbsence(c *gin.Context) {
+	var req AddAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		invalidRequest(c, "ends_at must be after starts_at")
+		return
+	}
+	if !req.EndsAt.After(time.Now()) {
+		invalidRequest(c, "ends_at must be in the future")
+		return
+	}
+
+	absence, err := s.UserStorage.AddAbsence(context.Background(), models.Absence{
+		UserID:          req.UserID,
+		StartsAt:        req.StartsAt,
+		EndsAt:          req.EndsAt,
+		Reason:          strings.TrimSpace(req.Reason),
+		ReassignReviews: req.ReassignReviews,
+	})
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to add absence", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to add absence",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AbsenceResponse{Absence: absence})
+}
+
+// ListAbsences returns the current and upcoming absences of a user.
+func (s *UserService) ListAbsences(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		invalidRequest(c, "user_id query parameter is required")
+		return
+	}
+
+	absences, err := s.UserStorage.ListAbsences(context.Background(), userID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to list absences", "err", err, "userID", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to list absences",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AbsencesResponse{UserID: userID, Absences: absences})
+}
+
+func (s *UserService) DeleteAbsence(c *gin.Context) {
+	var req DeleteAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid request body", "err", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	err := s.UserStorage.DeleteAbsence(context.Background(), req.UserID, req.AbsenceID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": "absence not found",
+				},
+			})
+			return
+		}
+		slog.Error("Failed to delete absence", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "failed to delete absence",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteAbsenceResponse{UserID: req.UserID, AbsenceID: req.AbsenceID})
+}
+
+func invalidRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"code":    "INVALID_REQUEST",
+			"message": message,
+		},
+	})
 }
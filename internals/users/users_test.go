@@ -2,10 +2,13 @@ package users
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sssciel/avito-backend-intership/internals/storage/mocks"
@@ -51,6 +54,12 @@ func TestSetIsActive_Success(t *testing.T) {
 	if userStorage.Users["u1"].IsActive {
 		t.Error("Expected user to be inactive")
 	}
+
+	var response UserResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.User.Username != "Alice" || response.User.IsActive {
+		t.Errorf("Expected the updated user in the response, got %+v", response.User)
+	}
 }
 
 func TestSetIsActive_UserNotFound(t *testing.T) {
@@ -76,6 +85,103 @@ func TestSetIsActive_UserNotFound(t *testing.T) {
 	}
 }
 
+func TestGetUser(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	service := New(userStorage, mocks.NewMockTeamStorage())
+	router := setupRouter(service)
+
+	userStorage.Users["u1"] = models.User{ID: "u1", Username: "Alice", IsActive: true}
+	userStorage.TeamNames[1] = "backend"
+	userStorage.TeamNames[2] = "platform"
+	userStorage.Memberships["u1"] = []int{1, 2}
+	userStorage.UserTeams["u1"] = 1
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/get?user_id=u1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response UserResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.User.Username != "Alice" || response.User.TeamName != "backend" {
+		t.Errorf("Expected Alice of backend, got %+v", response.User)
+	}
+	if len(response.User.Teams) != 2 || response.User.Teams[1].TeamName != "platform" || response.User.Teams[1].IsPrimary {
+		t.Errorf("Expected both memberships, got %+v", response.User.Teams)
+	}
+
+	for _, path := range []string{"/api/v1/users/get?user_id=ghost", "/api/v1/users/get"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code == http.StatusOK {
+			t.Errorf("Expected %s to fail, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(userStorage, teamStorage)
+	router := setupRouter(service)
+
+	backend, _ := teamStorage.AddTeam(context.Background(), models.Team{Name: "backend"})
+	for _, user := range []models.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Alina", IsActive: false},
+		{ID: "u3", Username: "Bob", IsActive: true},
+	} {
+		userStorage.Users[user.ID] = user
+	}
+	userStorage.Memberships["u1"] = []int{backend.ID}
+	userStorage.Memberships["u2"] = []int{backend.ID}
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedIDs  []string
+		nextAfter    string
+	}{
+		{"all", "", http.StatusOK, []string{"u1", "u2", "u3"}, ""},
+		{"search", "?search=ALI", http.StatusOK, []string{"u1", "u2"}, ""},
+		{"team and activity", "?team_name=backend&is_active=false", http.StatusOK, []string{"u2"}, ""},
+		{"first page", "?limit=2", http.StatusOK, []string{"u1", "u2"}, "u2"},
+		{"next page", "?limit=2&after=u2", http.StatusOK, []string{"u3"}, ""},
+		{"unknown team", "?team_name=ghosts", http.StatusNotFound, nil, ""},
+		{"invalid activity", "?is_active=maybe", http.StatusBadRequest, nil, ""},
+		{"invalid limit", "?limit=0", http.StatusBadRequest, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/list"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response ListUsersResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			var ids []string
+			for _, user := range response.Users {
+				ids = append(ids, user.ID)
+			}
+			if !slices.Equal(ids, tt.expectedIDs) || response.NextAfter != tt.nextAfter {
+				t.Errorf("Expected %v next %q, got %v next %q", tt.expectedIDs, tt.nextAfter, ids, response.NextAfter)
+			}
+		})
+	}
+}
+
 func TestGetUserReviews_Success(t *testing.T) {
 	userStorage := mocks.NewMockUserStorage()
 	teamStorage := mocks.NewMockTeamStorage()
@@ -150,3 +256,270 @@ func TestGetUserReviews_MissingParameter(t *testing.T) {
 		t.Errorf("Expected status 400, got %d", w.Code)
 	}
 }
+
+func TestSetTags_Success(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(userStorage, teamStorage)
+	router := setupRouter(service)
+
+	userStorage.Users["u1"] = models.User{ID: "u1", Username: "Alice", IsActive: true}
+
+	body, _ := json.Marshal(SetTagsRequest{UserID: "u1", Tags: []string{"Go", "go", "frontend"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/setTags", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	tags := userStorage.Users["u1"].Tags
+	if len(tags) != 2 || tags[0] != "go" || tags[1] != "frontend" {
+		t.Errorf("Expected tags [go frontend], got %v", tags)
+	}
+}
+
+func TestSetTags_UserNotFound(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(userStorage, teamStorage)
+	router := setupRouter(service)
+
+	body, _ := json.Marshal(SetTagsRequest{UserID: "ghost", Tags: []string{"go"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/setTags", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSetPrimaryTeam(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	teamStorage := mocks.NewMockTeamStorage()
+	service := New(userStorage, teamStorage)
+	router := setupRouter(service)
+
+	backend, _ := teamStorage.AddTeam(context.Background(), models.Team{Name: "backend"})
+	platform, _ := teamStorage.AddTeam(context.Background(), models.Team{Name: "platform"})
+	teamStorage.AddTeam(context.Background(), models.Team{Name: "mobile"})
+
+	userStorage.Users["u1"] = models.User{ID: "u1", Username: "Alice", IsActive: true}
+	userStorage.UserTeams["u1"] = backend.ID
+	userStorage.Memberships["u1"] = []int{backend.ID, platform.ID}
+
+	tests := []struct {
+		name         string
+		request      SetPrimaryTeamRequest
+		expectedCode int
+		errorCode    string
+	}{
+		{"member team", SetPrimaryTeamRequest{UserID: "u1", TeamName: "platform"}, http.StatusOK, ""},
+		{"not a member", SetPrimaryTeamRequest{UserID: "u1", TeamName: "mobile"}, http.StatusBadRequest, "NOT_TEAM_MEMBER"},
+		{"unknown team", SetPrimaryTeamRequest{UserID: "u1", TeamName: "ghosts"}, http.StatusNotFound, "NOT_FOUND"},
+		{"unknown user", SetPrimaryTeamRequest{UserID: "ghost", TeamName: "platform"}, http.StatusNotFound, "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/setPrimaryTeam", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if tt.errorCode == "" {
+				return
+			}
+			var resp struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Error.Code != tt.errorCode {
+				t.Errorf("Expected error %s, got %s", tt.errorCode, resp.Error.Code)
+			}
+		})
+	}
+
+	if userStorage.UserTeams["u1"] != platform.ID {
+		t.Errorf("Expected primary team %d, got %d", platform.ID, userStorage.UserTeams["u1"])
+	}
+}
+
+func TestSetMaxOpenReviews(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	service := New(userStorage, mocks.NewMockTeamStorage())
+	router := setupRouter(service)
+
+	userStorage.Users["u1"] = models.User{ID: "u1", Username: "Alice", IsActive: true}
+
+	three, negative := 3, -1
+	tests := []struct {
+		name         string
+		request      SetMaxOpenReviewsRequest
+		expectedCode int
+		wantLimit    int
+	}{
+		{"limit", SetMaxOpenReviewsRequest{UserID: "u1", MaxOpenReviews: &three}, http.StatusOK, 3},
+		{"team default", SetMaxOpenReviewsRequest{UserID: "u1"}, http.StatusOK, 0},
+		{"negative", SetMaxOpenReviewsRequest{UserID: "u1", MaxOpenReviews: &negative}, http.StatusBadRequest, 0},
+		{"unknown user", SetMaxOpenReviewsRequest{UserID: "ghost", MaxOpenReviews: &three}, http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users/setMaxOpenReviews", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response UserResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			capacity := response.User.ReviewCapacity
+			if (capacity.MaxOpenReviews == nil) != (tt.request.MaxOpenReviews == nil) || capacity.Limit != tt.wantLimit {
+				t.Errorf("Unexpected review capacity %+v", capacity)
+			}
+		})
+	}
+}
+
+func TestGetActivity_Paginates(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	service := New(userStorage, mocks.NewMockTeamStorage())
+	router := setupRouter(service)
+
+	userStorage.Users["u2"] = models.User{ID: "u2", Username: "Bob", IsActive: true}
+	userStorage.Events = []models.PullRequestEvent{
+		{ID: 1, PullRequestID: "pr-1", Type: models.EventReviewerAssigned, ReviewerID: "u2"},
+		{ID: 2, PullRequestID: "pr-1", Type: models.EventReviewerAssigned, ReviewerID: "u3"},
+		{ID: 3, PullRequestID: "pr-2", Type: models.EventCreated, ActorID: "u2"},
+		{ID: 4, PullRequestID: "pr-1", Type: models.EventReviewerReassigned, ReviewerID: "u4", OldReviewerID: "u2"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/activity?user_id=u2&limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response ActivityResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Events) != 2 || response.Events[0].ID != 4 || response.Events[1].ID != 3 || response.NextBefore != 3 {
+		t.Fatalf("Expected events 4, 3 and next_before 3, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/activity?user_id=u2&limit=2&before=3", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response = ActivityResponse{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Events) != 1 || response.Events[0].ID != 1 || response.NextBefore != 0 {
+		t.Errorf("Expected only event 1 on the last page, got %s", w.Body.String())
+	}
+}
+
+func TestGetActivity_InvalidParams(t *testing.T) {
+	service := New(mocks.NewMockUserStorage(), mocks.NewMockTeamStorage())
+	router := setupRouter(service)
+
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"", http.StatusBadRequest},
+		{"user_id=u1&limit=0", http.StatusBadRequest},
+		{"user_id=u1&before=x", http.StatusBadRequest},
+		{"user_id=ghost", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/activity?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%q: expected status %d, got %d", tt.query, tt.wantCode, w.Code)
+		}
+	}
+}
+
+func TestAbsences(t *testing.T) {
+	userStorage := mocks.NewMockUserStorage()
+	service := New(userStorage, mocks.NewMockTeamStorage())
+	router := setupRouter(service)
+
+	userStorage.Users["u1"] = models.User{ID: "u1", Username: "Alice", IsActive: true}
+
+	send := func(method, path string, payload interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, "/api/v1/users/"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name         string
+		request      gin.H
+		expectedCode int
+	}{
+		{"valid", gin.H{"user_id": "u1", "starts_at": now.Add(time.Hour), "ends_at": now.Add(48 * time.Hour), "reason": "vacation", "reassign_reviews": true}, http.StatusCreated},
+		{"ends before start", gin.H{"user_id": "u1", "starts_at": now.Add(time.Hour), "ends_at": now}, http.StatusBadRequest},
+		{"already over", gin.H{"user_id": "u1", "starts_at": now.Add(-48 * time.Hour), "ends_at": now.Add(-time.Hour)}, http.StatusBadRequest},
+		{"missing start", gin.H{"user_id": "u1", "ends_at": now.Add(time.Hour)}, http.StatusBadRequest},
+		{"unknown user", gin.H{"user_id": "ghost", "starts_at": now, "ends_at": now.Add(time.Hour)}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := send(http.MethodPost, "addAbsence", tt.request); w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := send(http.MethodGet, "absences?user_id=u1", nil)
+	var list AbsencesResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Absences) != 1 {
+		t.Fatalf("Expected one absence, got %d %s", w.Code, w.Body.String())
+	}
+	absence := list.Absences[0]
+	if absence.Reason != "vacation" || !absence.ReassignReviews || !absence.StartsAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Unexpected absence %+v", absence)
+	}
+
+	if w = send(http.MethodPost, "deleteAbsence", DeleteAbsenceRequest{UserID: "u1", AbsenceID: absence.ID}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w = send(http.MethodPost, "deleteAbsence", DeleteAbsenceRequest{UserID: "u1", AbsenceID: absence.ID}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a deleted absence, got %d", w.Code)
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS reviewer_strategy VARCHAR(50) NOT NULL DEFAULT 'least_loaded';
@@ -0,0 +1,7 @@
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_reviewers_bounds;
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS required_reviewers,
+    DROP COLUMN IF EXISTS min_reviewers,
+    DROP COLUMN IF EXISTS max_reviewers;
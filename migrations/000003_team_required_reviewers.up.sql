@@ -0,0 +1,11 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS required_reviewers INTEGER NOT NULL DEFAULT 2,
+    ADD COLUMN IF NOT EXISTS min_reviewers INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS max_reviewers INTEGER NOT NULL DEFAULT 5;
+
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_reviewers_bounds;
+
+ALTER TABLE teams
+    ADD CONSTRAINT teams_reviewers_bounds
+        CHECK (min_reviewers >= 0 AND min_reviewers <= required_reviewers AND required_reviewers <= max_reviewers);
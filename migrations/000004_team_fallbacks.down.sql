@@ -0,0 +1,4 @@
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS is_fallback;
+
+DROP TABLE IF EXISTS team_fallbacks;
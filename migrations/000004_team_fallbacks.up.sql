@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+                                              team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+                                              fallback_team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+                                              position INTEGER NOT NULL,
+                                              PRIMARY KEY (team_id, fallback_team_id),
+                                              CHECK (team_id <> fallback_team_id),
+                                              CHECK (position > 0)
+);
+
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS is_fallback BOOLEAN NOT NULL DEFAULT false;
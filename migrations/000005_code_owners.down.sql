@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS code_owner_rule_owners;
+DROP TABLE IF EXISTS code_owner_rules;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS code_owner_rules (
+                                                id SERIAL PRIMARY KEY,
+                                                pattern VARCHAR(1024) NOT NULL,
+                                                created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS code_owner_rule_owners (
+                                                      rule_id INTEGER NOT NULL REFERENCES code_owner_rules(id) ON DELETE CASCADE,
+                                                      user_id VARCHAR(255) REFERENCES users(user_id) ON DELETE CASCADE,
+                                                      team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+                                                      CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_owner_rule_owners_rule ON code_owner_rule_owners(rule_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_tags (
+                                         user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+                                         tag VARCHAR(64) NOT NULL,
+                                         PRIMARY KEY (user_id, tag)
+);
+
+CREATE TABLE IF NOT EXISTS pull_request_labels (
+                                                   pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+                                                   label VARCHAR(64) NOT NULL,
+                                                   PRIMARY KEY (pull_request_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tags_tag ON user_tags(tag);
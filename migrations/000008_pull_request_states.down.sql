@@ -0,0 +1,4 @@
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS closed_at;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS pull_request_reviews (
+                                                    id SERIAL PRIMARY KEY,
+                                                    pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+                                                    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+                                                    decision VARCHAR(32) NOT NULL CHECK (decision IN ('APPROVE', 'REQUEST_CHANGES', 'COMMENT')),
+                                                    comment TEXT NOT NULL DEFAULT '',
+                                                    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_pr ON pull_request_reviews(pull_request_id);
@@ -0,0 +1,2 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS merge_policy JSONB NOT NULL DEFAULT '{}'::JSONB;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS pull_request_reviewer_changes (
+                                                             id SERIAL PRIMARY KEY,
+                                                             pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+                                                             reviewer_id VARCHAR(255) NOT NULL,
+                                                             action VARCHAR(16) NOT NULL CHECK (action IN ('ADD', 'REMOVE')),
+                                                             performed_by VARCHAR(255) NOT NULL,
+                                                             created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_changes_pr ON pull_request_reviewer_changes(pull_request_id);
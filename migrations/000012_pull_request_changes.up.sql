@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS pull_request_changes (
+                                                    id SERIAL PRIMARY KEY,
+                                                    pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+                                                    field VARCHAR(64) NOT NULL CHECK (field IN ('pull_request_name', 'author_id')),
+                                                    old_value VARCHAR(255) NOT NULL,
+                                                    new_value VARCHAR(255) NOT NULL,
+                                                    changed_by VARCHAR(255) NOT NULL,
+                                                    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_changes_pr ON pull_request_changes(pull_request_id, id);
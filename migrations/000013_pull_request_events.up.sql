@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS pull_request_events (
+                                                   id BIGSERIAL PRIMARY KEY,
+                                                   pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+                                                   event_type VARCHAR(32) NOT NULL CHECK (event_type IN (
+                                                       'CREATED', 'REVIEWER_ASSIGNED', 'REVIEWER_REASSIGNED', 'REVIEWER_REMOVED', 'STATUS_CHANGED', 'MERGED'
+                                                   )),
+                                                   actor_id VARCHAR(255),
+                                                   reviewer_id VARCHAR(255),
+                                                   old_reviewer_id VARCHAR(255),
+                                                   from_status VARCHAR(50),
+                                                   to_status VARCHAR(50),
+                                                   reason VARCHAR(32),
+                                                   created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pull_request_events(pull_request_id, id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_actor ON pull_request_events(actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_reviewer ON pull_request_events(reviewer_id, id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_old_reviewer ON pull_request_events(old_reviewer_id, id);
@@ -0,0 +1,15 @@
+DELETE FROM pull_request_events WHERE event_type = 'SLA_BREACHED';
+
+ALTER TABLE pull_request_events
+    DROP CONSTRAINT IF EXISTS pull_request_events_event_type_check;
+
+ALTER TABLE pull_request_events
+    ADD CONSTRAINT pull_request_events_event_type_check CHECK (event_type IN (
+        'CREATED', 'REVIEWER_ASSIGNED', 'REVIEWER_REASSIGNED', 'REVIEWER_REMOVED', 'STATUS_CHANGED', 'MERGED'
+    ));
+
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS sla_breached_at;
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS review_sla;
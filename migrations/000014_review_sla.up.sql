@@ -0,0 +1,16 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS review_sla JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+-- Set once the SLA worker has handled a missed deadline, so an assignment
+-- left in place for lack of candidates is not reported again.
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS sla_breached_at TIMESTAMP;
+
+ALTER TABLE pull_request_events
+    DROP CONSTRAINT IF EXISTS pull_request_events_event_type_check;
+
+ALTER TABLE pull_request_events
+    ADD CONSTRAINT pull_request_events_event_type_check CHECK (event_type IN (
+        'CREATED', 'REVIEWER_ASSIGNED', 'REVIEWER_REASSIGNED', 'REVIEWER_REMOVED', 'STATUS_CHANGED', 'MERGED',
+        'SLA_BREACHED'
+    ));
@@ -0,0 +1,7 @@
+-- One row per reviewer reminded about stale reviews, so replicas and restarts
+-- do not remind them again before the repeat interval passes.
+CREATE TABLE IF NOT EXISTS review_reminders (
+    reviewer_id VARCHAR(255) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    reminded_at TIMESTAMP NOT NULL,
+    pending_count INTEGER NOT NULL
+);
@@ -0,0 +1,7 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS team_id;
+
+DROP INDEX IF EXISTS idx_team_members_primary;
+
+ALTER TABLE team_members
+    DROP COLUMN IF EXISTS is_primary;
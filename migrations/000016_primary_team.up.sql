@@ -0,0 +1,21 @@
+ALTER TABLE team_members
+    ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT false;
+
+-- Users joined to several teams get their earliest team as the primary one.
+UPDATE team_members tm
+SET is_primary = true
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, team_id
+    FROM team_members
+    ORDER BY user_id, joined_at, team_id
+) first
+WHERE tm.user_id = first.user_id
+  AND tm.team_id = first.team_id
+  AND NOT EXISTS (SELECT 1 FROM team_members p WHERE p.user_id = tm.user_id AND p.is_primary);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_members_primary ON team_members(user_id) WHERE is_primary;
+
+-- The team reviewers are drawn from; NULL for pull requests created before
+-- it was recorded, which fall back to the author's primary team.
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- Out-of-office windows: reviewer selection and replacement pass over a user
+-- while one of their absences is in progress.
+CREATE TABLE IF NOT EXISTS user_absences (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    reassign_reviews BOOLEAN NOT NULL DEFAULT false,
+    reviews_reassigned_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_user ON user_absences(user_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_user_absences_pending_reassign ON user_absences(starts_at)
+    WHERE reassign_reviews AND reviews_reassigned_at IS NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS missing_reviewers;
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS max_open_reviews;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS max_open_reviews;
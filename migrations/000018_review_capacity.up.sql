@@ -0,0 +1,13 @@
+-- Review capacity: a user with max_open_reviews OPEN reviews is not picked
+-- as a reviewer. NULL falls back to the default of their primary team, and 0
+-- means no limit.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS max_open_reviews INTEGER CHECK (max_open_reviews >= 0);
+
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS max_open_reviews INTEGER NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0);
+
+-- Reviewer slots left empty because every remaining candidate was at
+-- capacity when reviewers were assigned.
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS missing_reviewers INTEGER NOT NULL DEFAULT 0;
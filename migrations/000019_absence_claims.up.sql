@@ -0,0 +1,5 @@
+-- A claim on an absence whose reviews are being reassigned. The absence is
+-- marked reassigned only once all its reviews were handled; a claim left
+-- behind by a failed or crashed run expires and the absence is claimed again.
+ALTER TABLE user_absences
+    ADD COLUMN IF NOT EXISTS reviews_claimed_at TIMESTAMP;
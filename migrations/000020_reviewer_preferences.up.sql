@@ -0,0 +1,11 @@
+-- Reviewers the author asked for (PREFERRED, in the requested order) or
+-- against (EXCLUDED) when creating a pull request. Exclusions hold for every
+-- later assignment; preferences are applied again when a draft becomes ready.
+-- user_id is not a foreign key: unknown users may be named too.
+CREATE TABLE IF NOT EXISTS pull_request_reviewer_preferences (
+    pull_request_id INTEGER NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('PREFERRED', 'EXCLUDED')),
+    position INTEGER NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id)
+);
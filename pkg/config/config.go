@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -22,6 +23,14 @@ var envVars = []string{
 
 	"SERVICE_API_TOKEN",
 	"SERVICE_PORT",
+
+	"SLA_CHECK_INTERVAL",
+	"REMINDER_INTERVAL",
+	"REMINDER_STALE_AFTER",
+	"REMINDER_REPEAT_AFTER",
+	"REMINDER_NOTIFIER",
+	"REMINDER_FILE",
+	"ABSENCE_CHECK_INTERVAL",
 }
 
 type DBConfig struct {
@@ -37,14 +46,30 @@ type ServiceConfig struct {
 	Token string `mapstructure:"SERVICE_API_TOKEN"`
 }
 
+// WorkerConfig sets up background workers. A zero interval disables a
+// worker.
+type WorkerConfig struct {
+	SLACheckInterval    time.Duration `mapstructure:"SLA_CHECK_INTERVAL"`
+	ReminderInterval    time.Duration `mapstructure:"REMINDER_INTERVAL"`
+	ReminderStaleAfter  time.Duration `mapstructure:"REMINDER_STALE_AFTER"`
+	ReminderRepeatAfter time.Duration `mapstructure:"REMINDER_REPEAT_AFTER"`
+	ReminderNotifier    string        `mapstructure:"REMINDER_NOTIFIER"`
+	ReminderFile        string        `mapstructure:"REMINDER_FILE"`
+	// AbsenceCheckInterval is how often reviews of users whose absence has
+	// started are reassigned.
+	AbsenceCheckInterval time.Duration `mapstructure:"ABSENCE_CHECK_INTERVAL"`
+}
+
 var (
-	DB  DBConfig
-	API ServiceConfig
+	DB      DBConfig
+	API     ServiceConfig
+	Workers WorkerConfig
 )
 
 var ConfigStructs = []interface{}{
 	&DB,
 	&API,
+	&Workers,
 }
 
 func loadEnv() {
@@ -55,6 +80,13 @@ func loadEnv() {
 		slog.Warn(".env file not found, using environment variables", "err", err)
 	}
 	viper.AutomaticEnv()
+	viper.SetDefault("SLA_CHECK_INTERVAL", "1m")
+	viper.SetDefault("REMINDER_INTERVAL", "1h")
+	viper.SetDefault("REMINDER_STALE_AFTER", "24h")
+	viper.SetDefault("REMINDER_REPEAT_AFTER", "24h")
+	viper.SetDefault("REMINDER_NOTIFIER", "log")
+	viper.SetDefault("REMINDER_FILE", "reminders.log")
+	viper.SetDefault("ABSENCE_CHECK_INTERVAL", "1m")
 
 	for _, v := range envVars {
 		viper.BindEnv(v)
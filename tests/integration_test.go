@@ -2,17 +2,27 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/sssciel/avito-backend-intership/internals/absences"
+	"github.com/sssciel/avito-backend-intership/internals/codeowners"
 	"github.com/sssciel/avito-backend-intership/internals/pullrequests"
+	"github.com/sssciel/avito-backend-intership/internals/reminders"
+	"github.com/sssciel/avito-backend-intership/internals/sla"
+	"github.com/sssciel/avito-backend-intership/internals/stats"
+	"github.com/sssciel/avito-backend-intership/internals/storage/models"
 	"github.com/sssciel/avito-backend-intership/internals/storage/pgsql"
 	"github.com/sssciel/avito-backend-intership/internals/teams"
 	"github.com/sssciel/avito-backend-intership/internals/users"
@@ -45,6 +55,7 @@ func cleanupDB(db *sqlx.DB) {
 	db.Exec("TRUNCATE TABLE team_members CASCADE")
 	db.Exec("TRUNCATE TABLE users CASCADE")
 	db.Exec("TRUNCATE TABLE teams CASCADE")
+	db.Exec("TRUNCATE TABLE code_owner_rules CASCADE")
 }
 
 func setupRouter(db *sqlx.DB) *gin.Engine {
@@ -53,10 +64,14 @@ func setupRouter(db *sqlx.DB) *gin.Engine {
 	teamStorage := &pgsql.PGTeamStorage{DB: db}
 	userStorage := &pgsql.PGUserStorage{DB: db}
 	requestStorage := &pgsql.PGPullRequestStorage{DB: db}
+	codeOwnerStorage := &pgsql.PGCodeOwnerStorage{DB: db}
+	statsStorage := &pgsql.PGStatsStorage{DB: db}
 
 	teamService := teams.New(teamStorage, userStorage)
 	userService := users.New(userStorage, teamStorage)
-	prService := pullrequests.New(requestStorage, teamStorage, userStorage)
+	prService := pullrequests.New(requestStorage, teamStorage, userStorage, codeOwnerStorage)
+	codeOwnerService := codeowners.New(codeOwnerStorage)
+	statsService := stats.New(statsStorage, teamStorage)
 
 	r := gin.Default()
 	api := r.Group("/api/v1")
@@ -64,6 +79,8 @@ func setupRouter(db *sqlx.DB) *gin.Engine {
 	teamService.RegisterRoutes(api)
 	userService.RegisterRoutes(api)
 	prService.RegisterRoutes(api)
+	codeOwnerService.RegisterRoutes(api)
+	statsService.RegisterRoutes(api)
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -298,6 +315,83 @@ func TestIntegration_SetUserActive(t *testing.T) {
 	if user["is_active"] != false {
 		t.Errorf("Expected is_active to be false, got %v", user["is_active"])
 	}
+	if user["username"] != "Ian" || user["team_name"] != "QA" {
+		t.Errorf("Expected username Ian in team QA, got %v", user)
+	}
+}
+
+func TestIntegration_GetAndListUsers(t *testing.T) {
+	cleanupDB(testDB)
+
+	for _, team := range []map[string]interface{}{
+		{"team_name": "Core", "members": []map[string]interface{}{
+			{"user_id": "gl1", "username": "Anna", "is_active": true, "tags": []string{"go"}},
+			{"user_id": "gl2", "username": "Boris", "is_active": false},
+		}},
+		{"team_name": "Mobile", "members": []map[string]interface{}{
+			{"user_id": "gl1", "username": "Anna", "is_active": true},
+			{"user_id": "gl3", "username": "Hanna", "is_active": true},
+		}},
+	} {
+		body, _ := json.Marshal(team)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	get := func(path string) (int, []byte) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1"+path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := get("/users/get?user_id=gl1")
+	var userResp struct {
+		User models.UserProfile `json:"user"`
+	}
+	json.Unmarshal(body, &userResp)
+	if code != http.StatusOK || userResp.User.TeamName != "Core" || len(userResp.User.Teams) != 2 {
+		t.Fatalf("Expected gl1 in Core and Mobile, got %d %s", code, body)
+	}
+	if !userResp.User.Teams[0].IsPrimary || userResp.User.Teams[1].TeamName != "Mobile" {
+		t.Errorf("Expected the primary team first, got %+v", userResp.User.Teams)
+	}
+
+	if code, _ = get("/users/get?user_id=ghost"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown user, got %d", code)
+	}
+
+	var listResp struct {
+		Users     []models.UserProfile `json:"users"`
+		NextAfter string               `json:"next_after"`
+	}
+	ids := func(path string) []string {
+		listResp.Users, listResp.NextAfter = nil, ""
+		code, body := get(path)
+		if code != http.StatusOK {
+			t.Fatalf("Failed to list users %s: %d %s", path, code, body)
+		}
+		json.Unmarshal(body, &listResp)
+		var result []string
+		for _, u := range listResp.Users {
+			result = append(result, u.ID)
+		}
+		return result
+	}
+
+	if got := ids("/users/list?search=anna"); !slices.Equal(got, []string{"gl1", "gl3"}) {
+		t.Errorf("Expected search to match Anna and Hanna, got %v", got)
+	}
+	if got := ids("/users/list?team_name=Core&is_active=true"); !slices.Equal(got, []string{"gl1"}) {
+		t.Errorf("Expected only active Core members, got %v", got)
+	}
+	if got := ids("/users/list?limit=2"); !slices.Equal(got, []string{"gl1", "gl2"}) || listResp.NextAfter != "gl2" {
+		t.Errorf("Expected the first page, got %v next %q", got, listResp.NextAfter)
+	}
+	if got := ids("/users/list?limit=2&after=gl2"); !slices.Equal(got, []string{"gl3"}) || listResp.NextAfter != "" {
+		t.Errorf("Expected the last page, got %v next %q", got, listResp.NextAfter)
+	}
 }
 
 func TestIntegration_GetUserReviews(t *testing.T) {
@@ -537,3 +631,1457 @@ func TestIntegration_InactiveUserNotAssigned(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegration_LeastLoadedReviewersPreferred(t *testing.T) {
+	cleanupDB(testDB)
+
+	teamData := map[string]interface{}{
+		"team_name": "Platform",
+		"members": []map[string]interface{}{
+			{"user_id": "pl1", "username": "Wendy", "is_active": true},
+			{"user_id": "pl2", "username": "Xavier", "is_active": true},
+			{"user_id": "pl3", "username": "Yara", "is_active": true},
+			{"user_id": "pl4", "username": "Zack", "is_active": true},
+		},
+	}
+
+	body, _ := json.Marshal(teamData)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	createPR := func(prID string) []interface{} {
+		prData := map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Load test",
+			"author_id":         "pl1",
+		}
+
+		body, _ := json.Marshal(prData)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})
+	}
+
+	first := createPR("pr-load-1")
+	second := createPR("pr-load-2")
+
+	assigned := make(map[interface{}]bool)
+	for _, rev := range first {
+		assigned[rev] = true
+	}
+
+	var idle interface{}
+	for _, id := range []string{"pl2", "pl3", "pl4"} {
+		if !assigned[id] {
+			idle = id
+		}
+	}
+
+	found := false
+	for _, rev := range second {
+		if rev == idle {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected least loaded reviewer %v to be assigned, got %v", idle, second)
+	}
+}
+
+func TestIntegration_DeactivateUsersReassignsOpenReviews(t *testing.T) {
+	cleanupDB(testDB)
+
+	teamData := map[string]interface{}{
+		"team_name": "Mobile",
+		"members": []map[string]interface{}{
+			{"user_id": "mb1", "username": "Ann", "is_active": true},
+			{"user_id": "mb2", "username": "Ben", "is_active": true},
+			{"user_id": "mb3", "username": "Cid", "is_active": true},
+			{"user_id": "mb4", "username": "Dan", "is_active": true},
+		},
+	}
+
+	body, _ := json.Marshal(teamData)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	prData := map[string]interface{}{
+		"pull_request_id":   "pr-deactivate",
+		"pull_request_name": "Offline mode",
+		"author_id":         "mb1",
+	}
+
+	body, _ = json.Marshal(prData)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	reviewers := created["pr"].(map[string]interface{})["assigned_reviewers"].([]interface{})
+	if len(reviewers) != 2 {
+		t.Fatalf("Expected 2 reviewers, got %v", reviewers)
+	}
+
+	deactivateData := map[string]interface{}{
+		"team_name": "Mobile",
+		"user_ids":  []interface{}{reviewers[0], reviewers[1]},
+	}
+
+	body, _ = json.Marshal(deactivateData)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/team/deactivateUsers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	statuses := make(map[interface{}]int)
+	for _, r := range response["reassignments"].([]interface{}) {
+		statuses[r.(map[string]interface{})["status"]]++
+	}
+	if statuses["REPLACED"] != 1 || statuses["NO_CANDIDATE"] != 1 {
+		t.Errorf("Expected one REPLACED and one NO_CANDIDATE, got %v", statuses)
+	}
+}
+
+func TestIntegration_DeactivateUsersUsesPullRequestTeam(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Web",
+		"members": []map[string]interface{}{
+			{"user_id": "dw1", "username": "Ann", "is_active": true},
+			{"user_id": "dw2", "username": "Ben", "is_active": true},
+			{"user_id": "dx", "username": "Cid", "is_active": true},
+		},
+	})
+	post("/team/add", map[string]interface{}{
+		"team_name": "Data",
+		"members": []map[string]interface{}{
+			{"user_id": "dx", "username": "Cid", "is_active": true},
+			{"user_id": "dd1", "username": "Dan", "is_active": true},
+		},
+	})
+	post("/team/add", map[string]interface{}{
+		"team_name": "Ops",
+		"members":   []map[string]interface{}{{"user_id": "do1", "username": "Eve", "is_active": true}},
+	})
+	w := post("/team/updateSettings", map[string]interface{}{"team_name": "Web", "fallback_teams": []string{"Ops"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to set fallback teams: %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":   "pr-web",
+		"pull_request_name": "Landing",
+		"author_id":         "dw1",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create PR: %d %s", w.Code, w.Body.String())
+	}
+
+	// dx is deactivated through Data, but reviews a Web pull request: the
+	// replacement comes from Web and then its fallback team, never from Data.
+	w = post("/team/deactivateUsers", map[string]interface{}{"team_name": "Data", "user_ids": []string{"dx"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Reassignments []models.ReviewerReassignment `json:"reassignments"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Reassignments) != 1 || response.Reassignments[0].NewReviewerID != "do1" {
+		t.Fatalf("Expected do1 from the fallback team to take over, got %+v", response.Reassignments)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=pr-web", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var got struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if !slices.Contains(got.PR.FallbackReviewers, "do1") {
+		t.Errorf("Expected do1 to be recorded as a fallback reviewer, got %+v", got.PR)
+	}
+}
+
+func TestIntegration_Stats(t *testing.T) {
+	cleanupDB(testDB)
+
+	teamData := map[string]interface{}{
+		"team_name": "Analytics",
+		"members": []map[string]interface{}{
+			{"user_id": "an1", "username": "Ann", "is_active": true},
+			{"user_id": "an2", "username": "Ben", "is_active": true},
+			{"user_id": "an3", "username": "Cid", "is_active": true},
+		},
+	}
+
+	body, _ := json.Marshal(teamData)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	for _, id := range []string{"pr-stats-1", "pr-stats-2"} {
+		prData := map[string]interface{}{
+			"pull_request_id":   id,
+			"pull_request_name": "Report " + id,
+			"author_id":         "an1",
+		}
+		body, _ = json.Marshal(prData)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"pull_request_id": "pr-stats-1"})
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/merge", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/stats/reviewers?team_name=Analytics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var reviewers struct {
+		Reviewers []struct {
+			UserID           string `json:"user_id"`
+			TotalAssignments int    `json:"total_assignments"`
+			OpenAssignments  int    `json:"open_assignments"`
+			MergedReviews    int    `json:"merged_reviews"`
+		} `json:"reviewers"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reviewers)
+
+	total, open, merged := 0, 0, 0
+	for _, r := range reviewers.Reviewers {
+		total += r.TotalAssignments
+		open += r.OpenAssignments
+		merged += r.MergedReviews
+	}
+	if total != 4 || open != 2 || merged != 2 {
+		t.Errorf("Expected 4 assignments (2 open, 2 merged), got %d (%d open, %d merged)", total, open, merged)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/stats/pullRequests?team_name=Analytics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var pullRequests struct {
+		PullRequests []struct {
+			ID                 string `json:"pull_request_id"`
+			ReviewersCount     int    `json:"reviewers_count"`
+			TimeToMergeSeconds *int64 `json:"time_to_merge_seconds"`
+		} `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &pullRequests)
+
+	if len(pullRequests.PullRequests) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(pullRequests.PullRequests))
+	}
+	for _, pr := range pullRequests.PullRequests {
+		if pr.ReviewersCount != 2 {
+			t.Errorf("Expected 2 reviewers on %s, got %d", pr.ID, pr.ReviewersCount)
+		}
+		if (pr.ID == "pr-stats-1") != (pr.TimeToMergeSeconds != nil) {
+			t.Errorf("Unexpected time to merge on %s: %v", pr.ID, pr.TimeToMergeSeconds)
+		}
+	}
+}
+
+func TestIntegration_GetAndListPullRequests(t *testing.T) {
+	cleanupDB(testDB)
+
+	teamData := map[string]interface{}{
+		"team_name": "Search",
+		"members": []map[string]interface{}{
+			{"user_id": "se1", "username": "Ann", "is_active": true},
+			{"user_id": "se2", "username": "Ben", "is_active": true},
+		},
+	}
+
+	body, _ := json.Marshal(teamData)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	for _, id := range []string{"pr-list-1", "pr-list-2", "pr-list-3"} {
+		prData := map[string]interface{}{
+			"pull_request_id":   id,
+			"pull_request_name": "Search " + id,
+			"author_id":         "se1",
+		}
+		body, _ = json.Marshal(prData)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/get?pull_request_id=pr-list-2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var seen []string
+	url := "/api/v1/pullRequest/list?team_name=Search&reviewer_id=se2&sort_by=created_at&order=asc&limit=2"
+	for page := 0; page < 3; page++ {
+		req = httptest.NewRequest(http.MethodGet, url, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+		}
+
+		var response struct {
+			PullRequests []struct {
+				ID string `json:"pull_request_id"`
+			} `json:"pull_requests"`
+			NextCursor string `json:"next_cursor"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		for _, pr := range response.PullRequests {
+			seen = append(seen, pr.ID)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		url = "/api/v1/pullRequest/list?team_name=Search&reviewer_id=se2&sort_by=created_at&order=asc&limit=2&cursor=" + response.NextCursor
+	}
+
+	if len(seen) != 3 || seen[0] != "pr-list-1" || seen[2] != "pr-list-3" {
+		t.Errorf("Expected all three PRs in creation order, got %v", seen)
+	}
+}
+
+func TestIntegration_PullRequestLifecycle(t *testing.T) {
+	cleanupDB(testDB)
+
+	teamData := map[string]interface{}{
+		"team_name": "Mobile",
+		"members": []map[string]interface{}{
+			{"user_id": "mo1", "username": "Ann", "is_active": true},
+			{"user_id": "mo2", "username": "Ben", "is_active": true},
+		},
+	}
+
+	body, _ := json.Marshal(teamData)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/team/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	prData := map[string]interface{}{
+		"pull_request_id":   "pr-draft",
+		"pull_request_name": "WIP",
+		"author_id":         "mo1",
+		"draft":             true,
+	}
+	body, _ = json.Marshal(prData)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/create", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	type prResponse struct {
+		PR struct {
+			Status            string   `json:"status"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+
+	steps := []struct {
+		endpoint  string
+		code      int
+		status    string
+		reviewers int
+	}{
+		{"merge", http.StatusConflict, "", 0},
+		{"close", http.StatusOK, "CLOSED", 0},
+		{"ready", http.StatusConflict, "", 0},
+		{"reopen", http.StatusOK, "OPEN", 1},
+		{"close", http.StatusOK, "CLOSED", 1},
+		{"reopen", http.StatusOK, "OPEN", 1},
+		{"merge", http.StatusOK, "MERGED", 1},
+		{"reopen", http.StatusConflict, "", 0},
+	}
+
+	for _, step := range steps {
+		body, _ = json.Marshal(map[string]interface{}{"pull_request_id": "pr-draft"})
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/"+step.endpoint, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != step.code {
+			t.Fatalf("%s: expected status %d, got %d. Body: %s", step.endpoint, step.code, w.Code, w.Body.String())
+		}
+		if step.code != http.StatusOK {
+			continue
+		}
+
+		var response prResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response.PR.Status != step.status {
+			t.Errorf("%s: expected status %s, got %s", step.endpoint, step.status, response.PR.Status)
+		}
+		if len(response.PR.AssignedReviewers) != step.reviewers {
+			t.Errorf("%s: expected %d reviewers, got %v", step.endpoint, step.reviewers, response.PR.AssignedReviewers)
+		}
+	}
+}
+
+func TestIntegration_ApprovalGatedMerge(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Billing",
+		"members": []map[string]interface{}{
+			{"user_id": "bi1", "username": "Ann", "is_active": true},
+			{"user_id": "bi2", "username": "Ben", "is_active": true},
+		},
+	})
+	post("/team/updateSettings", map[string]interface{}{
+		"team_name":          "Billing",
+		"required_reviewers": 1,
+		"merge_policy":       map[string]interface{}{"min_approvals": 1},
+	})
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":   "pr-approve",
+		"pull_request_name": "Invoices",
+		"author_id":         "bi1",
+	})
+
+	steps := []struct {
+		path string
+		data map[string]interface{}
+		code int
+	}{
+		{"/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-approve"}, http.StatusConflict},
+		{"/pullRequest/review", map[string]interface{}{"pull_request_id": "pr-approve", "reviewer_id": "bi1", "decision": "APPROVE"}, http.StatusConflict},
+		{"/pullRequest/review", map[string]interface{}{"pull_request_id": "pr-approve", "reviewer_id": "bi2", "decision": "REQUEST_CHANGES"}, http.StatusCreated},
+		{"/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-approve"}, http.StatusConflict},
+		{"/pullRequest/review", map[string]interface{}{"pull_request_id": "pr-approve", "reviewer_id": "bi2", "decision": "APPROVE"}, http.StatusCreated},
+		{"/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-approve"}, http.StatusOK},
+		{"/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-approve"}, http.StatusOK},
+	}
+
+	for i, step := range steps {
+		w := post(step.path, step.data)
+		if w.Code != step.code {
+			t.Fatalf("step %d %s: expected status %d, got %d. Body: %s", i, step.path, step.code, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/reviews?pull_request_id=pr-approve", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response struct {
+		Reviews []struct {
+			Decision string `json:"decision"`
+		} `json:"reviews"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Reviews) != 2 || response.Reviews[0].Decision != "REQUEST_CHANGES" || response.Reviews[1].Decision != "APPROVE" {
+		t.Errorf("Expected review history [REQUEST_CHANGES APPROVE], got %s", w.Body.String())
+	}
+}
+
+func TestIntegration_MergePolicy(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Risk",
+		"members": []map[string]interface{}{
+			{"user_id": "ri1", "username": "Ann", "is_active": true},
+			{"user_id": "ri2", "username": "Ben", "is_active": true, "tags": []string{"senior"}},
+		},
+	})
+	w := post("/team/updateSettings", map[string]interface{}{
+		"team_name":          "Risk",
+		"required_reviewers": 1,
+		"merge_policy": map[string]interface{}{
+			"senior_tag":               "senior",
+			"block_inactive_reviewers": true,
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":   "pr-policy",
+		"pull_request_name": "Limits",
+		"author_id":         "ri1",
+	})
+	post("/pullRequest/review", map[string]interface{}{
+		"pull_request_id": "pr-policy",
+		"reviewer_id":     "ri2",
+		"decision":        "APPROVE",
+	})
+	post("/users/setIsActive", map[string]interface{}{"user_id": "ri2", "is_active": false})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/mergeability?pull_request_id=pr-policy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response struct {
+		Mergeable bool `json:"mergeable"`
+		Rules     []struct {
+			Rule   string `json:"rule"`
+			Passed bool   `json:"passed"`
+		} `json:"rules"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	passed := make(map[string]bool)
+	for _, rule := range response.Rules {
+		passed[rule.Rule] = rule.Passed
+	}
+	if response.Mergeable || !passed["senior_approval"] || passed["active_reviewers"] {
+		t.Errorf("Expected only active_reviewers to fail, got %s", w.Body.String())
+	}
+
+	w = post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-policy"})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	post("/users/setIsActive", map[string]interface{}{"user_id": "ri2", "is_active": true})
+
+	w = post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-policy"})
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIntegration_AddAndRemoveReviewer(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Search",
+		"members": []map[string]interface{}{
+			{"user_id": "se1", "username": "Ann", "is_active": true},
+			{"user_id": "se2", "username": "Ben", "is_active": true},
+			{"user_id": "se3", "username": "Cid", "is_active": false},
+		},
+	})
+	post("/team/add", map[string]interface{}{
+		"team_name": "Ranking",
+		"members": []map[string]interface{}{
+			{"user_id": "ra1", "username": "Dan", "is_active": true},
+		},
+	})
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":   "pr-manual",
+		"pull_request_name": "Synonyms",
+		"author_id":         "se1",
+	})
+
+	steps := []struct {
+		path      string
+		reviewer  string
+		code      int
+		reviewers []string
+	}{
+		{"/pullRequest/addReviewer", "se1", http.StatusConflict, nil},
+		{"/pullRequest/addReviewer", "se2", http.StatusConflict, nil},
+		{"/pullRequest/addReviewer", "se3", http.StatusConflict, nil},
+		{"/pullRequest/addReviewer", "ra1", http.StatusConflict, nil},
+		{"/pullRequest/addReviewer", "ghost", http.StatusNotFound, nil},
+		{"/pullRequest/removeReviewer", "se2", http.StatusOK, []string{}},
+		{"/pullRequest/removeReviewer", "se2", http.StatusConflict, nil},
+		{"/pullRequest/addReviewer", "se2", http.StatusOK, []string{"se2"}},
+	}
+
+	for _, step := range steps {
+		w := post(step.path, map[string]interface{}{
+			"pull_request_id": "pr-manual",
+			"reviewer_id":     step.reviewer,
+			"performed_by":    "lead",
+		})
+		if w.Code != step.code {
+			t.Fatalf("%s %s: expected status %d, got %d. Body: %s", step.path, step.reviewer, step.code, w.Code, w.Body.String())
+		}
+		if step.reviewers == nil {
+			continue
+		}
+
+		var response struct {
+			PR struct {
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+			Change struct {
+				PerformedBy string `json:"performed_by"`
+			} `json:"change"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if len(response.PR.AssignedReviewers) != len(step.reviewers) {
+			t.Errorf("%s %s: expected reviewers %v, got %v", step.path, step.reviewer, step.reviewers, response.PR.AssignedReviewers)
+		}
+		if response.Change.PerformedBy != "lead" {
+			t.Errorf("%s %s: expected change by lead, got %s", step.path, step.reviewer, w.Body.String())
+		}
+	}
+
+	post("/team/updateSettings", map[string]interface{}{
+		"team_name":      "Search",
+		"fallback_teams": []string{"Ranking"},
+	})
+	w := post("/pullRequest/addReviewer", map[string]interface{}{
+		"pull_request_id": "pr-manual",
+		"reviewer_id":     "ra1",
+		"performed_by":    "lead",
+	})
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected fallback team member to be added, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var changes int
+	testDB.Get(&changes, "SELECT COUNT(*) FROM pull_request_reviewer_changes")
+	if changes != 3 {
+		t.Errorf("Expected 3 recorded changes, got %d", changes)
+	}
+}
+
+func TestIntegration_ReviewerPreferences(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Maps",
+		"members": []map[string]interface{}{
+			{"user_id": "ma1", "username": "Ann", "is_active": true},
+			{"user_id": "ma2", "username": "Ben", "is_active": true},
+			{"user_id": "ma3", "username": "Cid", "is_active": true},
+			{"user_id": "ma4", "username": "Dan", "is_active": true},
+			{"user_id": "ma5", "username": "Eve", "is_active": false},
+		},
+	})
+
+	w := post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-prefs",
+		"pull_request_name":   "Tiles",
+		"author_id":           "ma1",
+		"preferred_reviewers": []string{"ma4", "ma5"},
+		"excluded_reviewers":  []string{"ma2"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		UnhonoredPreferences []struct {
+			UserID string `json:"user_id"`
+			Reason string `json:"reason"`
+		} `json:"unhonored_preferences"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if strings.Join(response.PR.AssignedReviewers, ",") != "ma4,ma3" {
+		t.Errorf("Expected reviewers [ma4 ma3], got %v", response.PR.AssignedReviewers)
+	}
+	if len(response.UnhonoredPreferences) != 1 || response.UnhonoredPreferences[0].UserID != "ma5" || response.UnhonoredPreferences[0].Reason != "INACTIVE" {
+		t.Errorf("Expected ma5 to be unhonored as INACTIVE, got %s", w.Body.String())
+	}
+
+	// The excluded ma2 is the only other member left, so nobody replaces ma3.
+	w = post("/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "pr-prefs",
+		"old_reviewer_id": "ma3",
+	})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "NO_CANDIDATE") {
+		t.Errorf("Expected NO_CANDIDATE replacing ma3, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-prefs-draft",
+		"pull_request_name":   "Routing",
+		"author_id":           "ma1",
+		"draft":               true,
+		"preferred_reviewers": []string{"ma3"},
+		"excluded_reviewers":  []string{"ma4"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for a draft with preferences, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	w = post("/pullRequest/ready", map[string]interface{}{"pull_request_id": "pr-prefs-draft", "reviewers_count": 1})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if strings.Join(response.PR.AssignedReviewers, ",") != "ma3" {
+		t.Errorf("Expected the preferred ma3 once ready, got %v", response.PR.AssignedReviewers)
+	}
+}
+
+func TestIntegration_UpdatePullRequest(t *testing.T) {
+	cleanupDB(testDB)
+
+	send := func(method, path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(method, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	send(http.MethodPost, "/team/add", map[string]interface{}{
+		"team_name": "Payments",
+		"members": []map[string]interface{}{
+			{"user_id": "pa1", "username": "Ann", "is_active": true},
+			{"user_id": "pa2", "username": "Ben", "is_active": true},
+			{"user_id": "pa3", "username": "Cid", "is_active": true},
+		},
+	})
+	send(http.MethodPost, "/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-update",
+		"pull_request_name":   "Refunds",
+		"author_id":           "pa1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"pa2"},
+	})
+
+	w := send(http.MethodPatch, "/pullRequest/update", map[string]interface{}{
+		"pull_request_id":   "pr-update",
+		"pull_request_name": "Partial refunds",
+		"author_id":         "pa2",
+		"performed_by":      "pa1",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		PR struct {
+			Name              string   `json:"pull_request_name"`
+			AuthorID          string   `json:"author_id"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		Changes         []map[string]interface{} `json:"changes"`
+		RemovedReviewer string                   `json:"removed_reviewer"`
+		ReplacedBy      string                   `json:"replaced_by"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.PR.Name != "Partial refunds" || response.PR.AuthorID != "pa2" || len(response.Changes) != 2 {
+		t.Errorf("Expected renamed PR owned by pa2 with 2 changes, got %s", w.Body.String())
+	}
+	if response.RemovedReviewer != "pa2" || len(response.PR.AssignedReviewers) != 1 || response.PR.AssignedReviewers[0] == "pa2" {
+		t.Errorf("Expected pa2 to be replaced as reviewer, got %s", w.Body.String())
+	}
+
+	w = send(http.MethodPatch, "/pullRequest/update", map[string]interface{}{
+		"pull_request_id": "pr-update",
+		"author_id":       "ghost",
+		"performed_by":    "pa1",
+	})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown author, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/history?pull_request_id=pr-update", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var history struct {
+		Changes []struct {
+			Field    string `json:"field"`
+			OldValue string `json:"old_value"`
+			NewValue string `json:"new_value"`
+		} `json:"changes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history.Changes) != 2 || history.Changes[1].Field != "author_id" || history.Changes[1].OldValue != "pa1" {
+		t.Errorf("Expected name and author changes, got %s", w.Body.String())
+	}
+}
+
+func TestIntegration_Timeline(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Feed",
+		"members": []map[string]interface{}{
+			{"user_id": "fe1", "username": "Ann", "is_active": true},
+			{"user_id": "fe2", "username": "Ben", "is_active": true},
+			{"user_id": "fe3", "username": "Cid", "is_active": true},
+		},
+	})
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-timeline",
+		"pull_request_name":   "Ranking",
+		"author_id":           "fe1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"fe2"},
+	})
+	post("/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "pr-timeline",
+		"old_reviewer_id": "fe2",
+	})
+	post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-timeline"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/timeline?pull_request_id=pr-timeline", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var timeline struct {
+		Events []struct {
+			Type          string `json:"type"`
+			ReviewerID    string `json:"reviewer_id"`
+			OldReviewerID string `json:"old_reviewer_id"`
+			Reason        string `json:"reason"`
+		} `json:"events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+
+	var types []string
+	for _, event := range timeline.Events {
+		types = append(types, event.Type)
+	}
+	if strings.Join(types, ",") != "CREATED,REVIEWER_ASSIGNED,REVIEWER_REASSIGNED,MERGED" {
+		t.Fatalf("Unexpected timeline %s", w.Body.String())
+	}
+	if reassigned := timeline.Events[2]; reassigned.OldReviewerID != "fe2" || reassigned.ReviewerID != "fe3" || reassigned.Reason != "REASSIGN" {
+		t.Errorf("Unexpected reassignment event %+v", reassigned)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/activity?user_id=fe2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var activity struct {
+		Events []struct {
+			Type string `json:"type"`
+		} `json:"events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &activity)
+	if len(activity.Events) != 2 || activity.Events[0].Type != "REVIEWER_REASSIGNED" {
+		t.Errorf("Expected fe2 to see the reassignment then the assignment, got %s", w.Body.String())
+	}
+}
+
+func TestIntegration_ReviewSLA(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Support",
+		"members": []map[string]interface{}{
+			{"user_id": "su1", "username": "Ann", "is_active": true},
+			{"user_id": "su2", "username": "Ben", "is_active": true},
+			{"user_id": "su3", "username": "Cid", "is_active": true},
+			{"user_id": "su4", "username": "Dan", "is_active": true},
+		},
+	})
+	w := post("/team/updateSettings", map[string]interface{}{
+		"team_name":  "Support",
+		"review_sla": map[string]interface{}{"response_hours": 1},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to set review SLA: %s", w.Body.String())
+	}
+
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-sla-silent",
+		"pull_request_name":   "Tickets",
+		"author_id":           "su1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"su2"},
+	})
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-sla-answered",
+		"pull_request_name":   "Macros",
+		"author_id":           "su1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"su3"},
+	})
+	testDB.MustExec("UPDATE pull_request_reviewers SET assigned_at = NOW() - INTERVAL '2 hours'")
+	post("/pullRequest/review", map[string]interface{}{
+		"pull_request_id": "pr-sla-answered",
+		"reviewer_id":     "su3",
+		"decision":        "COMMENT",
+	})
+
+	requestStorage := &pgsql.PGPullRequestStorage{DB: testDB}
+	prService := pullrequests.New(requestStorage, &pgsql.PGTeamStorage{DB: testDB}, &pgsql.PGUserStorage{DB: testDB}, &pgsql.PGCodeOwnerStorage{DB: testDB})
+	worker := sla.NewWorker(requestStorage, prService, time.Minute)
+
+	handled, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if handled != 1 {
+		t.Errorf("Expected one breach, got %d", handled)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/timeline?pull_request_id=pr-sla-silent", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var timeline struct {
+		Events []struct {
+			Type          string `json:"type"`
+			ReviewerID    string `json:"reviewer_id"`
+			OldReviewerID string `json:"old_reviewer_id"`
+			Reason        string `json:"reason"`
+		} `json:"events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+
+	var types []string
+	for _, event := range timeline.Events {
+		types = append(types, event.Type)
+	}
+	if strings.Join(types, ",") != "CREATED,REVIEWER_ASSIGNED,SLA_BREACHED,REVIEWER_REASSIGNED" {
+		t.Fatalf("Unexpected timeline %s", w.Body.String())
+	}
+	if breach := timeline.Events[2]; breach.ReviewerID != "su2" {
+		t.Errorf("Expected the breach to name su2, got %+v", breach)
+	}
+	if reassigned := timeline.Events[3]; reassigned.OldReviewerID != "su2" || reassigned.Reason != "SLA_BREACH" || reassigned.ReviewerID != "su4" {
+		t.Errorf("Unexpected reassignment event %+v", reassigned)
+	}
+
+	// The replacement starts a fresh SLA, so nothing is due on the next run.
+	if handled, _ := worker.RunOnce(context.Background()); handled != 0 {
+		t.Errorf("Expected no breaches on the next run, got %d", handled)
+	}
+}
+
+type recordingNotifier struct {
+	reminders []models.ReviewReminder
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, reminder models.ReviewReminder) error {
+	n.reminders = append(n.reminders, reminder)
+	return nil
+}
+
+func TestIntegration_ReviewReminders(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Mobile",
+		"members": []map[string]interface{}{
+			{"user_id": "mo1", "username": "Ann", "is_active": true},
+			{"user_id": "mo2", "username": "Ben", "is_active": true},
+			{"user_id": "mo3", "username": "Cid", "is_active": true},
+		},
+	})
+	for _, id := range []string{"pr-remind-1", "pr-remind-2"} {
+		post("/pullRequest/create", map[string]interface{}{
+			"pull_request_id":     id,
+			"pull_request_name":   "Screens",
+			"author_id":           "mo1",
+			"reviewers_count":     1,
+			"preferred_reviewers": []string{"mo2"},
+		})
+	}
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-remind-fresh",
+		"pull_request_name":   "Icons",
+		"author_id":           "mo1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"mo3"},
+	})
+	testDB.MustExec(`
+		UPDATE pull_request_reviewers SET assigned_at = NOW() - INTERVAL '2 days'
+		WHERE reviewer_id = 'mo2'
+	`)
+
+	policy := models.ReminderPolicy{StaleAfter: 24 * time.Hour, RepeatAfter: 24 * time.Hour}
+	notifier := &recordingNotifier{}
+	scheduler := reminders.NewScheduler(&pgsql.PGReminderStorage{DB: testDB}, notifier, time.Hour, policy)
+
+	sent, err := scheduler.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if sent != 1 || len(notifier.reminders) != 1 {
+		t.Fatalf("Expected one reminder, got %+v", notifier.reminders)
+	}
+	reminder := notifier.reminders[0]
+	if reminder.ReviewerID != "mo2" || reminder.PendingCount != 2 || len(reminder.PullRequestIDs) != 2 {
+		t.Errorf("Unexpected reminder %+v", reminder)
+	}
+
+	// A restarted scheduler finds the reminder already sent.
+	restarted := reminders.NewScheduler(&pgsql.PGReminderStorage{DB: testDB}, notifier, time.Hour, policy)
+	if sent, _ := restarted.RunOnce(context.Background()); sent != 0 {
+		t.Errorf("Expected no duplicate reminders after a restart, sent %d", sent)
+	}
+}
+
+func TestIntegration_TeamManagement(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Infra",
+		"members": []map[string]interface{}{
+			{"user_id": "in1", "username": "Ann", "is_active": true},
+			{"user_id": "in2", "username": "Ben", "is_active": true},
+		},
+	})
+
+	w := post("/team/addMembers", map[string]interface{}{
+		"team_name": "Infra",
+		"members":   []map[string]interface{}{{"user_id": "in3", "username": "Cid", "is_active": true}},
+	})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"in3"`) {
+		t.Fatalf("Failed to add member: %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/team/rename", map[string]interface{}{"team_name": "Infra", "new_team_name": "Platform"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to rename team: %d %s", w.Code, w.Body.String())
+	}
+
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-infra",
+		"pull_request_name":   "Terraform",
+		"author_id":           "in1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"in2"},
+	})
+
+	w = post("/team/delete", map[string]interface{}{"team_name": "Platform"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 while in2 reviews an OPEN PR, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/team/removeMembers", map[string]interface{}{"team_name": "Platform", "user_ids": []string{"in2", "in4"}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a non member, got %d", w.Code)
+	}
+
+	post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-infra"})
+
+	w = post("/team/delete", map[string]interface{}{"team_name": "Platform"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to delete team: %d %s", w.Code, w.Body.String())
+	}
+
+	var users int
+	testDB.Get(&users, "SELECT COUNT(*) FROM users WHERE user_id LIKE 'in%'")
+	if users != 3 {
+		t.Errorf("Expected users to outlive the team, got %d", users)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/team/get?team_name=Platform", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the deleted team to be gone, got %d", w.Code)
+	}
+}
+
+func TestIntegration_PrimaryTeam(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	createPR := func(data map[string]interface{}) (int, models.PullRequest) {
+		w := post("/pullRequest/create", data)
+		var resp struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.PR
+	}
+
+	// mt1 joins Web first, which makes it their primary team.
+	post("/team/add", map[string]interface{}{
+		"team_name": "Web",
+		"members": []map[string]interface{}{
+			{"user_id": "mt1", "username": "Ann", "is_active": true},
+			{"user_id": "mt2", "username": "Ben", "is_active": true},
+		},
+	})
+	post("/team/add", map[string]interface{}{
+		"team_name": "Data",
+		"members": []map[string]interface{}{
+			{"user_id": "mt1", "username": "Ann", "is_active": true},
+			{"user_id": "mt3", "username": "Cid", "is_active": true},
+		},
+	})
+
+	code, pr := createPR(map[string]interface{}{"pull_request_id": "pr-mt1", "pull_request_name": "A", "author_id": "mt1"})
+	if code != http.StatusCreated || pr.TeamName != "Web" || len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "mt2" {
+		t.Fatalf("Expected a Web reviewer, got %d %+v", code, pr)
+	}
+
+	code, pr = createPR(map[string]interface{}{"pull_request_id": "pr-mt2", "pull_request_name": "B", "author_id": "mt1", "team_name": "Data"})
+	if code != http.StatusCreated || pr.TeamName != "Data" || len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "mt3" {
+		t.Fatalf("Expected a Data reviewer, got %d %+v", code, pr)
+	}
+
+	code, _ = createPR(map[string]interface{}{"pull_request_id": "pr-mt3", "pull_request_name": "C", "author_id": "mt2", "team_name": "Data"})
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an author outside the team, got %d", code)
+	}
+
+	// Pull requests are listed under the team they were created for, not
+	// under every team of their author.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/list?team_name=Web", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var page struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != http.StatusOK || len(page.PullRequests) != 1 || page.PullRequests[0].ID != "pr-mt1" {
+		t.Errorf("Expected only pr-mt1 under Web, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/users/setPrimaryTeam", map[string]interface{}{"user_id": "mt1", "team_name": "Data"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to set primary team: %d %s", w.Code, w.Body.String())
+	}
+
+	code, pr = createPR(map[string]interface{}{"pull_request_id": "pr-mt4", "pull_request_name": "D", "author_id": "mt1"})
+	if code != http.StatusCreated || pr.TeamName != "Data" {
+		t.Errorf("Expected the new primary team, got %d %+v", code, pr)
+	}
+
+	// Leaving the primary team promotes the remaining one.
+	post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-mt2"})
+	post("/pullRequest/merge", map[string]interface{}{"pull_request_id": "pr-mt4"})
+	w = post("/team/removeMembers", map[string]interface{}{"team_name": "Data", "user_ids": []string{"mt1"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to remove member: %d %s", w.Code, w.Body.String())
+	}
+
+	var primary string
+	testDB.Get(&primary, `
+		SELECT t.name FROM team_members tm INNER JOIN teams t ON t.id = tm.team_id
+		WHERE tm.user_id = 'mt1' AND tm.is_primary
+	`)
+	if primary != "Web" {
+		t.Errorf("Expected Web to become primary, got %q", primary)
+	}
+}
+
+func TestIntegration_Absences(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Ops",
+		"members": []map[string]interface{}{
+			{"user_id": "ab1", "username": "Ann", "is_active": true},
+			{"user_id": "ab2", "username": "Ben", "is_active": true},
+			{"user_id": "ab3", "username": "Cid", "is_active": true},
+		},
+	})
+	post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-ab-before",
+		"pull_request_name":   "Alerts",
+		"author_id":           "ab1",
+		"reviewers_count":     1,
+		"preferred_reviewers": []string{"ab2"},
+	})
+
+	now := time.Now().UTC()
+	w := post("/users/addAbsence", map[string]interface{}{
+		"user_id":          "ab2",
+		"starts_at":        now.Add(-time.Minute),
+		"ends_at":          now.Add(24 * time.Hour),
+		"reason":           "vacation",
+		"reassign_reviews": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to add absence: %d %s", w.Code, w.Body.String())
+	}
+
+	w = post("/pullRequest/create", map[string]interface{}{
+		"pull_request_id":     "pr-ab-during",
+		"pull_request_name":   "Dashboards",
+		"author_id":           "ab1",
+		"preferred_reviewers": []string{"ab2"},
+	})
+	var response struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		UnhonoredPreferences []struct {
+			UserID string `json:"user_id"`
+			Reason string `json:"reason"`
+		} `json:"unhonored_preferences"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if strings.Join(response.PR.AssignedReviewers, ",") != "ab3" {
+		t.Errorf("Expected only ab3 to be assigned, got %v", response.PR.AssignedReviewers)
+	}
+	if len(response.UnhonoredPreferences) != 1 || response.UnhonoredPreferences[0].Reason != "UNAVAILABLE" {
+		t.Errorf("Expected ab2 to be unhonored as UNAVAILABLE, got %s", w.Body.String())
+	}
+
+	w = post("/pullRequest/addReviewer", map[string]interface{}{
+		"pull_request_id": "pr-ab-during",
+		"reviewer_id":     "ab2",
+		"performed_by":    "lead",
+	})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "REVIEWER_UNAVAILABLE") {
+		t.Errorf("Expected REVIEWER_UNAVAILABLE adding absent ab2, got %d %s", w.Code, w.Body.String())
+	}
+
+	userStorage := &pgsql.PGUserStorage{DB: testDB}
+	prService := pullrequests.New(&pgsql.PGPullRequestStorage{DB: testDB}, &pgsql.PGTeamStorage{DB: testDB}, userStorage, &pgsql.PGCodeOwnerStorage{DB: testDB})
+	worker := absences.NewWorker(userStorage, prService, time.Minute)
+
+	reassigned, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if reassigned != 1 {
+		t.Errorf("Expected one reassigned review, got %d", reassigned)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pullRequest/timeline?pull_request_id=pr-ab-before", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var timeline struct {
+		Events []struct {
+			Type          string `json:"type"`
+			ReviewerID    string `json:"reviewer_id"`
+			OldReviewerID string `json:"old_reviewer_id"`
+			Reason        string `json:"reason"`
+		} `json:"events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+	last := timeline.Events[len(timeline.Events)-1]
+	if last.Type != "REVIEWER_REASSIGNED" || last.OldReviewerID != "ab2" || last.ReviewerID != "ab3" || last.Reason != "ABSENCE" {
+		t.Errorf("Unexpected reassignment event %+v", last)
+	}
+
+	// The absence has been handled, so the next run leaves it alone.
+	if reassigned, _ := worker.RunOnce(context.Background()); reassigned != 0 {
+		t.Errorf("Expected nothing to reassign on the next run, got %d", reassigned)
+	}
+}
+
+func TestIntegration_ReviewCapacity(t *testing.T) {
+	cleanupDB(testDB)
+
+	post := func(path string, data map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1"+path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	createPR := func(id string) models.PullRequest {
+		w := post("/pullRequest/create", map[string]interface{}{
+			"pull_request_id":   id,
+			"pull_request_name": "Quota",
+			"author_id":         "ca1",
+			"reviewers_count":   2,
+		})
+		var resp struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.PR
+	}
+
+	post("/team/add", map[string]interface{}{
+		"team_name": "Quota",
+		"members": []map[string]interface{}{
+			{"user_id": "ca1", "username": "Ann", "is_active": true},
+			{"user_id": "ca2", "username": "Ben", "is_active": true},
+			{"user_id": "ca3", "username": "Cid", "is_active": true},
+		},
+	})
+	if w := post("/team/updateSettings", map[string]interface{}{"team_name": "Quota", "max_open_reviews": 1}); w.Code != http.StatusOK {
+		t.Fatalf("Failed to set team capacity: %s", w.Body.String())
+	}
+	// ca3 lifts the team limit for themselves.
+	if w := post("/users/setMaxOpenReviews", map[string]interface{}{"user_id": "ca3", "max_open_reviews": 0}); w.Code != http.StatusOK {
+		t.Fatalf("Failed to set user capacity: %s", w.Body.String())
+	}
+
+	first := createPR("pr-ca-1")
+	slices.Sort(first.AssignedReviewers)
+	if strings.Join(first.AssignedReviewers, ",") != "ca2,ca3" || first.MissingReviewers != 0 {
+		t.Fatalf("Expected a fully staffed PR, got %+v", first)
+	}
+
+	second := createPR("pr-ca-2")
+	if strings.Join(second.AssignedReviewers, ",") != "ca3" || second.MissingReviewers != 1 {
+		t.Errorf("Expected ca3 alone with one missing reviewer, got %+v", second)
+	}
+
+	var list struct {
+		PullRequests []models.PullRequest `json:"pull_requests"`
+	}
+	json.Unmarshal(get("/pullRequest/list?understaffed=true").Body.Bytes(), &list)
+	if len(list.PullRequests) != 1 || list.PullRequests[0].ID != "pr-ca-2" {
+		t.Errorf("Expected only pr-ca-2 to be understaffed, got %+v", list.PullRequests)
+	}
+
+	var user struct {
+		User models.UserProfile `json:"user"`
+	}
+	json.Unmarshal(get("/users/get?user_id=ca2").Body.Bytes(), &user)
+	if capacity := user.User.ReviewCapacity; capacity.MaxOpenReviews != nil || capacity.Limit != 1 || capacity.OpenReviews != 1 {
+		t.Errorf("Unexpected capacity of ca2 %+v", capacity)
+	}
+
+	w := post("/pullRequest/addReviewer", map[string]interface{}{
+		"pull_request_id": "pr-ca-2",
+		"reviewer_id":     "ca2",
+		"performed_by":    "lead",
+	})
+	var added struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &added)
+	if w.Code != http.StatusOK || added.PR.MissingReviewers != 0 {
+		t.Errorf("Expected the manual reviewer to fill the slot, got %d %s", w.Code, w.Body.String())
+	}
+}